@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	dbUsers "github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/services/social"
 	"github.com/aschles4/finalProject/internal/services/users"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -15,12 +16,15 @@ import (
 	"github.com/rs/zerolog"
 )
 
-type FindUserProfileResponse struct {
-	Email          string                   `json:"email,omitempty"`
-	Name           string                   `json:"name,omitempty"`
-	StreamAccounts []dbUsers.StreamAccounts `json:"streamAccounts,omitempty"`
-	Status         int                      `json:"status,omitempty"`
-	Message        string                   `json:"message,omitempty"`
+type AddToLibraryRequest struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+}
+
+type AddToLibraryResponse struct {
+	Library *dbUsers.Library `json:"library,omitempty"`
+	Status  int              `json:"status,omitempty"`
+	Message string           `json:"message,omitempty"`
 }
 
 type Env struct {
@@ -31,6 +35,7 @@ type Env struct {
 type Handler struct {
 	Env Env
 	U   *users.Users
+	S   *social.Social
 	l   zerolog.Logger
 }
 
@@ -41,25 +46,55 @@ func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxy
 	}
 
 	if token == "" {
-		h.l.Info().Msg("Token is required")
 		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
 	}
 
-	act, err := h.U.FindUserAccountByToken(ctx, token)
+	v, err := h.U.FindViewerForScope(ctx, token, users.ScopeWriteLibrary)
+	if err == users.ErrForbidden {
+		return h.handleError(http.StatusForbidden, nil, "API key does not have the library:write scope")
+	}
 	if err != nil {
 		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
 	}
 
-	prof, err := h.U.FindUserProfileByID(ctx, act.ID)
+	var req AddToLibraryRequest
+	err = json.Unmarshal([]byte(event.Body), &req)
 	if err != nil {
-		return h.handleError(http.StatusInternalServerError, err, "Failed to access user profile")
+		return h.handleError(http.StatusBadRequest, err, "Failed to marshal event")
+	}
+
+	if req.ID == 0 {
+		return h.handleError(http.StatusBadRequest, nil, "ID is required")
+	}
+
+	if req.Type != "movie" && req.Type != "tv" {
+		return h.handleError(http.StatusBadRequest, nil, "Type must be movie or tv")
+	}
+
+	//adds to the library of the selected profile
+	added, err := h.U.AddToLibrary(ctx, v.Profile, dbUsers.Content{
+		ID:   req.ID,
+		Type: req.Type,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to add to library")
+	}
+
+	//followers only hear about new titles, and just miss one if the feed write fails
+	if added {
+		err = h.S.Publish(ctx, social.MemberOf(v.Profile), social.Activity{
+			Verb:      social.ActivityLibraryAdd,
+			MediaType: req.Type,
+			TMDBID:    req.ID,
+		})
+		if err != nil {
+			h.l.Error().Str("profileId", v.Profile.ID).Msg(err.Error())
+		}
 	}
 
 	//return
-	js, err := json.Marshal(FindUserProfileResponse{
-		Email:          act.Email,
-		Name:           prof.Name,
-		StreamAccounts: prof.StreamAccounts,
+	js, err := json.Marshal(AddToLibraryResponse{
+		Library: &v.Profile.Library,
 	})
 	if err != nil {
 		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
@@ -77,7 +112,7 @@ func (h Handler) handleError(status int, err error, message string) (events.APIG
 		h.l.Error().Msg(err.Error())
 	}
 
-	js, err := json.Marshal(FindUserProfileResponse{
+	js, err := json.Marshal(AddToLibraryResponse{
 		Message: message,
 	})
 	if err != nil {
@@ -109,10 +144,17 @@ func main() {
 		l.Fatal().Msg("failed to connect to users service")
 	}
 
+	s, err := social.NewSocialService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to social service")
+	}
+
 	h := Handler{
 		l:   l,
 		Env: e,
 		U:   u,
+		S:   s,
 	}
 
 	lambda.Start(h.HandleRequest)
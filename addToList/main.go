@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	dbUsers "github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type AddToListRequest struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+	Note string `json:"note,omitempty"`
+}
+
+type AddToListResponse struct {
+	List    *dbUsers.List `json:"list,omitempty"`
+	Status  int           `json:"status,omitempty"`
+	Message string        `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	l   zerolog.Logger
+}
+
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	v, err := h.U.FindViewerForScope(ctx, token, users.ScopeWriteLibrary)
+	if err == users.ErrForbidden {
+		return h.handleError(http.StatusForbidden, nil, "API key does not have the library:write scope")
+	}
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	ID := event.PathParameters["id"]
+	if ID == "" {
+		return h.handleError(http.StatusBadRequest, nil, "ID is required")
+	}
+
+	var req AddToListRequest
+	err = json.Unmarshal([]byte(event.Body), &req)
+	if err != nil {
+		return h.handleError(http.StatusBadRequest, err, "Failed to marshal event")
+	}
+
+	//added to the end, updateList reorders
+	l, err := h.U.AddToList(ctx, v.Profile, ID, dbUsers.ListItem{
+		ID:   req.ID,
+		Type: req.Type,
+		Note: req.Note,
+	})
+	if err == users.ErrListNotFound {
+		return h.handleError(http.StatusNotFound, nil, "List not found")
+	}
+	if err == users.ErrInvalidListItem {
+		return h.handleError(http.StatusBadRequest, nil, "Items need an ID and a type of movie or tv")
+	}
+	if err == users.ErrListNoteTooLong {
+		return h.handleError(http.StatusBadRequest, nil, fmt.Sprintf("Notes can be %d characters at most", users.MaxListNoteLength))
+	}
+	if err == users.ErrDuplicateListItem {
+		return h.handleError(http.StatusConflict, nil, "Title is already in the list")
+	}
+	if err == users.ErrTooManyListItems {
+		return h.handleError(http.StatusConflict, nil, fmt.Sprintf("Lists can hold %d titles at most", users.MaxListItems))
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to add to list")
+	}
+
+	//return
+	js, err := json.Marshal(AddToListResponse{
+		List: l,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(AddToListResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
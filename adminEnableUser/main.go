@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+
+	dbAudit "github.com/aschles4/finalProject/internal/pkg/dynamo/audit"
+	"github.com/aschles4/finalProject/internal/services/audit"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type AdminEnableUserResponse struct {
+	Status  int    `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	A   *audit.Audit
+	l   zerolog.Logger
+}
+
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	admin, err := h.U.Authorize(ctx, token, users.ScopeManageUsers)
+	if err == users.ErrForbidden {
+		return h.handleError(http.StatusForbidden, nil, "Forbidden")
+	}
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	ID := event.PathParameters["id"]
+	if ID == "" {
+		return h.handleError(http.StatusBadRequest, nil, "ID is required")
+	}
+
+	//recorded before acting so nothing happens unaudited
+	err = h.A.Record(ctx, dbAudit.Event{
+		UserID:    ID,
+		ActorID:   admin.ID,
+		Action:    audit.AdminEnableUser,
+		SourceIP:  event.RequestContext.Identity.SourceIP,
+		UserAgent: event.RequestContext.Identity.UserAgent,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to write audit log")
+	}
+
+	err = h.U.SetAccountDisabled(ctx, admin, ID, false)
+	if err == users.ErrAccountMissing {
+		return h.handleError(http.StatusNotFound, nil, "Account not found")
+	}
+	if err == users.ErrSelfAction {
+		return h.handleError(http.StatusBadRequest, nil, "Admins can't disable or demote themselves")
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to enable user")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusNoContent,
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(AdminEnableUserResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	a, err := audit.NewAuditService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to audit service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		A:   a,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
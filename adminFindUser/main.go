@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+
+	dbAudit "github.com/aschles4/finalProject/internal/pkg/dynamo/audit"
+	dbUsers "github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/services/audit"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type AdminFindUserResponse struct {
+	Account  *users.AdminAccount   `json:"account,omitempty"`
+	Profiles []dbUsers.UserProfile `json:"profiles,omitempty"`
+	Status   int                   `json:"status,omitempty"`
+	Message  string                `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	A   *audit.Audit
+	l   zerolog.Logger
+}
+
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	admin, err := h.U.Authorize(ctx, token, users.ScopeReadUsers)
+	if err == users.ErrForbidden {
+		return h.handleError(http.StatusForbidden, nil, "Forbidden")
+	}
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	ID := event.PathParameters["id"]
+	if ID == "" {
+		return h.handleError(http.StatusBadRequest, nil, "ID is required")
+	}
+
+	act, err := h.U.FindUserAccountByID(ctx, ID)
+	if err == users.ErrAccountMissing {
+		return h.handleError(http.StatusNotFound, nil, "Account not found")
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to find user")
+	}
+
+	profiles, err := h.U.FindViewerProfiles(ctx, act)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to find profiles")
+	}
+
+	//viewing a profile is recorded like any other admin action
+	err = h.A.Record(ctx, dbAudit.Event{
+		UserID:    ID,
+		ActorID:   admin.ID,
+		Action:    audit.AdminViewUser,
+		SourceIP:  event.RequestContext.Identity.SourceIP,
+		UserAgent: event.RequestContext.Identity.UserAgent,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to write audit log")
+	}
+
+	account := users.NewAdminAccount(*act)
+	resp := AdminFindUserResponse{
+		Account:  &account,
+		Profiles: make([]dbUsers.UserProfile, 0, len(*profiles)),
+	}
+	for _, p := range *profiles {
+		resp.Profiles = append(resp.Profiles, users.PublicProfile(p))
+	}
+
+	//return
+	js, err := json.Marshal(resp)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(AdminFindUserResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	a, err := audit.NewAuditService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to audit service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		A:   a,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	dbAudit "github.com/aschles4/finalProject/internal/pkg/dynamo/audit"
+	"github.com/aschles4/finalProject/internal/services/audit"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type AdminFindUsersResponse struct {
+	Users   []users.AdminAccount `json:"users"`
+	Cursor  string               `json:"cursor,omitempty"`
+	Status  int                  `json:"status,omitempty"`
+	Message string               `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	A   *audit.Audit
+	l   zerolog.Logger
+}
+
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	admin, err := h.U.Authorize(ctx, token, users.ScopeReadUsers)
+	if err == users.ErrForbidden {
+		return h.handleError(http.StatusForbidden, nil, "Forbidden")
+	}
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	q := event.QueryStringParameters
+	limit := 25
+	if val, ok := q["limit"]; ok {
+		limit, err = strconv.Atoi(val)
+		if err != nil || limit < 1 || limit > 100 {
+			return h.handleError(http.StatusBadRequest, err, "limit must be between 1 and 100")
+		}
+	}
+
+	//searches are recorded too, they expose who has an account
+	err = h.A.Record(ctx, dbAudit.Event{
+		ActorID:   admin.ID,
+		Action:    audit.AdminSearchUsers,
+		SourceIP:  event.RequestContext.Identity.SourceIP,
+		UserAgent: event.RequestContext.Identity.UserAgent,
+		Data:      map[string]string{"query": q["q"]},
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to write audit log")
+	}
+
+	//q matches part of an email or a whole account id, cursor continues from the last page
+	accounts, cursor, err := h.U.FindUserAccounts(ctx, q["q"], q["cursor"], limit)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to find users")
+	}
+
+	resp := AdminFindUsersResponse{
+		Users:  make([]users.AdminAccount, 0, len(*accounts)),
+		Cursor: cursor,
+	}
+	for _, act := range *accounts {
+		resp.Users = append(resp.Users, users.NewAdminAccount(act))
+	}
+
+	//return
+	js, err := json.Marshal(resp)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(AdminFindUsersResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	a, err := audit.NewAuditService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to audit service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		A:   a,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/aschles4/finalProject/internal/services/content"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type CalendarFeedResponse struct {
+	Status  int    `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection  string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region      string `required:"true" default:"us-east-1" envconfig:"REGION"`
+	IMBDKey     string `required:"true" default:"" envconfig:"IMBD_KEY"`
+	GuideBoxKey string `required:"true" default:"" envconfig:"GB_KEY"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	C   *content.Content
+	l   zerolog.Logger
+}
+
+//The feed is polled by calendar apps that can't send headers, so the secret in the path is the only credential
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var calToken string
+	if val, ok := event.PathParameters["token"]; ok {
+		calToken = strings.TrimSuffix(val, ".ics")
+	}
+
+	if calToken == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Calendar token is required")
+	}
+
+	act, err := h.U.FindUserAccountByCalendarToken(ctx, calToken)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to access calendar")
+	}
+
+	if act == nil {
+		return h.handleError(http.StatusNotFound, nil, "Calendar not found")
+	}
+
+	prof, err := h.U.FindUserProfileByID(ctx, act.ID)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to access user profile")
+	}
+
+	//calendar apps don't send a useful Accept-Language, the feed URL can carry ?language= instead
+	localized := h.C.WithLocale(content.ResolveLocale(users.LocaleFor(prof), "", event.QueryStringParameters["language"], event.QueryStringParameters["region"]))
+	h.C = &localized
+
+	follows, err := h.U.FindFollowsByUserID(ctx, act.ID)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to access followed shows")
+	}
+
+	//followed and library shows may overlap
+	seen := make(map[string]bool)
+	showIDs := make([]string, 0)
+	for _, f := range *follows {
+		if !seen[f.ShowID] {
+			seen[f.ShowID] = true
+			showIDs = append(showIDs, f.ShowID)
+		}
+	}
+
+	movieIDs := make([]string, 0)
+	for _, c := range prof.Library.ContentList {
+		ID := fmt.Sprintf("%d", c.ID)
+		switch c.Type {
+		case "tv":
+			if !seen[ID] {
+				seen[ID] = true
+				showIDs = append(showIDs, ID)
+			}
+		case "movie":
+			movieIDs = append(movieIDs, ID)
+		}
+	}
+
+	from := time.Now().AddDate(0, 0, -7)
+	to := time.Now().AddDate(0, 6, 0)
+	//a title TMDB couldn't find is left off the feed rather than failing it
+	cal, missing := h.C.BuildCalendar(ctx, fmt.Sprintf("%s's Shows", prof.Name), showIDs, movieIDs, from, to)
+	for key, err := range missing {
+		h.l.Error().Str("title", key).Msg(err.Error())
+	}
+
+	//return
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Headers: map[string]string{
+			"Content-Type":  "text/calendar; charset=utf-8",
+			"Cache-Control": "private, max-age=3600",
+		},
+		Body: cal.Encode(),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(CalendarFeedResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	c, err := content.NewContentService(e.IMBDKey, e.GuideBoxKey)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to content service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		C:   c,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	dbParties "github.com/aschles4/finalProject/internal/pkg/dynamo/parties"
+	"github.com/aschles4/finalProject/internal/services/notifications"
+	"github.com/aschles4/finalProject/internal/services/parties"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type CancelWatchPartyResponse struct {
+	*parties.State
+	Status  int    `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	N   *notifications.Notifications
+	P   *parties.Parties
+	l   zerolog.Logger
+}
+
+//Calls the party off, invitees are told and their calendars pick up the cancellation
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	v, err := h.U.FindViewerByToken(ctx, token)
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	ID := event.PathParameters["id"]
+	if ID == "" {
+		return h.handleError(http.StatusBadRequest, nil, "ID is required")
+	}
+
+	state, err := h.P.CancelParty(ctx, v.Profile.ID, ID)
+	if err == parties.ErrPartyNotFound {
+		return h.handleError(http.StatusNotFound, nil, "Party not found")
+	}
+	if err == parties.ErrNotHost {
+		return h.handleError(http.StatusForbidden, nil, "Only the host can change a party")
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to cancel party")
+	}
+
+	party := *state.Party
+	h.notify(ctx, notifications.PartyCancelled, party, state.Invites, fmt.Sprintf("The %s watch party is off", parties.Title(party)), fmt.Sprintf("%s cancelled the party.", party.HostName))
+
+	//return
+	js, err := json.Marshal(CancelWatchPartyResponse{
+		State: state,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+//notify lets the invitees know, a failed notification doesn't undo the cancellation
+func (h Handler) notify(ctx context.Context, typ string, party dbParties.Party, invites []dbParties.Invite, title, message string) {
+	for _, i := range invites {
+		if i.ProfileID == party.HostID {
+			continue
+		}
+
+		prof, err := h.U.FindUserProfileByID(ctx, i.ProfileID)
+		if err != nil || prof == nil || prof.ID == "" {
+			h.l.Error().Str("inviteId", i.ID).Msg("failed to find invitee profile")
+			continue
+		}
+
+		err = h.N.PublishPartyEvent(ctx, notifications.SubscriberFor(prof), typ, party.ID, title, message, party.StartsAt)
+		if err != nil {
+			h.l.Error().Str("inviteId", i.ID).Msg(err.Error())
+		}
+	}
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(CancelWatchPartyResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	n, err := notifications.NewNotificationsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to notifications service")
+	}
+
+	p, err := parties.NewPartiesService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to parties service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		N:   n,
+		P:   p,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
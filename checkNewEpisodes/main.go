@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	dbUsers "github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/services/content"
+	"github.com/aschles4/finalProject/internal/services/notifications"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type Env struct {
+	Connection  string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region      string `required:"true" default:"us-east-1" envconfig:"REGION"`
+	IMBDKey     string `required:"true" default:"" envconfig:"IMBD_KEY"`
+	GuideBoxKey string `required:"true" default:"" envconfig:"GB_KEY"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	C   *content.Content
+	N   *notifications.Notifications
+	l   zerolog.Logger
+}
+
+// Runs on a CloudWatch schedule, raising an event for every follower of a show once its latest episode has aired
+func (h Handler) HandleRequest(ctx context.Context, event events.CloudWatchEvent) error {
+	follows, err := h.U.FindAllFollows(ctx)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+		return err
+	}
+
+	//look each show up once no matter how many people follow it
+	byShow := make(map[string][]dbUsers.Follow)
+	for _, f := range *follows {
+		byShow[f.ShowID] = append(byShow[f.ShowID], f)
+	}
+
+	today := time.Now()
+	for showID, followers := range byShow {
+		d, err := h.C.FindShowDetailsByID(ctx, showID)
+		if err != nil {
+			h.l.Error().Str("showId", showID).Msg(err.Error())
+			continue
+		}
+
+		ep := d.LastEpisode
+		if ep == nil || !content.AirsBetween(ep.AirDate, time.Time{}, today) {
+			continue
+		}
+
+		for _, f := range followers {
+			if f.LastNotified >= ep.AirDate {
+				continue
+			}
+
+			prof, err := h.U.FindUserProfileByID(ctx, f.UserID)
+			if err != nil {
+				h.l.Error().Str("followId", f.ID).Msg(err.Error())
+				continue
+			}
+
+			err = h.N.PublishEpisodeAired(ctx, notifications.SubscriberFor(prof), showID, d.Title, ep.SeasonNumber, ep.EpisodeNumber, ep.AirDate)
+			if err != nil {
+				h.l.Error().Str("followId", f.ID).Msg(err.Error())
+				continue
+			}
+
+			err = h.U.MarkFollowNotified(ctx, f.ID, ep.AirDate)
+			if err != nil {
+				h.l.Error().Str("followId", f.ID).Msg(err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	c, err := content.NewContentService(e.IMBDKey, e.GuideBoxKey)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to content service")
+	}
+
+	n, err := notifications.NewNotificationsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to notifications service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		C:   c,
+		N:   n,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	dbAudit "github.com/aschles4/finalProject/internal/pkg/dynamo/audit"
+	"github.com/aschles4/finalProject/internal/pkg/oidc"
+	"github.com/aschles4/finalProject/internal/services/audit"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type CompleteOIDCLoginResponse struct {
+	Token             string `json:"token,omitempty"`
+	Challenge         string `json:"challenge,omitempty"`
+	TwoFactorRequired bool   `json:"twoFactorRequired,omitempty"`
+	Provider          string `json:"provider,omitempty"`
+	Created           bool   `json:"created,omitempty"`
+	Linked            bool   `json:"linked,omitempty"`
+	Status            int    `json:"status,omitempty"`
+	Message           string `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection  string       `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region      string       `required:"true" default:"us-east-1" envconfig:"REGION"`
+	Providers   oidc.Configs `default:"" envconfig:"OIDC_PROVIDERS"`
+	CallbackURL string       `required:"true" default:"http://localhost:3000/oidc" envconfig:"OIDC_CALLBACK_URL"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	A   *audit.Audit
+	l   zerolog.Logger
+}
+
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	q := event.QueryStringParameters
+	if q["error"] != "" {
+		return h.handleError(http.StatusBadRequest, nil, fmt.Sprintf("Login provider returned %s", q["error"]))
+	}
+
+	if q["code"] == "" || q["state"] == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Code and state are required")
+	}
+
+	name := event.PathParameters["provider"]
+	cfg, ok := h.Env.Providers.Find(name)
+	if !ok {
+		return h.handleError(http.StatusNotFound, nil, "Unknown login provider")
+	}
+
+	//the redirect must match what is registered with the provider exactly
+	p, err := oidc.NewProvider(ctx, cfg, fmt.Sprintf("%s/%s/callback", h.Env.CallbackURL, name))
+	if err != nil {
+		return h.handleError(http.StatusBadGateway, err, "Failed to reach login provider")
+	}
+
+	res, err := h.U.CompleteOIDCLogin(ctx, p, q["state"], q["code"])
+	if err == users.ErrInvalidToken {
+		return h.handleError(http.StatusBadRequest, nil, "Login has expired, please sign in again")
+	}
+	if err == users.ErrAccountDisabled {
+		return h.handleError(http.StatusForbidden, nil, "Account is disabled")
+	}
+	if err == users.ErrIdentityNotLinked {
+		return h.handleError(http.StatusConflict, nil, "An account with this email already exists, sign in with your password and link this provider from settings")
+	}
+	if err == users.ErrIdentityLinked {
+		return h.handleError(http.StatusConflict, nil, "This login is already linked to another account")
+	}
+	if err == users.ErrEmailRequired {
+		return h.handleError(http.StatusBadRequest, nil, "Login provider did not share an email address")
+	}
+	if errors.Is(err, oidc.ErrInvalidIDToken) || err == oidc.ErrNonceMismatch {
+		return h.handleError(http.StatusUnauthorized, err, "Login provider returned an invalid token")
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to login user")
+	}
+
+	if res.Created {
+		h.record(ctx, event, res.Identity.UserID, audit.Signup, map[string]string{"provider": res.Identity.Provider})
+	}
+	if res.Login != nil && res.Login.Token != "" {
+		h.record(ctx, event, res.Identity.UserID, audit.LoginSucceeded, map[string]string{"provider": res.Identity.Provider})
+	}
+
+	resp := CompleteOIDCLoginResponse{
+		Provider: res.Identity.Provider,
+		Created:  res.Created,
+		Linked:   res.Linked,
+	}
+	if res.Login != nil {
+		resp.Token = res.Login.Token
+		resp.Challenge = res.Login.Challenge
+		resp.TwoFactorRequired = res.Login.Challenge != ""
+	}
+
+	//return users login token, the challenge for loginTwoFactor, or that the identity was linked
+	js, err := json.Marshal(resp)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(CompleteOIDCLoginResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+//record keeps a failed audit write from failing the request, it only gets logged
+func (h Handler) record(ctx context.Context, event events.APIGatewayProxyRequest, userID, action string, data map[string]string) {
+	err := h.A.Record(ctx, dbAudit.Event{
+		UserID:    userID,
+		Action:    action,
+		SourceIP:  event.RequestContext.Identity.SourceIP,
+		UserAgent: event.RequestContext.Identity.UserAgent,
+		Data:      data,
+	})
+	if err != nil {
+		h.l.Error().Str("userId", userID).Str("action", action).Msg(err.Error())
+	}
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	a, err := audit.NewAuditService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to audit service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		A:   a,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
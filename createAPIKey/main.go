@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	dbAudit "github.com/aschles4/finalProject/internal/pkg/dynamo/audit"
+	dbUsers "github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/services/audit"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ProfileID     string   `json:"profileId,omitempty"`
+	ExpiresInDays int      `json:"expiresInDays,omitempty"`
+}
+
+type CreateAPIKeyResponse struct {
+	Key     string          `json:"key,omitempty"`
+	APIKey  *dbUsers.APIKey `json:"apiKey,omitempty"`
+	Status  int             `json:"status,omitempty"`
+	Message string          `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	A   *audit.Audit
+	l   zerolog.Logger
+}
+
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	//account tokens only, neither a profile token nor another key can mint keys
+	act, err := h.U.FindUserAccountByToken(ctx, token)
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	var req CreateAPIKeyRequest
+	err = json.Unmarshal([]byte(event.Body), &req)
+	if err != nil {
+		return h.handleError(http.StatusBadRequest, err, "Failed to marshal event")
+	}
+
+	if req.Name == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Name is required")
+	}
+
+	if req.ExpiresInDays < 0 {
+		return h.handleError(http.StatusBadRequest, nil, "expiresInDays can't be negative")
+	}
+
+	ttl := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	key, k, err := h.U.CreateAPIKey(ctx, act, req.Name, req.ProfileID, req.Scopes, ttl)
+	if err == users.ErrScopeRequired || err == users.ErrInvalidScope {
+		return h.handleError(http.StatusBadRequest, nil, "Scopes must be one or more of "+strings.Join(users.APIKeyScopes, ", "))
+	}
+	if err == users.ErrProfileNotFound {
+		return h.handleError(http.StatusNotFound, nil, "Profile not found")
+	}
+	if err == users.ErrTooManyAPIKeys {
+		return h.handleError(http.StatusConflict, nil, "Account has the maximum number of API keys")
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to create API key")
+	}
+	h.record(ctx, event, act.ID, audit.APIKeyCreated, map[string]string{"keyId": k.ID, "name": k.Name, "scopes": strings.Join(k.Scopes, " ")})
+
+	//return, the key is only ever shown here
+	public := users.PublicAPIKey(*k)
+	js, err := json.Marshal(CreateAPIKeyResponse{
+		Key:    key,
+		APIKey: &public,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusCreated,
+		Body:       string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(CreateAPIKeyResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+//record keeps a failed audit write from failing the request, it only gets logged
+func (h Handler) record(ctx context.Context, event events.APIGatewayProxyRequest, userID, action string, data map[string]string) {
+	err := h.A.Record(ctx, dbAudit.Event{
+		UserID:    userID,
+		Action:    action,
+		SourceIP:  event.RequestContext.Identity.SourceIP,
+		UserAgent: event.RequestContext.Identity.UserAgent,
+		Data:      data,
+	})
+	if err != nil {
+		h.l.Error().Str("userId", userID).Str("action", action).Msg(err.Error())
+	}
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	a, err := audit.NewAuditService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to audit service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		A:   a,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
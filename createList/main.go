@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	dbUsers "github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type CreateListRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Visibility  string `json:"visibility,omitempty"`
+}
+
+type CreateListResponse struct {
+	List    *dbUsers.List `json:"list,omitempty"`
+	Status  int           `json:"status,omitempty"`
+	Message string        `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	l   zerolog.Logger
+}
+
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	v, err := h.U.FindViewerForScope(ctx, token, users.ScopeWriteLibrary)
+	if err == users.ErrForbidden {
+		return h.handleError(http.StatusForbidden, nil, "API key does not have the library:write scope")
+	}
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	var req CreateListRequest
+	err = json.Unmarshal([]byte(event.Body), &req)
+	if err != nil {
+		return h.handleError(http.StatusBadRequest, err, "Failed to marshal event")
+	}
+
+	//the list belongs to the selected profile, private unless asked otherwise
+	l, err := h.U.CreateList(ctx, v.Profile, req.Name, req.Description, req.Visibility)
+	if err == users.ErrListNameRequired {
+		return h.handleError(http.StatusBadRequest, nil, "Name is required")
+	}
+	if err == users.ErrListNameTooLong || err == users.ErrListDescTooLong {
+		return h.handleError(http.StatusBadRequest, nil, fmt.Sprintf("Name can be %d and description %d characters at most", users.MaxListNameLength, users.MaxListDescriptionLen))
+	}
+	if err == users.ErrInvalidVisibility {
+		return h.handleError(http.StatusBadRequest, nil, "Visibility must be one of "+strings.Join(users.ListVisibilities, ", "))
+	}
+	if err == users.ErrTooManyLists {
+		return h.handleError(http.StatusConflict, nil, "Profile has the maximum number of lists")
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to create list")
+	}
+
+	//return
+	js, err := json.Marshal(CreateListResponse{
+		List: l,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusCreated,
+		Body:       string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(CreateListResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
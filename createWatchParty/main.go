@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	dbParties "github.com/aschles4/finalProject/internal/pkg/dynamo/parties"
+	"github.com/aschles4/finalProject/internal/services/content"
+	"github.com/aschles4/finalProject/internal/services/notifications"
+	"github.com/aschles4/finalProject/internal/services/parties"
+	"github.com/aschles4/finalProject/internal/services/social"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type CreateWatchPartyRequest struct {
+	Name      string    `json:"name"`
+	Note      string    `json:"note"`
+	MediaType string    `json:"mediaType"`
+	TMDBID    int64     `json:"tmdbId"`
+	Season    int       `json:"season"`
+	Episode   int       `json:"episode"`
+	StartsAt  time.Time `json:"startsAt"`
+	Duration  int       `json:"duration"`
+	Invitees  []string  `json:"invitees"`
+}
+
+type CreateWatchPartyResponse struct {
+	*parties.State
+	Status  int    `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection  string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region      string `required:"true" default:"us-east-1" envconfig:"REGION"`
+	IMBDKey     string `required:"true" default:"" envconfig:"IMBD_KEY"`
+	GuideBoxKey string `required:"true" default:"" envconfig:"GB_KEY"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	S   *social.Social
+	C   *content.Content
+	N   *notifications.Notifications
+	P   *parties.Parties
+	l   zerolog.Logger
+}
+
+//Plans a watch party for a movie or an episode and invites profiles on the account or people who follow the host
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	v, err := h.U.FindViewerByToken(ctx, token)
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+	m := social.MemberOf(v.Profile)
+
+	var req CreateWatchPartyRequest
+	err = json.Unmarshal([]byte(event.Body), &req)
+	if err != nil {
+		return h.handleError(http.StatusBadRequest, err, "Failed to marshal event")
+	}
+
+	if req.Duration == 0 {
+		req.Duration = parties.DefaultDuration
+	}
+
+	guests, err := h.findGuests(ctx, m, req.Invitees)
+	if err == errUnknownGuest {
+		return h.handleError(http.StatusBadRequest, nil, err.Error())
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to find invitees")
+	}
+
+	//the party still works without a title, invites and the calendar fall back to the ID
+	key := content.TitleKey(req.MediaType, strconv.FormatInt(req.TMDBID, 10))
+	info, err := h.C.FindTitleInfo(ctx, []string{key})
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	d := parties.Details{
+		Name:         req.Name,
+		Note:         req.Note,
+		MediaType:    req.MediaType,
+		TMDBID:       req.TMDBID,
+		Season:       req.Season,
+		Episode:      req.Episode,
+		ContentTitle: info[key].Title,
+		StartsAt:     req.StartsAt,
+		Duration:     req.Duration,
+	}
+
+	host := parties.Guest{
+		AccountID: m.AccountID,
+		ProfileID: m.ProfileID,
+		Name:      m.Name,
+	}
+
+	state, err := h.P.CreateParty(ctx, host, d, guests)
+	if err == parties.ErrInvalidTarget || err == parties.ErrStartInPast || err == parties.ErrInvalidDuration || err == parties.ErrPartyNameTooLong || err == parties.ErrPartyNoteTooLong || err == parties.ErrTooManyInvitees {
+		return h.handleError(http.StatusBadRequest, nil, err.Error())
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to create party")
+	}
+
+	party := *state.Party
+	h.notify(ctx, notifications.PartyInvite, party, state.Invites, fmt.Sprintf("%s invited you to watch %s", party.HostName, parties.Title(party)), "Let them know if you can make it.")
+
+	//return
+	js, err := json.Marshal(CreateWatchPartyResponse{
+		State: state,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusCreated,
+		Body:       string(js),
+	}, nil
+}
+
+var errUnknownGuest = errors.New("guests must be profiles on your account or people who follow you")
+
+//findGuests looks up the profiles to invite, skipping the host and repeats. Anyone outside the host's
+//account has to follow the host.
+func (h Handler) findGuests(ctx context.Context, m social.Member, profileIDs []string) ([]parties.Guest, error) {
+	seen := map[string]bool{m.ProfileID: true}
+	guests := make([]parties.Guest, 0, len(profileIDs))
+	for _, ID := range profileIDs {
+		if seen[ID] {
+			continue
+		}
+		seen[ID] = true
+
+		prof, err := h.U.FindUserProfileByID(ctx, ID)
+		if err != nil {
+			return nil, err
+		}
+		if prof == nil || prof.ID == "" {
+			return nil, errUnknownGuest
+		}
+
+		g := social.MemberOf(prof)
+		if g.AccountID != m.AccountID {
+			//invites are emailed, so only people who follow the host can be sent one
+			ok, err := h.S.Follows(ctx, g.ProfileID, m.ProfileID)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, errUnknownGuest
+			}
+		}
+
+		guests = append(guests, parties.Guest{
+			AccountID: g.AccountID,
+			ProfileID: g.ProfileID,
+			Name:      g.Name,
+		})
+	}
+
+	return guests, nil
+}
+
+//notify lets the invitees know, a failed notification doesn't undo the party
+func (h Handler) notify(ctx context.Context, typ string, party dbParties.Party, invites []dbParties.Invite, title, message string) {
+	for _, i := range invites {
+		if i.ProfileID == party.HostID {
+			continue
+		}
+
+		prof, err := h.U.FindUserProfileByID(ctx, i.ProfileID)
+		if err != nil || prof == nil || prof.ID == "" {
+			h.l.Error().Str("inviteId", i.ID).Msg("failed to find invitee profile")
+			continue
+		}
+
+		err = h.N.PublishPartyEvent(ctx, notifications.SubscriberFor(prof), typ, party.ID, title, message, party.StartsAt)
+		if err != nil {
+			h.l.Error().Str("inviteId", i.ID).Msg(err.Error())
+		}
+	}
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(CreateWatchPartyResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	s, err := social.NewSocialService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to social service")
+	}
+
+	c, err := content.NewContentService(e.IMBDKey, e.GuideBoxKey)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to content service")
+	}
+
+	n, err := notifications.NewNotificationsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to notifications service")
+	}
+
+	p, err := parties.NewPartiesService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to parties service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		S:   s,
+		C:   c,
+		N:   n,
+		P:   p,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	dbAudit "github.com/aschles4/finalProject/internal/pkg/dynamo/audit"
+	"github.com/aschles4/finalProject/internal/services/audit"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code,omitempty"`
+}
+
+type DeleteAccountResponse struct {
+	DeleteAfter int64  `json:"deleteAfter,omitempty"`
+	RetryAfter  int    `json:"retryAfter,omitempty"`
+	Status      int    `json:"status,omitempty"`
+	Message     string `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+
+	DeletionGrace time.Duration `default:"720h" envconfig:"DELETION_GRACE"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	A   *audit.Audit
+	l   zerolog.Logger
+}
+
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	act, err := h.U.FindUserAccountByToken(ctx, token)
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	var req DeleteAccountRequest
+	err = json.Unmarshal([]byte(event.Body), &req)
+	if err != nil {
+		return h.handleError(http.StatusBadRequest, err, "Failed to marshal event")
+	}
+
+	if req.Password == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Password is required")
+	}
+
+	if act.TwoFactor.Enabled && req.Code == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Code is required")
+	}
+
+	//a valid session isn't enough, the password is asked for again and every session is signed out
+	deleteAfter, err := h.U.ScheduleAccountDeletion(ctx, act, req.Password, req.Code, event.RequestContext.Identity.SourceIP, h.Env.DeletionGrace)
+	if locked, ok := err.(*users.LockedError); ok {
+		retry := int(locked.RetryAfter(time.Now()).Seconds())
+		js, err := json.Marshal(DeleteAccountResponse{
+			RetryAfter: retry,
+			Message:    "Too many failed attempts, try again later",
+		})
+		if err != nil {
+			return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+		}
+
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusTooManyRequests,
+			Headers:    map[string]string{"Retry-After": strconv.Itoa(retry)},
+			Body:       string(js),
+		}, nil
+	}
+	if err == users.ErrInvalidCredentials {
+		return h.handleError(http.StatusUnauthorized, nil, "Password is incorrect")
+	}
+	if err == users.ErrInvalidCode {
+		return h.handleError(http.StatusUnauthorized, nil, "Code is invalid")
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to delete account")
+	}
+	h.record(ctx, event, act.ID, audit.DeletionRequested, map[string]string{"deleteAfter": strconv.FormatInt(deleteAfter.Unix(), 10)})
+
+	//return, signing in again before deleteAfter and calling cancelAccountDeletion keeps the account
+	js, err := json.Marshal(DeleteAccountResponse{
+		DeleteAfter: deleteAfter.Unix(),
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusAccepted,
+		Body:       string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(DeleteAccountResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+//record keeps a failed audit write from failing the request, it only gets logged
+func (h Handler) record(ctx context.Context, event events.APIGatewayProxyRequest, userID, action string, data map[string]string) {
+	err := h.A.Record(ctx, dbAudit.Event{
+		UserID:    userID,
+		Action:    action,
+		SourceIP:  event.RequestContext.Identity.SourceIP,
+		UserAgent: event.RequestContext.Identity.UserAgent,
+		Data:      data,
+	})
+	if err != nil {
+		h.l.Error().Str("userId", userID).Str("action", action).Msg(err.Error())
+	}
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	a, err := audit.NewAuditService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to audit service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		A:   a,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/exports"
+	"github.com/aschles4/finalProject/internal/services/content"
+	"github.com/aschles4/finalProject/internal/services/downloads"
+	"github.com/aschles4/finalProject/internal/services/reviews"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type ExportLibraryResponse struct {
+	Download *downloads.Link `json:"download,omitempty"`
+	Status   int             `json:"status,omitempty"`
+	Message  string          `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection  string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region      string `required:"true" default:"us-east-1" envconfig:"REGION"`
+	IMBDKey     string `required:"true" default:"" envconfig:"IMBD_KEY"`
+	GuideBoxKey string `required:"true" default:"" envconfig:"GB_KEY"`
+	//the bucket should expire exports after a day or so, the links stop working long before that
+	ExportBucket string        `required:"true" envconfig:"EXPORT_BUCKET"`
+	LinkExpiry   time.Duration `default:"15m" envconfig:"EXPORT_LINK_EXPIRY"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	C   *content.Content
+	R   *reviews.Reviews
+	D   *downloads.Downloads
+	l   zerolog.Logger
+}
+
+//Exports the selected profile's library, lists, ratings and history to a file and returns a link to
+//download it from. CSV is one section at a time, JSON has every section unless one is asked for.
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	v, err := h.U.FindViewerForScope(ctx, token, users.ScopeReadLibrary)
+	if err == users.ErrForbidden {
+		return h.handleError(http.StatusForbidden, nil, "API key does not have the library:read scope")
+	}
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	format := strings.ToLower(event.QueryStringParameters["format"])
+	if format == "" {
+		format = exports.FormatJSON
+	}
+	if format != exports.FormatJSON && format != exports.FormatCSV {
+		return h.handleError(http.StatusBadRequest, nil, "format must be csv or json")
+	}
+
+	wanted := exports.Sections
+	section := strings.ToLower(event.QueryStringParameters["section"])
+	if section != "" {
+		if !exports.ValidSection(section) {
+			return h.handleError(http.StatusBadRequest, nil, "section must be one of "+strings.Join(exports.Sections, ", "))
+		}
+		wanted = []string{section}
+	}
+	if format == exports.FormatCSV && section == "" {
+		return h.handleError(http.StatusBadRequest, nil, "section is required for csv, one of "+strings.Join(exports.Sections, ", "))
+	}
+
+	sections := make([]exports.Section, 0, len(wanted))
+	for _, name := range wanted {
+		s, err := h.section(ctx, v, name)
+		if err != nil {
+			return h.handleError(http.StatusInternalServerError, err, "Failed to export "+name)
+		}
+		sections = append(sections, *s)
+	}
+
+	//titles, years and IMDb IDs come from TMDB, the export still goes out with TMDB IDs if that fails
+	err = h.addTitleInfo(ctx, sections)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	now := time.Now()
+	contentType := "application/json"
+	write := func(w io.Writer) error {
+		return exports.WriteJSON(w, now, v.Profile.Name, sections)
+	}
+	if format == exports.FormatCSV {
+		contentType = "text/csv; charset=utf-8"
+		write = func(w io.Writer) error {
+			return exports.WriteCSV(w, sections[0])
+		}
+	}
+
+	name := "library"
+	if section != "" {
+		name = section
+	}
+
+	//large libraries are gzipped when the client takes it, the encoders write straight into the upload
+	link, err := h.D.Write(ctx, v.Profile.ID, fmt.Sprintf("%s-%s.%s", name, now.Format("20060102"), format), contentType, acceptsGzip(event.Headers), h.Env.LinkExpiry, write)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to write export")
+	}
+
+	//return
+	js, err := json.Marshal(ExportLibraryResponse{
+		Download: link,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+//section builds one section, newest first except lists which keep their owner's order
+func (h Handler) section(ctx context.Context, v *users.Viewer, name string) (*exports.Section, error) {
+	s := exports.Section{Name: name, Entries: make([]exports.Entry, 0)}
+
+	switch name {
+	case exports.SectionLibrary:
+		for _, c := range v.Profile.Library.ContentList {
+			s.Entries = append(s.Entries, exports.Entry{
+				MediaType: c.Type,
+				TMDBID:    strconv.FormatInt(c.ID, 10),
+			})
+		}
+	case exports.SectionLists:
+		lists, err := h.U.FindLists(ctx, v.Profile)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, l := range *lists {
+			for i, item := range l.Items {
+				s.Entries = append(s.Entries, exports.Entry{
+					MediaType: item.Type,
+					TMDBID:    strconv.FormatInt(item.ID, 10),
+					AddedAt:   item.AddedAt,
+					List:      l.Name,
+					Position:  i + 1,
+					Note:      item.Note,
+				})
+			}
+		}
+	case exports.SectionRatings:
+		rvs, err := h.R.FindReviewsByProfileID(ctx, v.Profile.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rv := range *rvs {
+			s.Entries = append(s.Entries, exports.Entry{
+				MediaType: rv.MediaType,
+				TMDBID:    rv.TMDBID,
+				Season:    rv.Season,
+				Episode:   rv.Episode,
+				RatedAt:   rv.UpdatedAt,
+				Rating:    rv.Rating,
+				Review:    rv.Text,
+			})
+		}
+	case exports.SectionHistory:
+		//the history is newest first, a watch is a rewatch when an older one of the title follows it
+		seen := make(map[string]int)
+		for _, w := range v.Profile.WatchHistory {
+			seen[fmt.Sprintf("%s:%d:%d:%d", w.Type, w.ID, w.Season, w.Episode)]++
+		}
+
+		for _, w := range v.Profile.WatchHistory {
+			key := fmt.Sprintf("%s:%d:%d:%d", w.Type, w.ID, w.Season, w.Episode)
+			seen[key]--
+			s.Entries = append(s.Entries, exports.Entry{
+				MediaType: w.Type,
+				TMDBID:    strconv.FormatInt(w.ID, 10),
+				Season:    w.Season,
+				Episode:   w.Episode,
+				WatchedAt: w.WatchedAt,
+				Rewatch:   seen[key] > 0,
+			})
+		}
+	}
+
+	return &s, nil
+}
+
+//addTitleInfo fills in the title, year and IMDb ID of every entry, episodes get their show's
+func (h Handler) addTitleInfo(ctx context.Context, sections []exports.Section) error {
+	keys := make([]string, 0)
+	found := make(map[string]bool)
+	for _, s := range sections {
+		for _, e := range s.Entries {
+			key := content.TitleKey(e.MediaType, e.TMDBID)
+			if !found[key] {
+				found[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	info, err := h.C.FindTitleInfo(ctx, keys)
+
+	for _, s := range sections {
+		for i := range s.Entries {
+			e := &s.Entries[i]
+			if t, ok := info[content.TitleKey(e.MediaType, e.TMDBID)]; ok {
+				e.Title, e.Year, e.IMDbID = t.Title, t.Year, t.IMDbID
+			}
+		}
+	}
+
+	return err
+}
+
+func acceptsGzip(headers map[string]string) bool {
+	for k, v := range headers {
+		if strings.EqualFold(k, "Accept-Encoding") && strings.Contains(v, "gzip") {
+			return true
+		}
+	}
+	return false
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(ExportLibraryResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	c, err := content.NewContentService(e.IMBDKey, e.GuideBoxKey)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to content service")
+	}
+
+	r, err := reviews.NewReviewsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to reviews service")
+	}
+
+	d, err := downloads.NewDownloadsService(e.Region, e.ExportBucket)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to downloads service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		C:   c,
+		R:   r,
+		D:   d,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	dbAudit "github.com/aschles4/finalProject/internal/pkg/dynamo/audit"
+	dbNotifications "github.com/aschles4/finalProject/internal/pkg/dynamo/notifications"
+	dbReviews "github.com/aschles4/finalProject/internal/pkg/dynamo/reviews"
+	"github.com/aschles4/finalProject/internal/services/audit"
+	"github.com/aschles4/finalProject/internal/services/notifications"
+	"github.com/aschles4/finalProject/internal/services/parties"
+	"github.com/aschles4/finalProject/internal/services/reviews"
+	"github.com/aschles4/finalProject/internal/services/social"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+//Archive is the whole export, one JSON document per account
+type Archive struct {
+	ExportedAt int64 `json:"exportedAt"`
+	users.AccountExport
+	Notifications *[]dbNotifications.Event `json:"notifications"`
+	Reviews       *[]dbReviews.Review      `json:"reviews"`
+	Activity      *[]audit.Activity        `json:"activity"`
+	Social        *social.Export           `json:"social"`
+	Parties       *parties.Export          `json:"parties"`
+}
+
+type ExportUserDataResponse struct {
+	Status  int    `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	A   *audit.Audit
+	N   *notifications.Notifications
+	R   *reviews.Reviews
+	S   *social.Social
+	P   *parties.Parties
+	l   zerolog.Logger
+}
+
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	//account tokens only, the export covers every profile in the household
+	act, err := h.U.FindUserAccountByToken(ctx, token)
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	export, err := h.U.ExportAccount(ctx, act)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to export account")
+	}
+
+	notes, err := h.N.FindEventsByAccountID(ctx, act.ID)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to export notifications")
+	}
+
+	rvs, err := h.R.FindReviewsByAccountID(ctx, act.ID)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to export reviews")
+	}
+
+	activity, err := h.A.FindActivity(ctx, act.ID)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to export activity")
+	}
+
+	soc, err := h.S.ExportAccount(ctx, act.ID)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to export social data")
+	}
+
+	pts, err := h.P.ExportAccount(ctx, act.ID)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to export watch parties")
+	}
+
+	now := time.Now()
+	js, err := json.MarshalIndent(Archive{
+		ExportedAt:    now.Unix(),
+		AccountExport: *export,
+		Notifications: notes,
+		Reviews:       rvs,
+		Activity:      activity,
+		Social:        soc,
+		Parties:       pts,
+	}, "", "  ")
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+	h.record(ctx, event, act.ID, audit.DataExported, nil)
+
+	//return as a download
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Headers: map[string]string{
+			"Content-Type":        "application/json",
+			"Content-Disposition": fmt.Sprintf("attachment; filename=\"export-%s-%s.json\"", act.ID, now.Format("20060102")),
+		},
+		Body: string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(ExportUserDataResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+//record keeps a failed audit write from failing the request, it only gets logged
+func (h Handler) record(ctx context.Context, event events.APIGatewayProxyRequest, userID, action string, data map[string]string) {
+	err := h.A.Record(ctx, dbAudit.Event{
+		UserID:    userID,
+		Action:    action,
+		SourceIP:  event.RequestContext.Identity.SourceIP,
+		UserAgent: event.RequestContext.Identity.UserAgent,
+		Data:      data,
+	})
+	if err != nil {
+		h.l.Error().Str("userId", userID).Str("action", action).Msg(err.Error())
+	}
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	a, err := audit.NewAuditService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to audit service")
+	}
+
+	n, err := notifications.NewNotificationsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to notifications service")
+	}
+
+	r, err := reviews.NewReviewsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to reviews service")
+	}
+
+	s, err := social.NewSocialService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to social service")
+	}
+
+	p, err := parties.NewPartiesService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to parties service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		A:   a,
+		N:   n,
+		R:   r,
+		S:   s,
+		P:   p,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	dbSocial "github.com/aschles4/finalProject/internal/pkg/dynamo/social"
+	"github.com/aschles4/finalProject/internal/services/social"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type FindActivityFeedResponse struct {
+	Items   *[]dbSocial.FeedItem `json:"items,omitempty"`
+	Cursor  string               `json:"cursor,omitempty"`
+	Status  int                  `json:"status,omitempty"`
+	Message string               `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	S   *social.Social
+	l   zerolog.Logger
+}
+
+//Finds the library adds, ratings and watches of the profiles the selected profile follows, newest first
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	v, err := h.U.FindViewerByToken(ctx, token)
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+	m := social.MemberOf(v.Profile)
+
+	limit := 0
+	if val := event.QueryStringParameters["limit"]; val != "" {
+		limit, err = strconv.Atoi(val)
+		if err != nil {
+			return h.handleError(http.StatusBadRequest, err, "limit must be a number")
+		}
+	}
+
+	//cursor is the one returned with the previous page
+	items, cursor, err := h.S.FindFeed(ctx, m, event.QueryStringParameters["cursor"], limit)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to find activity feed")
+	}
+
+	//return
+	js, err := json.Marshal(FindActivityFeedResponse{
+		Items:  items,
+		Cursor: cursor,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(FindActivityFeedResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	s, err := social.NewSocialService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to social service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		S:   s,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
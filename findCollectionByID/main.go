@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aschles4/finalProject/internal/services/content"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type FindCollectionByIDResponse struct {
+	Collection *content.Collection `json:"collection,omitempty"`
+	Status     int                 `json:"status,omitempty"`
+	Message    string              `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection  string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region      string `required:"true" default:"us-east-1" envconfig:"REGION"`
+	IMBDKey     string `required:"true" default:"" envconfig:"IMBD_KEY"`
+	GuideBoxKey string `required:"true" default:"" envconfig:"GB_KEY"`
+	ImageCDN    string `default:"" envconfig:"IMAGE_CDN"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	C   *content.Content
+	l   zerolog.Logger
+}
+
+//Finds a movie collection with its parts in release order and how far through it the selected profile is
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	v, err := h.U.FindViewerByToken(ctx, token)
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	//titles, release dates, ratings and availability in the viewer's language and region
+	localized := h.C.WithLocale(content.ResolveLocale(users.LocaleFor(v.Profile), event.Headers["Accept-Language"], event.QueryStringParameters["language"], event.QueryStringParameters["region"]))
+	h.C = &localized
+
+	ID := event.PathParameters["id"]
+	if ID == "" {
+		return h.handleError(http.StatusBadRequest, nil, "ID is required")
+	}
+
+	col, err := h.C.FindCollectionByID(ctx, ID)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to access collection")
+	}
+
+	//drop parts over the profile's parental controls
+	filtered, err := h.C.FilterPartsByRating(ctx, *col, users.RatingLimitFor(v.Profile))
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to apply parental controls")
+	}
+
+	watched := make(map[string]bool)
+	for _, w := range v.Profile.WatchHistory {
+		if w.Type == content.MediaMovie || w.Type == "" {
+			watched[strconv.FormatInt(w.ID, 10)] = true
+		}
+	}
+	filtered = filtered.WithProgress(watched)
+
+	//return
+	js, err := json.Marshal(FindCollectionByIDResponse{
+		Collection: &filtered,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(FindCollectionByIDResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	c, err := content.NewContentService(e.IMBDKey, e.GuideBoxKey)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to content service")
+	}
+
+	//images go through the CDN when there is one
+	c.ImageBase = e.ImageCDN
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		C:   c,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
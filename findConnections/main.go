@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+
+	dbSocial "github.com/aschles4/finalProject/internal/pkg/dynamo/social"
+	"github.com/aschles4/finalProject/internal/services/social"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type FindConnectionsResponse struct {
+	Followers *[]dbSocial.Connection `json:"followers,omitempty"`
+	Following *[]dbSocial.Connection `json:"following,omitempty"`
+	Blocked   *[]dbSocial.Block      `json:"blocked,omitempty"`
+	Status    int                    `json:"status,omitempty"`
+	Message   string                 `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	S   *social.Social
+	l   zerolog.Logger
+}
+
+//Finds who the selected profile follows, who follows it and who it has blocked
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	v, err := h.U.FindViewerByToken(ctx, token)
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+	m := social.MemberOf(v.Profile)
+
+	followers, err := h.S.FindFollowers(ctx, m)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to find followers")
+	}
+
+	following, err := h.S.FindFollowing(ctx, m)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to find following")
+	}
+
+	blocked, err := h.S.FindBlocks(ctx, m)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to find blocked profiles")
+	}
+
+	//return
+	js, err := json.Marshal(FindConnectionsResponse{
+		Followers: followers,
+		Following: following,
+		Blocked:   blocked,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(FindConnectionsResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	s, err := social.NewSocialService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to social service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		S:   s,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
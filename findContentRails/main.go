@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aschles4/finalProject/internal/services/content"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type FindContentRailsResponse struct {
+	Suggestions []content.Suggestion `json:"suggestions,omitempty"`
+	Status      int                  `json:"status,omitempty"`
+	Message     string               `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection  string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region      string `required:"true" default:"us-east-1" envconfig:"REGION"`
+	IMBDKey     string `required:"true" default:"" envconfig:"IMBD_KEY"`
+	GuideBoxKey string `required:"true" default:"" envconfig:"GB_KEY"`
+	ImageCDN    string `default:"" envconfig:"IMAGE_CDN"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	C   *content.Content
+	l   zerolog.Logger
+}
+
+//Finds TMDB's trending, popular, top rated, now playing, upcoming, airing today and on the air rows for the home screen
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	v, err := h.U.FindViewerByToken(ctx, token)
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	//titles, release dates, ratings and availability in the viewer's language and region
+	localized := h.C.WithLocale(content.ResolveLocale(users.LocaleFor(v.Profile), event.Headers["Accept-Language"], event.QueryStringParameters["language"], event.QueryStringParameters["region"]))
+	h.C = &localized
+
+	//every rail unless the client asks for some, e.g. ?rails=trending_day,upcoming
+	names := content.Rails
+	if q := event.QueryStringParameters["rails"]; q != "" {
+		names = strings.Split(q, ",")
+	}
+
+	for _, name := range names {
+		if !content.ValidRail(name) {
+			return h.handleError(http.StatusBadRequest, nil, fmt.Sprintf("Unknown rail %s", name))
+		}
+	}
+
+	//a rail TMDB couldn't serve is left off the home screen rather than failing it
+	s, err := h.C.FindRails(ctx, names, "")
+	if err != nil && len(*s) == 0 {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to access content rails")
+	}
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	//drop anything over the profile's parental controls
+	*s, err = h.C.FilterByRating(ctx, *s, users.RatingLimitFor(v.Profile))
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to apply parental controls")
+	}
+
+	//return
+	js, err := json.Marshal(FindContentRailsResponse{
+		Suggestions: *s,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	//filtered per profile, so only the viewer's own client may cache it
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Headers: map[string]string{
+			"Cache-Control": "private, max-age=3600",
+		},
+		Body: string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(FindContentRailsResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	c, err := content.NewContentService(e.IMBDKey, e.GuideBoxKey)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to content service")
+	}
+
+	//images go through the CDN when there is one
+	c.ImageBase = e.ImageCDN
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		C:   c,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
@@ -9,7 +9,9 @@ import (
 
 	"github.com/aws/aws-lambda-go/events"
 
+	dbReviews "github.com/aschles4/finalProject/internal/pkg/dynamo/reviews"
 	"github.com/aschles4/finalProject/internal/services/content"
+	"github.com/aschles4/finalProject/internal/services/reviews"
 	"github.com/aschles4/finalProject/internal/services/users"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/kelseyhightower/envconfig"
@@ -17,9 +19,11 @@ import (
 )
 
 type findEpisodeDetailsByNumberResponse struct {
-	Details *content.EpisodeDetails `json:"details,omitempty"`
-	Status  int                     `json:"status,omitempty"`
-	Message string                  `json:"message,omitempty"`
+	Details   *content.EpisodeDetails `json:"details,omitempty"`
+	Community *reviews.Score          `json:"community,omitempty"`
+	MyReview  *dbReviews.Review       `json:"myReview,omitempty"`
+	Status    int                     `json:"status,omitempty"`
+	Message   string                  `json:"message,omitempty"`
 }
 
 type Env struct {
@@ -27,12 +31,14 @@ type Env struct {
 	Region      string `required:"true" default:"us-east-1" envconfig:"REGION"`
 	IMBDKey     string `required:"true" default:"" envconfig:"IMBD_KEY"`
 	GuideBoxKey string `required:"true" default:"" envconfig:"GB_KEY"`
+	ImageCDN    string `default:"" envconfig:"IMAGE_CDN"`
 }
 
 type Handler struct {
 	Env Env
 	U   *users.Users
 	C   *content.Content
+	R   *reviews.Reviews
 	l   zerolog.Logger
 }
 
@@ -46,11 +52,15 @@ func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxy
 		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
 	}
 
-	_, err := h.U.FindUserAccountByToken(ctx, token)
+	v, err := h.U.FindViewerByToken(ctx, token)
 	if err != nil {
 		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
 	}
 
+	//titles, release dates, ratings and availability in the viewer's language and region
+	localized := h.C.WithLocale(content.ResolveLocale(users.LocaleFor(v.Profile), event.Headers["Accept-Language"], event.QueryStringParameters["language"], event.QueryStringParameters["region"]))
+	h.C = &localized
+
 	var showId string
 	if val, ok := event.PathParameters["showid"]; ok {
 		showId = val
@@ -78,14 +88,42 @@ func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxy
 		return h.handleError(http.StatusBadRequest, nil, "Episode Number is required")
 	}
 
+	//parental controls on the profile, episodes go by their show
+	check, err := h.C.CheckRating(ctx, users.RatingLimitFor(v.Profile), content.MediaTV, showId)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to apply parental controls")
+	}
+
+	if !check.Allowed {
+		return h.handleError(http.StatusForbidden, nil, "Restricted by parental controls, a parent can unlock it with their PIN")
+	}
+
 	d, err := h.C.FindEpisodeDetailsByNumber(ctx, showId, seasonNumber, episodeNumber)
 	if err != nil {
 		return h.handleError(http.StatusInternalServerError, err, "Failed to access content details")
 	}
 
+	//community score from our own ratings, shown next to TMDB's vote average
+	t, err := reviews.NewTarget(content.MediaTV, showId, seasonNumber, episodeNumber)
+	if err != nil {
+		return h.handleError(http.StatusBadRequest, err, "Invalid content ID")
+	}
+
+	score, err := h.R.FindScore(ctx, *t)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to access community score")
+	}
+
+	mine, err := h.R.FindReview(ctx, v.Profile.ID, *t)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to access review")
+	}
+
 	//return
 	js, err := json.Marshal(findEpisodeDetailsByNumberResponse{
-		Details: d,
+		Details:   d,
+		Community: score,
+		MyReview:  mine,
 	})
 	if err != nil {
 		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
@@ -141,11 +179,21 @@ func main() {
 		l.Fatal().Msg("failed to connect to users service")
 	}
 
+	//images go through the CDN when there is one
+	c.ImageBase = e.ImageCDN
+
+	r, err := reviews.NewReviewsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to reviews service")
+	}
+
 	h := Handler{
 		l:   l,
 		Env: e,
 		U:   u,
 		C:   c,
+		R:   r,
 	}
 
 	lambda.Start(h.HandleRequest)
@@ -10,6 +10,7 @@ import (
 	"github.com/aws/aws-lambda-go/events"
 
 	"github.com/aschles4/finalProject/internal/services/content"
+	"github.com/aschles4/finalProject/internal/services/reviews"
 	"github.com/aschles4/finalProject/internal/services/users"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/kelseyhightower/envconfig"
@@ -27,12 +28,14 @@ type Env struct {
 	Region      string `required:"true" default:"us-east-1" envconfig:"REGION"`
 	IMBDKey     string `required:"true" default:"" envconfig:"IMBD_KEY"`
 	GuideBoxKey string `required:"true" default:"" envconfig:"GB_KEY"`
+	ImageCDN    string `default:"" envconfig:"IMAGE_CDN"`
 }
 
 type Handler struct {
 	Env Env
 	U   *users.Users
 	C   *content.Content
+	R   *reviews.Reviews
 	l   zerolog.Logger
 }
 
@@ -46,16 +49,40 @@ func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxy
 		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
 	}
 
-	_, err := h.U.FindUserAccountByToken(ctx, token)
+	v, err := h.U.FindViewerByToken(ctx, token)
 	if err != nil {
 		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
 	}
 
+	//titles, release dates, ratings and availability in the viewer's language and region
+	localized := h.C.WithLocale(content.ResolveLocale(users.LocaleFor(v.Profile), event.Headers["Accept-Language"], event.QueryStringParameters["language"], event.QueryStringParameters["region"]))
+	h.C = &localized
+
 	s, err := h.C.FindMovieSuggestions(ctx)
 	if err != nil {
 		return h.handleError(http.StatusInternalServerError, err, "Failed to access content suggestions")
 	}
 
+	//a title the viewer rated has been seen too, even if it never went through the library
+	ratings, err := h.R.FindRatingsByProfileID(ctx, v.Profile.ID)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to access ratings")
+	}
+	rated := reviews.RatedTitles(*ratings, content.MediaMovie)
+
+	//recommendations are per profile, leave out what this viewer already has or has watched
+	seen := users.SeenContent(v.Profile)
+	*s = content.FilterSuggestions(*s, func(key string, t content.Thumbnail) bool {
+		_, ok := rated[key]
+		return !seen[key] && !ok
+	})
+
+	//drop anything over the profile's parental controls
+	*s, err = h.C.FilterByRating(ctx, *s, users.RatingLimitFor(v.Profile))
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to apply parental controls")
+	}
+
 	//return
 	js, err := json.Marshal(FindMovieSuggestionsResponse{
 		Suggestions: *s,
@@ -114,11 +141,21 @@ func main() {
 		l.Fatal().Msg("failed to connect to users service")
 	}
 
+	//images go through the CDN when there is one
+	c.ImageBase = e.ImageCDN
+
+	r, err := reviews.NewReviewsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to reviews service")
+	}
+
 	h := Handler{
 		l:   l,
 		Env: e,
 		U:   u,
 		C:   c,
+		R:   r,
 	}
 
 	lambda.Start(h.HandleRequest)
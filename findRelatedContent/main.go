@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aschles4/finalProject/internal/services/content"
+	"github.com/aschles4/finalProject/internal/services/reviews"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type FindRelatedContentResponse struct {
+	Suggestions []content.Suggestion `json:"suggestions,omitempty"`
+	Status      int                  `json:"status,omitempty"`
+	Message     string               `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection  string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region      string `required:"true" default:"us-east-1" envconfig:"REGION"`
+	IMBDKey     string `required:"true" default:"" envconfig:"IMBD_KEY"`
+	GuideBoxKey string `required:"true" default:"" envconfig:"GB_KEY"`
+	ImageCDN    string `default:"" envconfig:"IMAGE_CDN"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	C   *content.Content
+	R   *reviews.Reviews
+	l   zerolog.Logger
+}
+
+//Finds rows to go on to from a movie or show's detail page, with the services the viewer subscribes to that stream each title
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	v, err := h.U.FindViewerByToken(ctx, token)
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	//titles, release dates, ratings and availability in the viewer's language and region
+	localized := h.C.WithLocale(content.ResolveLocale(users.LocaleFor(v.Profile), event.Headers["Accept-Language"], event.QueryStringParameters["language"], event.QueryStringParameters["region"]))
+	h.C = &localized
+
+	mediaType := event.PathParameters["type"]
+	if mediaType != content.MediaMovie && mediaType != content.MediaTV {
+		return h.handleError(http.StatusBadRequest, nil, "Type must be movie or tv")
+	}
+
+	ID := event.PathParameters["id"]
+	if ID == "" {
+		return h.handleError(http.StatusBadRequest, nil, "ID is required")
+	}
+
+	related, err := h.C.FindRelated(ctx, mediaType, ID)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to access related content")
+	}
+
+	inLibrary := false
+	for _, c := range v.Profile.Library.ContentList {
+		if strconv.FormatInt(c.ID, 10) == ID && (c.Type == mediaType || c.Type == "") {
+			inLibrary = true
+		}
+	}
+	s := related.Rows(inLibrary)
+
+	//a title the viewer rated has been seen too, even if it never went through the library
+	ratings, err := h.R.FindRatingsByProfileID(ctx, v.Profile.ID)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to access ratings")
+	}
+	rated := reviews.RatedTitles(*ratings, "")
+
+	//somewhere to go next, so leave out what this viewer already has or has watched
+	seen := users.SeenContent(v.Profile)
+	s = content.FilterSuggestions(s, func(key string, t content.Thumbnail) bool {
+		_, ok := rated[key]
+		return !seen[key] && !ok
+	})
+
+	//drop anything over the profile's parental controls
+	s, err = h.C.FilterByRating(ctx, s, users.RatingLimitFor(v.Profile))
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to apply parental controls")
+	}
+
+	subscribed := make(map[int64]bool)
+	for _, a := range v.Profile.StreamAccounts {
+		subscribed[a.ID] = true
+	}
+
+	//missing availability shouldn't cost the viewer the rows
+	s, err = h.C.AnnotateProviders(ctx, s, "", subscribed)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	//return
+	js, err := json.Marshal(FindRelatedContentResponse{
+		Suggestions: s,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(FindRelatedContentResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	c, err := content.NewContentService(e.IMBDKey, e.GuideBoxKey)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to content service")
+	}
+
+	//images go through the CDN when there is one
+	c.ImageBase = e.ImageCDN
+
+	r, err := reviews.NewReviewsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to reviews service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		C:   c,
+		R:   r,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
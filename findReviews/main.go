@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	dbReviews "github.com/aschles4/finalProject/internal/pkg/dynamo/reviews"
+	"github.com/aschles4/finalProject/internal/services/reviews"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type FindReviewsResponse struct {
+	Reviews   *[]dbReviews.Review `json:"reviews,omitempty"`
+	Community *reviews.Score      `json:"community,omitempty"`
+	Status    int                 `json:"status,omitempty"`
+	Message   string              `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	R   *reviews.Reviews
+	l   zerolog.Logger
+}
+
+//Lists the written reviews of a movie, show or episode with its community score
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	_, err := h.U.FindViewerByToken(ctx, token)
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	//season and episode are only in the path when reviewing an episode
+	t, err := reviews.NewTarget(event.PathParameters["type"], event.PathParameters["id"], event.PathParameters["season_num"], event.PathParameters["episode_num"])
+	if err != nil {
+		return h.handleError(http.StatusBadRequest, err, "Type must be movie or tv with a numeric ID")
+	}
+
+	limit := 50
+	if val, ok := event.QueryStringParameters["limit"]; ok {
+		limit, err = strconv.Atoi(val)
+		if err != nil || limit < 1 || limit > reviews.MaxReviews {
+			return h.handleError(http.StatusBadRequest, err, fmt.Sprintf("limit must be between 1 and %d", reviews.MaxReviews))
+		}
+	}
+
+	list, score, err := h.R.FindReviews(ctx, *t, limit)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to find reviews")
+	}
+
+	//return, newest first
+	js, err := json.Marshal(FindReviewsResponse{
+		Reviews:   list,
+		Community: score,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(FindReviewsResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	r, err := reviews.NewReviewsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to reviews service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		R:   r,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
@@ -27,6 +27,7 @@ type Env struct {
 	Region      string `required:"true" default:"us-east-1" envconfig:"REGION"`
 	IMBDKey     string `required:"true" default:"" envconfig:"IMBD_KEY"`
 	GuideBoxKey string `required:"true" default:"" envconfig:"GB_KEY"`
+	ImageCDN    string `default:"" envconfig:"IMAGE_CDN"`
 }
 
 type Handler struct {
@@ -46,11 +47,15 @@ func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxy
 		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
 	}
 
-	_, err := h.U.FindUserAccountByToken(ctx, token)
+	v, err := h.U.FindViewerByToken(ctx, token)
 	if err != nil {
 		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
 	}
 
+	//titles, release dates, ratings and availability in the viewer's language and region
+	localized := h.C.WithLocale(content.ResolveLocale(users.LocaleFor(v.Profile), event.Headers["Accept-Language"], event.QueryStringParameters["language"], event.QueryStringParameters["region"]))
+	h.C = &localized
+
 	var showId string
 	if val, ok := event.PathParameters["showid"]; ok {
 		showId = val
@@ -69,6 +74,16 @@ func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxy
 		return h.handleError(http.StatusBadRequest, nil, "Season Number is required")
 	}
 
+	//parental controls on the profile, seasons go by their show
+	check, err := h.C.CheckRating(ctx, users.RatingLimitFor(v.Profile), content.MediaTV, showId)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to apply parental controls")
+	}
+
+	if !check.Allowed {
+		return h.handleError(http.StatusForbidden, nil, "Restricted by parental controls, a parent can unlock it with their PIN")
+	}
+
 	d, err := h.C.FindSeasonDetailsByNumber(ctx, showId, seasonNumber)
 	if err != nil {
 		return h.handleError(http.StatusInternalServerError, err, "Failed to access content details")
@@ -126,12 +141,15 @@ func main() {
 		l.Fatal().Msg("failed to connect to users service")
 	}
 
-	c, err := content.NewContentService(e.IMBDKey)
+	c, err := content.NewContentService(e.IMBDKey, e.GuideBoxKey)
 	if err != nil {
 		l.Info().Msg(err.Error())
 		l.Fatal().Msg("failed to connect to users service")
 	}
 
+	//images go through the CDN when there is one
+	c.ImageBase = e.ImageCDN
+
 	h := Handler{
 		l:   l,
 		Env: e,
@@ -16,9 +16,12 @@ import (
 )
 
 type FindUserProfileResponse struct {
+	ID             string                   `json:"id,omitempty"`
 	Email          string                   `json:"email,omitempty"`
 	Name           string                   `json:"name,omitempty"`
+	Kids           bool                     `json:"kids,omitempty"`
 	StreamAccounts []dbUsers.StreamAccounts `json:"streamAccounts,omitempty"`
+	Library        *dbUsers.Library         `json:"library,omitempty"`
 	Status         int                      `json:"status,omitempty"`
 	Message        string                   `json:"message,omitempty"`
 }
@@ -45,22 +48,30 @@ func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxy
 		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
 	}
 
-	act, err := h.U.FindUserAccountByToken(ctx, token)
+	//the profile picked with selectProfile, or the owner's for an account token
+	v, err := h.U.FindViewerForScope(ctx, token, users.ScopeReadProfile)
+	if err == users.ErrForbidden {
+		return h.handleError(http.StatusForbidden, nil, "API key does not have the profile:read scope")
+	}
 	if err != nil {
 		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
 	}
+	prof := v.Profile
 
-	prof, err := h.U.FindUserProfileByID(ctx, act.ID)
-	if err != nil {
-		return h.handleError(http.StatusInternalServerError, err, "Failed to access user profile")
+	resp := FindUserProfileResponse{
+		ID:             prof.ID,
+		Email:          v.Account.Email,
+		Name:           prof.Name,
+		Kids:           prof.Kids,
+		StreamAccounts: prof.StreamAccounts,
+	}
+	//an API key only sees the library with library:read as well
+	if v.HasScope(users.ScopeReadLibrary) {
+		resp.Library = &prof.Library
 	}
 
 	//return
-	js, err := json.Marshal(FindUserProfileResponse{
-		Email:          act.Email,
-		Name:           prof.Name,
-		StreamAccounts: prof.StreamAccounts,
-	})
+	js, err := json.Marshal(resp)
 	if err != nil {
 		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
 	}
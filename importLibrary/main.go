@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	dbUsers "github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/pkg/imports"
+	"github.com/aschles4/finalProject/internal/services/content"
+	"github.com/aschles4/finalProject/internal/services/reviews"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+//ImportReport counts what was added, rows already in the library, history or ratings are matched
+//but not counted again. Unmatched rows can be added by hand from their candidates. FailedRatings
+//are matched rows whose title was imported but whose rating wasn't, only the rating needs redoing.
+type ImportReport struct {
+	Source        string         `json:"source"`
+	Rows          int            `json:"rows"`
+	Matched       int            `json:"matched"`
+	Library       int            `json:"library"`
+	Watched       int            `json:"watched"`
+	Ratings       int            `json:"ratings"`
+	Unmatched     []UnmatchedRow `json:"unmatched"`
+	FailedRatings []UnmatchedRow `json:"failedRatings"`
+}
+
+type UnmatchedRow struct {
+	Line       int             `json:"line"`
+	Title      string          `json:"title,omitempty"`
+	Year       int             `json:"year,omitempty"`
+	IMDbID     string          `json:"imdbId,omitempty"`
+	Season     int             `json:"season,omitempty"`
+	Episode    int             `json:"episode,omitempty"`
+	Reason     string          `json:"reason"`
+	Candidates []content.Match `json:"candidates,omitempty"`
+}
+
+type ImportLibraryResponse struct {
+	Report  *ImportReport `json:"report,omitempty"`
+	Status  int           `json:"status,omitempty"`
+	Message string        `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection  string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region      string `required:"true" default:"us-east-1" envconfig:"REGION"`
+	IMBDKey     string `required:"true" default:"" envconfig:"IMBD_KEY"`
+	GuideBoxKey string `required:"true" default:"" envconfig:"GB_KEY"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	C   *content.Content
+	R   *reviews.Reviews
+	l   zerolog.Logger
+}
+
+//Imports one export file into the selected profile. The body is the file as downloaded, source
+//says where it came from and file=watchlist marks Letterboxd's watchlist.csv.
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	v, err := h.U.FindViewerForScope(ctx, token, users.ScopeWriteLibrary)
+	if err == users.ErrForbidden {
+		return h.handleError(http.StatusForbidden, nil, "API key does not have the library:write scope")
+	}
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	source := strings.ToLower(event.QueryStringParameters["source"])
+
+	data := []byte(event.Body)
+	if event.IsBase64Encoded {
+		data, err = base64.StdEncoding.DecodeString(event.Body)
+		if err != nil {
+			return h.handleError(http.StatusBadRequest, err, "Failed to decode body")
+		}
+	}
+
+	rows, err := imports.Parse(source, strings.ToLower(event.QueryStringParameters["file"]), data)
+	if err == imports.ErrUnknownSource {
+		return h.handleError(http.StatusBadRequest, nil, "source must be one of "+strings.Join(imports.Sources, ", "))
+	}
+	if err == imports.ErrUnknownFormat || err == imports.ErrEmptyFile {
+		return h.handleError(http.StatusBadRequest, err, "File is not a "+source+" export or has no rows")
+	}
+	if err == imports.ErrTooManyRows {
+		return h.handleError(http.StatusRequestEntityTooLarge, nil, fmt.Sprintf("Files can have %d rows at most, split it and import each part", imports.MaxRows))
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to read file")
+	}
+
+	//skipped rows aren't looked up
+	queries := make([]content.TitleQuery, 0, len(rows))
+	for _, r := range rows {
+		if r.Skip == "" {
+			queries = append(queries, titleQuery(r))
+		}
+	}
+	resolved := h.C.ResolveTitles(ctx, queries)
+
+	report := ImportReport{
+		Source:        source,
+		Rows:          len(rows),
+		Unmatched:     make([]UnmatchedRow, 0),
+		FailedRatings: make([]UnmatchedRow, 0),
+	}
+	library := make([]dbUsers.Content, 0)
+	watched := make([]dbUsers.Watched, 0)
+
+	next := 0
+	for _, r := range rows {
+		if r.Skip != "" {
+			report.Unmatched = append(report.Unmatched, unmatched(r, r.Skip, nil))
+			continue
+		}
+
+		res := resolved[next]
+		next++
+
+		if res.Err != nil {
+			h.l.Error().Int("line", r.Line).Msg(res.Err.Error())
+			report.Unmatched = append(report.Unmatched, unmatched(r, "lookup failed, try the row again later", nil))
+			continue
+		}
+
+		m := res.Match
+		if m == nil {
+			report.Unmatched = append(report.Unmatched, unmatched(r, "no title was a close enough match", res.Candidates))
+			continue
+		}
+
+		if r.MediaType == imports.MediaEpisode && m.Episode == 0 {
+			report.Unmatched = append(report.Unmatched, unmatched(r, "episode was not found", []content.Match{*m}))
+			continue
+		}
+		report.Matched++
+
+		if r.Library {
+			library = append(library, dbUsers.Content{ID: m.ID, Type: m.MediaType})
+		}
+
+		if r.WatchedAt != 0 {
+			watched = append(watched, dbUsers.Watched{
+				ID:        m.ID,
+				Type:      m.MediaType,
+				Season:    m.Season,
+				Episode:   m.Episode,
+				WatchedAt: r.WatchedAt,
+			})
+		}
+
+		if r.Rating != 0 {
+			t := reviews.Target{
+				MediaType: m.MediaType,
+				TMDBID:    strconv.FormatInt(m.ID, 10),
+				Season:    m.Season,
+				Episode:   m.Episode,
+			}
+
+			saved, err := h.R.ImportRating(ctx, v.Account.ID, v.Profile.ID, v.Profile.Name, t, r.Rating, r.RatedAt)
+			if err == reviews.ErrInvalidRating {
+				report.FailedRatings = append(report.FailedRatings, unmatched(r, "rating must be between 1 and 10", nil))
+				continue
+			}
+			//the rest of the import is still saved, the report says which ratings to try again
+			if err != nil {
+				h.l.Error().Int("line", r.Line).Msg(err.Error())
+				report.FailedRatings = append(report.FailedRatings, unmatched(r, "rating could not be saved, rate the title again later", nil))
+				continue
+			}
+			if saved {
+				report.Ratings++
+			}
+		}
+	}
+
+	report.Library, report.Watched, err = h.U.ImportToProfile(ctx, v.Profile, library, watched)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to save library and watch history")
+	}
+
+	//return
+	js, err := json.Marshal(ImportLibraryResponse{
+		Report: &report,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+//titleQuery looks episodes up through their show. IMDb only has the episode's own ID and a title
+//made of the show and episode names, searching that would find the wrong show, so it isn't sent.
+func titleQuery(r imports.Row) content.TitleQuery {
+	q := content.TitleQuery{
+		MediaType: r.MediaType,
+		Title:     r.Title,
+		Year:      r.Year,
+		IMDbID:    r.IMDbID,
+		TMDBID:    r.TMDBID,
+		Season:    r.Season,
+		Episode:   r.Episode,
+	}
+
+	if r.MediaType == imports.MediaEpisode {
+		q.MediaType = content.MediaTV
+		if r.Episode == 0 {
+			q.Title = ""
+		}
+	}
+
+	return q
+}
+
+func unmatched(r imports.Row, reason string, candidates []content.Match) UnmatchedRow {
+	return UnmatchedRow{
+		Line:       r.Line,
+		Title:      r.Title,
+		Year:       r.Year,
+		IMDbID:     r.IMDbID,
+		Season:     r.Season,
+		Episode:    r.Episode,
+		Reason:     reason,
+		Candidates: candidates,
+	}
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(ImportLibraryResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	c, err := content.NewContentService(e.IMBDKey, e.GuideBoxKey)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to content service")
+	}
+
+	r, err := reviews.NewReviewsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to reviews service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		C:   c,
+		R:   r,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+//Event is one line of the audit log. UserID is the account the event is about, ActorID is set
+//when someone else, like an admin, did it.
+type Event struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"userId"`
+	ActorID   string            `json:"actorId,omitempty"`
+	Action    string            `json:"action"`
+	SourceIP  string            `json:"sourceIp,omitempty"`
+	UserAgent string            `json:"userAgent,omitempty"`
+	Data      map[string]string `json:"data,omitempty"`
+	CreatedAt int64             `json:"createdAt"`
+}
+
+//CreateEvent never overwrites, the log is append only
+func (s Store) CreateEvent(ctx context.Context, event Event) error {
+	m, err := dynamodbattribute.MarshalMap(event)
+	if err != nil {
+		return err
+	}
+
+	// create the api params
+	params := &dynamodb.PutItemInput{
+		TableName:           aws.String("AuditEvents"),
+		Item:                m,
+		ConditionExpression: aws.String("attribute_not_exists(id)"),
+	}
+
+	// put the item
+	_, err = s.db.PutItem(params)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s Store) FindEventsByUserID(ctx context.Context, userID string) (*[]Event, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("AuditEvents"),
+		FilterExpression: aws.String("userId = :u"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":u": {S: aws.String(userID)},
+		},
+	}
+
+	return s.scanEvents(params)
+}
+
+func (s Store) FindEventsByActorID(ctx context.Context, actorID string) (*[]Event, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("AuditEvents"),
+		FilterExpression: aws.String("actorId = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":a": {S: aws.String(actorID)},
+		},
+	}
+
+	return s.scanEvents(params)
+}
+
+//DynamoDB Helpers
+func (s Store) scanEvents(params *dynamodb.ScanInput) (*[]Event, error) {
+	events := make([]Event, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Event
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &events, nil
+}
@@ -0,0 +1,256 @@
+package groups
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+//Session is a group deciding what to watch. Members join with Code, vote on Candidates once the
+//host starts voting, and the session ends with Pick. Sessions, members and votes all expire
+//through the tables' ttl attribute.
+type Session struct {
+	ID            string      `json:"id"`
+	Code          string      `json:"code"`
+	HostID        string      `json:"hostId"`
+	HostAccountID string      `json:"hostAccountId,omitempty"`
+	Status        string      `json:"status"`
+	Candidates    []Candidate `json:"candidates,omitempty"`
+	Pick          *Candidate  `json:"pick,omitempty"`
+	CreatedAt     int64       `json:"createdAt"`
+	ResolvedAt    int64       `json:"resolvedAt,omitempty"`
+	ExpiresAt     int64       `json:"ttl"`
+}
+
+//Candidate is a title put to the vote, Libraries is how many members have it in their library
+type Candidate struct {
+	Type      string  `json:"type"`
+	ID        int64   `json:"id"`
+	Libraries int     `json:"libraries"`
+	Providers []int64 `json:"providers,omitempty"`
+}
+
+//Member is a profile in a session, ID is sessionId#profileId
+type Member struct {
+	ID        string `json:"id"`
+	SessionID string `json:"sessionId"`
+	ProfileID string `json:"profileId"`
+	AccountID string `json:"accountId,omitempty"`
+	Name      string `json:"name"`
+	JoinedAt  int64  `json:"joinedAt"`
+	ExpiresAt int64  `json:"ttl"`
+}
+
+//Vote is a member's yes or no on a candidate, ID is sessionId#profileId#type:id so voting again
+//replaces it
+type Vote struct {
+	ID         string `json:"id"`
+	SessionID  string `json:"sessionId"`
+	ProfileID  string `json:"profileId"`
+	AccountID  string `json:"accountId,omitempty"`
+	ContentKey string `json:"contentKey"`
+	Value      int    `json:"value"`
+	CreatedAt  int64  `json:"createdAt"`
+	ExpiresAt  int64  `json:"ttl"`
+}
+
+func (s Store) CreateSession(ctx context.Context, session Session) error {
+	return s.writeToTable(session, "GroupSessions")
+}
+
+func (s Store) FindSessionByID(ctx context.Context, ID string) (*Session, error) {
+	var session Session
+	found, err := s.findByID(ID, "GroupSessions", &session)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+//FindSessionsByCode can return expired sessions the ttl hasn't removed yet, codes are reused
+func (s Store) FindSessionsByCode(ctx context.Context, code string) (*[]Session, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("GroupSessions"),
+		FilterExpression: aws.String("code = :c"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":c": {S: aws.String(code)},
+		},
+	}
+
+	return s.scanSessions(params)
+}
+
+func (s Store) FindSessionsByHostAccountID(ctx context.Context, accountID string) (*[]Session, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("GroupSessions"),
+		FilterExpression: aws.String("hostAccountId = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":a": {S: aws.String(accountID)},
+		},
+	}
+
+	return s.scanSessions(params)
+}
+
+func (s Store) RemoveSessionByID(ctx context.Context, ID string) error {
+	return s.deleteFromTableByID(ID, "GroupSessions")
+}
+
+func (s Store) CreateMember(ctx context.Context, m Member) error {
+	return s.writeToTable(m, "GroupMembers")
+}
+
+func (s Store) FindMemberByID(ctx context.Context, ID string) (*Member, error) {
+	var m Member
+	found, err := s.findByID(ID, "GroupMembers", &m)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+func (s Store) FindMembersBySessionID(ctx context.Context, sessionID string) (*[]Member, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("GroupMembers"),
+		FilterExpression: aws.String("sessionId = :s"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":s": {S: aws.String(sessionID)},
+		},
+	}
+
+	return s.scanMembers(params)
+}
+
+func (s Store) FindMembersByAccountID(ctx context.Context, accountID string) (*[]Member, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("GroupMembers"),
+		FilterExpression: aws.String("accountId = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":a": {S: aws.String(accountID)},
+		},
+	}
+
+	return s.scanMembers(params)
+}
+
+func (s Store) RemoveMemberByID(ctx context.Context, ID string) error {
+	return s.deleteFromTableByID(ID, "GroupMembers")
+}
+
+func (s Store) CreateVote(ctx context.Context, v Vote) error {
+	return s.writeToTable(v, "GroupVotes")
+}
+
+func (s Store) FindVotesBySessionID(ctx context.Context, sessionID string) (*[]Vote, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("GroupVotes"),
+		FilterExpression: aws.String("sessionId = :s"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":s": {S: aws.String(sessionID)},
+		},
+	}
+
+	return s.scanVotes(params)
+}
+
+func (s Store) FindVotesByAccountID(ctx context.Context, accountID string) (*[]Vote, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("GroupVotes"),
+		FilterExpression: aws.String("accountId = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":a": {S: aws.String(accountID)},
+		},
+	}
+
+	return s.scanVotes(params)
+}
+
+func (s Store) RemoveVoteByID(ctx context.Context, ID string) error {
+	return s.deleteFromTableByID(ID, "GroupVotes")
+}
+
+//DynamoDB Helpers
+func (s Store) scanSessions(params *dynamodb.ScanInput) (*[]Session, error) {
+	sessions := make([]Session, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Session
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &sessions, nil
+}
+
+func (s Store) scanMembers(params *dynamodb.ScanInput) (*[]Member, error) {
+	members := make([]Member, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Member
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &members, nil
+}
+
+func (s Store) scanVotes(params *dynamodb.ScanInput) (*[]Vote, error) {
+	votes := make([]Vote, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Vote
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		votes = append(votes, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &votes, nil
+}
@@ -0,0 +1,99 @@
+package groups
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+type Store struct {
+	db *dynamodb.DynamoDB
+}
+
+func NewStore(conn, region string) (*Store, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region:   aws.String(region),
+		Endpoint: aws.String(conn),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	db := dynamodb.New(sess)
+
+	return &Store{
+		db: db,
+	}, nil
+}
+
+//DynamoDB Helpers
+func (s Store) findByID(ID, table string, v interface{}) (bool, error) {
+	// create the api params
+	params := &dynamodb.GetItemInput{
+		TableName: aws.String(table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+	}
+
+	// read the item
+	resp, err := s.db.GetItem(params)
+	if err != nil {
+		return false, err
+	}
+
+	if len(resp.Item) == 0 {
+		return false, nil
+	}
+
+	err = dynamodbattribute.UnmarshalMap(resp.Item, v)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s Store) deleteFromTableByID(ID, table string) error {
+	// create the api params
+	params := &dynamodb.DeleteItemInput{
+		TableName: aws.String(table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+	}
+
+	// delete the item
+	_, err := s.db.DeleteItem(params)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s Store) writeToTable(doc interface{}, table string) error {
+	m, err := dynamodbattribute.MarshalMap(doc)
+	if err != nil {
+		return err
+	}
+
+	// create the api params
+	params := &dynamodb.PutItemInput{
+		TableName: aws.String(table),
+		Item:      m,
+	}
+
+	// put the item
+	_, err = s.db.PutItem(params)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+const (
+	DeliveryPending = "pending"
+	DeliveryDead    = "dead"
+)
+
+type Recipient struct {
+	UserID        string `json:"userId"`
+	AccountID     string `json:"accountId,omitempty"`
+	Name          string `json:"name"`
+	Email         string `json:"email"`
+	WebhookURL    string `json:"webhookUrl,omitempty"`
+	WebhookSecret string `json:"webhookSecret,omitempty"`
+}
+
+//Delivery only keeps the user ID, the address and webhook secret are looked up when it is sent
+type Delivery struct {
+	ID            string `json:"id"`
+	Channel       string `json:"channel"`
+	Status        string `json:"deliveryStatus"`
+	Attempts      int    `json:"attempts"`
+	NextAttemptAt int64  `json:"nextAttemptAt"`
+	LastError     string `json:"lastError,omitempty"`
+	UserID        string `json:"userId"`
+	AccountID     string `json:"accountId,omitempty"`
+	Event         Event  `json:"event"`
+	CreatedAt     int64  `json:"createdAt"`
+	UpdatedAt     int64  `json:"updatedAt"`
+	//dead deliveries are kept for a while to look into, then DynamoDB expires them
+	ExpiresAt int64 `json:"ttl,omitempty"`
+}
+
+func (s Store) CreateDelivery(ctx context.Context, delivery Delivery) error {
+	return s.writeToTable(delivery, "Deliveries")
+}
+
+func (s Store) UpdateDelivery(ctx context.Context, delivery Delivery) error {
+	return s.writeToTable(delivery, "Deliveries")
+}
+
+//FindDueDeliveries returns pending deliveries on the channel whose next attempt is at or before now
+func (s Store) FindDueDeliveries(ctx context.Context, channel string, now int64) (*[]Delivery, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Deliveries"),
+		FilterExpression: aws.String("channel = :c AND deliveryStatus = :s AND nextAttemptAt <= :n"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":c": {S: aws.String(channel)},
+			":s": {S: aws.String(DeliveryPending)},
+			":n": {N: aws.String(fmt.Sprintf("%d", now))},
+		},
+	}
+
+	return s.scanDeliveries(params)
+}
+
+func (s Store) FindDeliveriesByUserID(ctx context.Context, userID string) (*[]Delivery, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Deliveries"),
+		FilterExpression: aws.String("userId = :u"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":u": {S: aws.String(userID)},
+		},
+	}
+
+	return s.scanDeliveries(params)
+}
+
+//FindDeliveriesByAccountID is every delivery for a profile of the account. Deliveries queued before
+//accountId was stored only have the owner's userId, which is the account ID.
+func (s Store) FindDeliveriesByAccountID(ctx context.Context, accountID string) (*[]Delivery, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Deliveries"),
+		FilterExpression: aws.String("accountId = :a OR userId = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":a": {S: aws.String(accountID)},
+		},
+	}
+
+	return s.scanDeliveries(params)
+}
+
+func (s Store) RemoveDeliveryByID(ctx context.Context, ID string) error {
+	return s.deleteFromTableByID(ID, "Deliveries")
+}
+
+//DynamoDB Helpers
+func (s Store) scanDeliveries(params *dynamodb.ScanInput) (*[]Delivery, error) {
+	deliveries := make([]Delivery, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Delivery
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &deliveries, nil
+}
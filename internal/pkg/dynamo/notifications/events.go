@@ -0,0 +1,123 @@
+package notifications
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+type Event struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"userId"`
+	AccountID string            `json:"accountId,omitempty"`
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Message   string            `json:"message"`
+	Data      map[string]string `json:"data,omitempty"`
+	CreatedAt int64             `json:"createdAt"`
+}
+
+func (s Store) CreateEvent(ctx context.Context, event Event) error {
+	return s.writeToTable(event, "Notifications")
+}
+
+func (s Store) FindEventsByUserID(ctx context.Context, userID string) (*[]Event, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Notifications"),
+		FilterExpression: aws.String("userId = :u"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":u": {S: aws.String(userID)},
+		},
+	}
+
+	return s.scanEvents(params)
+}
+
+//FindEventsByAccountID is every event for a profile of the account. Events recorded before
+//accountId was stored only have the owner's userId, which is the account ID.
+func (s Store) FindEventsByAccountID(ctx context.Context, accountID string) (*[]Event, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Notifications"),
+		FilterExpression: aws.String("accountId = :a OR userId = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":a": {S: aws.String(accountID)},
+		},
+	}
+
+	return s.scanEvents(params)
+}
+
+func (s Store) RemoveEventByID(ctx context.Context, ID string) error {
+	return s.deleteFromTableByID(ID, "Notifications")
+}
+
+//DynamoDB Helpers
+func (s Store) scanEvents(params *dynamodb.ScanInput) (*[]Event, error) {
+	events := make([]Event, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Event
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &events, nil
+}
+
+func (s Store) deleteFromTableByID(ID, table string) error {
+	// create the api params
+	params := &dynamodb.DeleteItemInput{
+		TableName: aws.String(table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+	}
+
+	// delete the item
+	_, err := s.db.DeleteItem(params)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s Store) writeToTable(doc interface{}, table string) error {
+	m, err := dynamodbattribute.MarshalMap(doc)
+	if err != nil {
+		return err
+	}
+
+	// create the api params
+	params := &dynamodb.PutItemInput{
+		TableName: aws.String(table),
+		Item:      m,
+	}
+
+	// put the item
+	_, err = s.db.PutItem(params)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+package parties
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+//Party is a watch party for a movie or an episode of a show, Season and Episode are only set for
+//an episode. ContentTitle is looked up when the party is made so invites and calendars can name
+//it. Sequence goes up each time the time changes, calendar clients use it to replace the entry.
+type Party struct {
+	ID            string `json:"id"`
+	HostID        string `json:"hostId"`
+	HostAccountID string `json:"hostAccountId,omitempty"`
+	HostName      string `json:"hostName"`
+	Name          string `json:"name,omitempty"`
+	Note          string `json:"note,omitempty"`
+	MediaType     string `json:"mediaType"`
+	TMDBID        int64  `json:"tmdbId"`
+	Season        int    `json:"season,omitempty"`
+	Episode       int    `json:"episode,omitempty"`
+	ContentTitle  string `json:"contentTitle,omitempty"`
+	StartsAt      int64  `json:"startsAt"`
+	Duration      int    `json:"duration"`
+	Status        string `json:"status"`
+	Sequence      int    `json:"sequence"`
+	CreatedAt     int64  `json:"createdAt"`
+	UpdatedAt     int64  `json:"updatedAt"`
+	ExpiresAt     int64  `json:"ttl"`
+}
+
+//Invite is one profile's place at a party, the host has one too. ID is partyId#profileId.
+//RemindBefore is how many minutes ahead the profile wants reminding, none when zero. RemindAt is
+//when that is for the party's current time and RemindedAt is set once the reminder has gone.
+type Invite struct {
+	ID           string `json:"id"`
+	PartyID      string `json:"partyId"`
+	ProfileID    string `json:"profileId"`
+	AccountID    string `json:"accountId,omitempty"`
+	Name         string `json:"name"`
+	RSVP         string `json:"rsvp"`
+	RespondedAt  int64  `json:"respondedAt,omitempty"`
+	RemindBefore int    `json:"remindBefore"`
+	RemindAt     int64  `json:"remindAt,omitempty"`
+	RemindedAt   int64  `json:"remindedAt,omitempty"`
+	CreatedAt    int64  `json:"createdAt"`
+	ExpiresAt    int64  `json:"ttl"`
+}
+
+func (s Store) CreateParty(ctx context.Context, p Party) error {
+	return s.writeToTable(p, "WatchParties")
+}
+
+func (s Store) FindPartyByID(ctx context.Context, ID string) (*Party, error) {
+	var p Party
+	found, err := s.findByID(ID, "WatchParties", &p)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func (s Store) FindPartiesByHostAccountID(ctx context.Context, accountID string) (*[]Party, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("WatchParties"),
+		FilterExpression: aws.String("hostAccountId = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":a": {S: aws.String(accountID)},
+		},
+	}
+
+	parties := make([]Party, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Party
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		parties = append(parties, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &parties, nil
+}
+
+func (s Store) RemovePartyByID(ctx context.Context, ID string) error {
+	return s.deleteFromTableByID(ID, "WatchParties")
+}
+
+func (s Store) CreateInvite(ctx context.Context, i Invite) error {
+	return s.writeToTable(i, "PartyInvites")
+}
+
+func (s Store) FindInviteByID(ctx context.Context, ID string) (*Invite, error) {
+	var i Invite
+	found, err := s.findByID(ID, "PartyInvites", &i)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &i, nil
+}
+
+func (s Store) FindInvitesByPartyID(ctx context.Context, partyID string) (*[]Invite, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("PartyInvites"),
+		FilterExpression: aws.String("partyId = :p"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":p": {S: aws.String(partyID)},
+		},
+	}
+
+	return s.scanInvites(params)
+}
+
+func (s Store) FindInvitesByProfileID(ctx context.Context, profileID string) (*[]Invite, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("PartyInvites"),
+		FilterExpression: aws.String("profileId = :p"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":p": {S: aws.String(profileID)},
+		},
+	}
+
+	return s.scanInvites(params)
+}
+
+func (s Store) FindInvitesByAccountID(ctx context.Context, accountID string) (*[]Invite, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("PartyInvites"),
+		FilterExpression: aws.String("accountId = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":a": {S: aws.String(accountID)},
+		},
+	}
+
+	return s.scanInvites(params)
+}
+
+//FindDueReminders is every invite whose reminder time has passed without one being sent
+func (s Store) FindDueReminders(ctx context.Context, now int64) (*[]Invite, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("PartyInvites"),
+		FilterExpression: aws.String("remindAt > :z AND remindAt <= :n AND attribute_not_exists(remindedAt)"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":z": {N: aws.String("0")},
+			":n": {N: aws.String(fmt.Sprintf("%d", now))},
+		},
+	}
+
+	return s.scanInvites(params)
+}
+
+func (s Store) RemoveInviteByID(ctx context.Context, ID string) error {
+	return s.deleteFromTableByID(ID, "PartyInvites")
+}
+
+//DynamoDB Helpers
+func (s Store) scanInvites(params *dynamodb.ScanInput) (*[]Invite, error) {
+	invites := make([]Invite, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Invite
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &invites, nil
+}
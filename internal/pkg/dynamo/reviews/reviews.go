@@ -0,0 +1,164 @@
+package reviews
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+//Review is one profile's rating of a movie, show or episode. ContentKey identifies the title
+//so everything said about it can be found together, AccountID is kept for account deletion.
+type Review struct {
+	ID         string `json:"id"`
+	ContentKey string `json:"contentKey"`
+	AccountID  string `json:"accountId"`
+	ProfileID  string `json:"profileId"`
+	Author     string `json:"author"`
+	MediaType  string `json:"mediaType"`
+	TMDBID     string `json:"tmdbId"`
+	Season     int    `json:"season,omitempty"`
+	Episode    int    `json:"episode,omitempty"`
+	Rating     int    `json:"rating"`
+	Text       string `json:"text,omitempty"`
+	CreatedAt  int64  `json:"createdAt"`
+	UpdatedAt  int64  `json:"updatedAt"`
+}
+
+//CreateReview also replaces, there is one review per profile and title
+func (s Store) CreateReview(ctx context.Context, review Review) error {
+	m, err := dynamodbattribute.MarshalMap(review)
+	if err != nil {
+		return err
+	}
+
+	// create the api params
+	params := &dynamodb.PutItemInput{
+		TableName: aws.String("Reviews"),
+		Item:      m,
+	}
+
+	// put the item
+	_, err = s.db.PutItem(params)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s Store) FindReviewByID(ctx context.Context, ID string) (*Review, error) {
+	// create the api params
+	params := &dynamodb.GetItemInput{
+		TableName: aws.String("Reviews"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+	}
+
+	// read the item
+	resp, err := s.db.GetItem(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Item) == 0 {
+		return nil, nil
+	}
+
+	var r Review
+	err = dynamodbattribute.UnmarshalMap(resp.Item, &r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+func (s Store) FindReviewsByContentKey(ctx context.Context, contentKey string) (*[]Review, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Reviews"),
+		FilterExpression: aws.String("contentKey = :c"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":c": {S: aws.String(contentKey)},
+		},
+	}
+
+	return s.scanReviews(params)
+}
+
+func (s Store) FindReviewsByProfileID(ctx context.Context, profileID string) (*[]Review, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Reviews"),
+		FilterExpression: aws.String("profileId = :p"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":p": {S: aws.String(profileID)},
+		},
+	}
+
+	return s.scanReviews(params)
+}
+
+func (s Store) FindReviewsByAccountID(ctx context.Context, accountID string) (*[]Review, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Reviews"),
+		FilterExpression: aws.String("accountId = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":a": {S: aws.String(accountID)},
+		},
+	}
+
+	return s.scanReviews(params)
+}
+
+func (s Store) RemoveReviewByID(ctx context.Context, ID string) error {
+	// create the api params
+	params := &dynamodb.DeleteItemInput{
+		TableName: aws.String("Reviews"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+	}
+
+	// delete the item
+	_, err := s.db.DeleteItem(params)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//DynamoDB Helpers
+func (s Store) scanReviews(params *dynamodb.ScanInput) (*[]Review, error) {
+	reviews := make([]Review, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Review
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &reviews, nil
+}
@@ -0,0 +1,250 @@
+package social
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+//Connection is one profile following another, ID is followerId#followeeId. Status is pending
+//until a private profile accepts it. Names are copied in when the request is made.
+type Connection struct {
+	ID                string `json:"id"`
+	FollowerID        string `json:"followerId"`
+	FollowerAccountID string `json:"followerAccountId,omitempty"`
+	FollowerName      string `json:"followerName"`
+	FolloweeID        string `json:"followeeId"`
+	FolloweeAccountID string `json:"followeeAccountId,omitempty"`
+	FolloweeName      string `json:"followeeName"`
+	Status            string `json:"status"`
+	CreatedAt         int64  `json:"createdAt"`
+	AcceptedAt        int64  `json:"acceptedAt,omitempty"`
+}
+
+//Block stops BlockedID following, requesting to follow or seeing the activity of BlockerID, ID is blockerId#blockedId
+type Block struct {
+	ID               string `json:"id"`
+	BlockerID        string `json:"blockerId"`
+	BlockerAccountID string `json:"blockerAccountId,omitempty"`
+	BlockedID        string `json:"blockedId"`
+	BlockedAccountID string `json:"blockedAccountId,omitempty"`
+	CreatedAt        int64  `json:"createdAt"`
+}
+
+//Settings are a profile's privacy choices, Publish lists the activity followers are sent
+type Settings struct {
+	ID        string   `json:"id"`
+	AccountID string   `json:"accountId,omitempty"`
+	Private   bool     `json:"private"`
+	Publish   []string `json:"publish"`
+	UpdatedAt int64    `json:"updatedAt"`
+}
+
+//CreateConnection also saves an accepted request
+func (s Store) CreateConnection(ctx context.Context, c Connection) error {
+	return s.writeToTable(c, "Connections")
+}
+
+func (s Store) FindConnectionByID(ctx context.Context, ID string) (*Connection, error) {
+	var c Connection
+	found, err := s.findByID(ID, "Connections", &c)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (s Store) FindConnectionsByFollowerID(ctx context.Context, followerID string) (*[]Connection, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Connections"),
+		FilterExpression: aws.String("followerId = :f"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":f": {S: aws.String(followerID)},
+		},
+	}
+
+	return s.scanConnections(params)
+}
+
+func (s Store) FindConnectionsByFolloweeID(ctx context.Context, followeeID string) (*[]Connection, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Connections"),
+		FilterExpression: aws.String("followeeId = :f"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":f": {S: aws.String(followeeID)},
+		},
+	}
+
+	return s.scanConnections(params)
+}
+
+//FindConnectionsByAccountID is every connection a profile of the account is on either end of
+func (s Store) FindConnectionsByAccountID(ctx context.Context, accountID string) (*[]Connection, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Connections"),
+		FilterExpression: aws.String("followerAccountId = :a OR followeeAccountId = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":a": {S: aws.String(accountID)},
+		},
+	}
+
+	return s.scanConnections(params)
+}
+
+func (s Store) RemoveConnectionByID(ctx context.Context, ID string) error {
+	return s.deleteFromTableByID(ID, "Connections")
+}
+
+func (s Store) CreateBlock(ctx context.Context, b Block) error {
+	return s.writeToTable(b, "Blocks")
+}
+
+func (s Store) FindBlockByID(ctx context.Context, ID string) (*Block, error) {
+	var b Block
+	found, err := s.findByID(ID, "Blocks", &b)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &b, nil
+}
+
+//FindBlocksByProfileID is the profile's blocks and the blocks against it
+func (s Store) FindBlocksByProfileID(ctx context.Context, profileID string) (*[]Block, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Blocks"),
+		FilterExpression: aws.String("blockerId = :p OR blockedId = :p"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":p": {S: aws.String(profileID)},
+		},
+	}
+
+	return s.scanBlocks(params)
+}
+
+func (s Store) FindBlocksByAccountID(ctx context.Context, accountID string) (*[]Block, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Blocks"),
+		FilterExpression: aws.String("blockerAccountId = :a OR blockedAccountId = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":a": {S: aws.String(accountID)},
+		},
+	}
+
+	return s.scanBlocks(params)
+}
+
+func (s Store) RemoveBlockByID(ctx context.Context, ID string) error {
+	return s.deleteFromTableByID(ID, "Blocks")
+}
+
+func (s Store) CreateSettings(ctx context.Context, settings Settings) error {
+	return s.writeToTable(settings, "SocialSettings")
+}
+
+func (s Store) FindSettingsByID(ctx context.Context, ID string) (*Settings, error) {
+	var st Settings
+	found, err := s.findByID(ID, "SocialSettings", &st)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	return &st, nil
+}
+
+func (s Store) FindSettingsByAccountID(ctx context.Context, accountID string) (*[]Settings, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("SocialSettings"),
+		FilterExpression: aws.String("accountId = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":a": {S: aws.String(accountID)},
+		},
+	}
+
+	settings := make([]Settings, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Settings
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		settings = append(settings, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &settings, nil
+}
+
+func (s Store) RemoveSettingsByID(ctx context.Context, ID string) error {
+	return s.deleteFromTableByID(ID, "SocialSettings")
+}
+
+//DynamoDB Helpers
+func (s Store) scanConnections(params *dynamodb.ScanInput) (*[]Connection, error) {
+	connections := make([]Connection, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Connection
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		connections = append(connections, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &connections, nil
+}
+
+func (s Store) scanBlocks(params *dynamodb.ScanInput) (*[]Block, error) {
+	blocks := make([]Block, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Block
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &blocks, nil
+}
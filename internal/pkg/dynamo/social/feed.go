@@ -0,0 +1,142 @@
+package social
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+//FeedItem is one piece of activity copied into a follower's feed. The FeedItems table is keyed on
+//feedId, the follower's profile, and sk, which sorts by time, so a feed is read with one query.
+//Items expire through the table's ttl attribute.
+type FeedItem struct {
+	FeedID         string `json:"feedId"`
+	SK             string `json:"sk"`
+	OwnerAccountID string `json:"ownerAccountId,omitempty"`
+	ActorID        string `json:"actorId"`
+	ActorAccountID string `json:"actorAccountId,omitempty"`
+	ActorName      string `json:"actorName"`
+	Verb           string `json:"verb"`
+	MediaType      string `json:"mediaType"`
+	TMDBID         int64  `json:"tmdbId"`
+	Season         int    `json:"season,omitempty"`
+	Episode        int    `json:"episode,omitempty"`
+	Rating         int    `json:"rating,omitempty"`
+	CreatedAt      int64  `json:"createdAt"`
+	TTL            int64  `json:"ttl"`
+}
+
+func (s Store) CreateFeedItem(ctx context.Context, item FeedItem) error {
+	return s.writeToTable(item, "FeedItems")
+}
+
+//FindFeedItems reads a feed newest first, starting after the sort key of the last item of the
+//previous page when after isn't empty
+func (s Store) FindFeedItems(ctx context.Context, feedID, after string, limit int64) (*[]FeedItem, error) {
+	// create the api params
+	params := &dynamodb.QueryInput{
+		TableName:              aws.String("FeedItems"),
+		KeyConditionExpression: aws.String("feedId = :f"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":f": {S: aws.String(feedID)},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int64(limit),
+	}
+
+	if after != "" {
+		params.ExclusiveStartKey = map[string]*dynamodb.AttributeValue{
+			"feedId": {S: aws.String(feedID)},
+			"sk":     {S: aws.String(after)},
+		}
+	}
+
+	// read the items
+	resp, err := s.db.Query(params)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]FeedItem, 0)
+	err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &items)
+	if err != nil {
+		return nil, err
+	}
+
+	return &items, nil
+}
+
+//FindFeedItemsByAccountID is every item in the account's feeds or about its profiles
+func (s Store) FindFeedItemsByAccountID(ctx context.Context, accountID string) (*[]FeedItem, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("FeedItems"),
+		FilterExpression: aws.String("ownerAccountId = :a OR actorAccountId = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":a": {S: aws.String(accountID)},
+		},
+	}
+
+	return s.scanFeedItems(params)
+}
+
+//FindFeedItemsByProfileID is every item in the profile's feed or about it
+func (s Store) FindFeedItemsByProfileID(ctx context.Context, profileID string) (*[]FeedItem, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("FeedItems"),
+		FilterExpression: aws.String("feedId = :p OR actorId = :p"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":p": {S: aws.String(profileID)},
+		},
+	}
+
+	return s.scanFeedItems(params)
+}
+
+func (s Store) RemoveFeedItem(ctx context.Context, feedID, sk string) error {
+	// create the api params
+	params := &dynamodb.DeleteItemInput{
+		TableName: aws.String("FeedItems"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"feedId": {S: aws.String(feedID)},
+			"sk":     {S: aws.String(sk)},
+		},
+	}
+
+	// delete the item
+	_, err := s.db.DeleteItem(params)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//DynamoDB Helpers
+func (s Store) scanFeedItems(params *dynamodb.ScanInput) (*[]FeedItem, error) {
+	items := make([]FeedItem, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []FeedItem
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &items, nil
+}
@@ -2,6 +2,7 @@ package users
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -12,6 +13,36 @@ func (s Store) CreateUserAccount(ctx context.Context, account UserAccount) error
 	return s.writeToTable(account, "Accounts")
 }
 
+func (s Store) FindUserAccountByID(ctx context.Context, ID string) (*UserAccount, error) {
+	// create the api params
+	params := &dynamodb.GetItemInput{
+		TableName: aws.String("Accounts"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+	}
+
+	// read the item
+	resp, err := s.db.GetItem(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Item) == 0 {
+		return nil, nil
+	}
+
+	var act UserAccount
+	err = dynamodbattribute.UnmarshalMap(resp.Item, &act)
+	if err != nil {
+		return nil, err
+	}
+
+	return &act, nil
+}
+
 func (s Store) FindUserAccountByEmail(ctx context.Context, email string) (*UserAccount, error) {
 	// create the api params
 	params := &dynamodb.ScanInput{
@@ -67,6 +98,96 @@ func (s Store) FindUserAccountByToken(ctx context.Context, token string) (*UserA
 	return nil, nil
 }
 
+func (s Store) FindUserAccountByCalendarToken(ctx context.Context, token string) (*UserAccount, error) {
+	return s.scanAccountsByAttribute("calTkn", token)
+}
+
+//FindUserAccounts pages through accounts, query matches part of the email or the whole ID.
+//It returns the ID to pass as startID for the next page, empty on the last one.
+func (s Store) FindUserAccounts(ctx context.Context, query, startID string, limit int) (*[]UserAccount, string, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName: aws.String("Accounts"),
+	}
+
+	if query != "" {
+		params.FilterExpression = aws.String("contains(email, :q) OR id = :q")
+		params.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+			":q": {S: aws.String(query)},
+		}
+	}
+
+	if startID != "" {
+		params.ExclusiveStartKey = map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String(startID)},
+		}
+	}
+
+	accounts := make([]UserAccount, 0)
+	for {
+		//the scan limit applies before the filter, so ask for what is left of the page
+		params.Limit = aws.Int64(int64(limit - len(accounts)))
+
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, "", err
+		}
+
+		var page []UserAccount
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, "", err
+		}
+		accounts = append(accounts, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			return &accounts, "", nil
+		}
+
+		if len(accounts) >= limit {
+			return &accounts, aws.StringValue(resp.LastEvaluatedKey["id"].S), nil
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+}
+
+//FindUserAccountsDueForDeletion is every account whose deletion grace period ended at or before now
+func (s Store) FindUserAccountsDueForDeletion(ctx context.Context, now int64) (*[]UserAccount, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Accounts"),
+		FilterExpression: aws.String("deleteAfter > :z AND deleteAfter <= :n"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":z": {N: aws.String("0")},
+			":n": {N: aws.String(fmt.Sprintf("%d", now))},
+		},
+	}
+
+	accounts := make([]UserAccount, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []UserAccount
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &accounts, nil
+}
+
 func (s Store) RemoveUserAccountByID(ctx context.Context, ID string) error {
 	return s.deleteFromTableByID(ID, "Accounts")
 }
@@ -102,3 +223,187 @@ func (s Store) UpdateUserAccountToken(ctx context.Context, ID, token string) (*U
 
 	return &updatedAct, nil
 }
+
+func (s Store) UpdateUserAccountCalendarToken(ctx context.Context, ID, token string) (*UserAccount, error) {
+	return s.updateAccountAttribute(ID, "calTkn", token)
+}
+
+func (s Store) UpdateUserAccountPassword(ctx context.Context, ID, password string) (*UserAccount, error) {
+	return s.updateAccountAttribute(ID, "password", password)
+}
+
+func (s Store) UpdateUserAccountRole(ctx context.Context, ID, role string) (*UserAccount, error) {
+	return s.updateAccountAttribute(ID, "role", role)
+}
+
+func (s Store) UpdateUserAccountDisabled(ctx context.Context, ID string, disabled bool) error {
+	// create the api params
+	params := &dynamodb.UpdateItemInput{
+		TableName: aws.String("Accounts"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+		UpdateExpression: aws.String("set disabled = :d"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":d": {BOOL: aws.Bool(disabled)},
+		},
+	}
+
+	// update the item
+	_, err := s.db.UpdateItem(params)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//UpdateUserAccountDeleteAfter schedules the account's deletion, zero cancels it
+func (s Store) UpdateUserAccountDeleteAfter(ctx context.Context, ID string, deleteAfter int64) error {
+	// create the api params
+	params := &dynamodb.UpdateItemInput{
+		TableName: aws.String("Accounts"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+		UpdateExpression: aws.String("remove deleteAfter"),
+	}
+
+	if deleteAfter > 0 {
+		params.UpdateExpression = aws.String("set deleteAfter = :d")
+		params.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+			":d": {N: aws.String(fmt.Sprintf("%d", deleteAfter))},
+		}
+	}
+
+	// update the item
+	_, err := s.db.UpdateItem(params)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s Store) UpdateUserAccountTwoFactor(ctx context.Context, ID string, tf TwoFactor) error {
+	av, err := dynamodbattribute.Marshal(tf)
+	if err != nil {
+		return err
+	}
+
+	// create the api params
+	params := &dynamodb.UpdateItemInput{
+		TableName: aws.String("Accounts"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+		UpdateExpression: aws.String("set twoFactor = :t"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":t": av,
+		},
+	}
+
+	// update the item
+	_, err = s.db.UpdateItem(params)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s Store) UpdateUserAccountVerified(ctx context.Context, ID string) error {
+	// create the api params
+	params := &dynamodb.UpdateItemInput{
+		TableName: aws.String("Accounts"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+		UpdateExpression: aws.String("set verified = :v"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":v": {BOOL: aws.Bool(true)},
+		},
+	}
+
+	// update the item
+	_, err := s.db.UpdateItem(params)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//DynamoDB Helpers
+func (s Store) scanAccountsByAttribute(attr, val string) (*UserAccount, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Accounts"),
+		FilterExpression: aws.String("#a = :v"),
+		ExpressionAttributeNames: map[string]*string{
+			"#a": aws.String(attr),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":v": {S: aws.String(val)},
+		},
+	}
+	// read the item
+	resp, err := s.db.Scan(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if *resp.Count >= int64(1) {
+		var act UserAccount
+		err = dynamodbattribute.UnmarshalMap(resp.Items[0], &act)
+		if err != nil {
+			return nil, err
+		}
+		return &act, nil
+	}
+
+	return nil, nil
+}
+
+func (s Store) updateAccountAttribute(ID, attr, val string) (*UserAccount, error) {
+	// create the api params
+	params := &dynamodb.UpdateItemInput{
+		TableName: aws.String("Accounts"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+		UpdateExpression: aws.String("set #a = :v"),
+		ExpressionAttributeNames: map[string]*string{
+			"#a": aws.String(attr),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":v": {S: aws.String(val)},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueAllNew),
+	}
+
+	// update the item
+	resp, err := s.db.UpdateItem(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// unmarshal the dynamodb attribute values into a custom struct
+	var updatedAct UserAccount
+	err = dynamodbattribute.UnmarshalMap(resp.Attributes, &updatedAct)
+	if err != nil {
+		return nil, err
+	}
+
+	return &updatedAct, nil
+}
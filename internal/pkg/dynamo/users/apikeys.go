@@ -0,0 +1,106 @@
+package users
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+func (s Store) CreateAPIKey(ctx context.Context, key APIKey) error {
+	return s.writeToTable(key, "APIKeys")
+}
+
+func (s Store) FindAPIKeyByID(ctx context.Context, ID string) (*APIKey, error) {
+	// create the api params
+	params := &dynamodb.GetItemInput{
+		TableName: aws.String("APIKeys"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+	}
+
+	// read the item
+	resp, err := s.db.GetItem(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Item) == 0 {
+		return nil, nil
+	}
+
+	var k APIKey
+	err = dynamodbattribute.UnmarshalMap(resp.Item, &k)
+	if err != nil {
+		return nil, err
+	}
+
+	return &k, nil
+}
+
+func (s Store) FindAPIKeysByUserID(ctx context.Context, userID string) (*[]APIKey, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("APIKeys"),
+		FilterExpression: aws.String("userId = :u"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":u": {S: aws.String(userID)},
+		},
+	}
+
+	keys := make([]APIKey, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []APIKey
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &keys, nil
+}
+
+func (s Store) RemoveAPIKeyByID(ctx context.Context, ID string) error {
+	return s.deleteFromTableByID(ID, "APIKeys")
+}
+
+func (s Store) UpdateAPIKeyLastUsed(ctx context.Context, ID string, lastUsedAt int64) error {
+	// create the api params
+	params := &dynamodb.UpdateItemInput{
+		TableName: aws.String("APIKeys"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+		UpdateExpression: aws.String("set lastUsedAt = :l"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":l": {N: aws.String(fmt.Sprintf("%d", lastUsedAt))},
+		},
+	}
+
+	// update the item
+	_, err := s.db.UpdateItem(params)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
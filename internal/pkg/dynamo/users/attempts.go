@@ -0,0 +1,109 @@
+package users
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+func (s Store) FindLoginAttemptsByID(ctx context.Context, ID string) (*LoginAttempts, error) {
+	// create the api params
+	params := &dynamodb.GetItemInput{
+		TableName: aws.String("LoginAttempts"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+	}
+
+	// read the item
+	resp, err := s.db.GetItem(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Item) == 0 {
+		return nil, nil
+	}
+
+	var a LoginAttempts
+	err = dynamodbattribute.UnmarshalMap(resp.Item, &a)
+	if err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+//IncrementLoginFailures counts atomically so concurrent guesses can't slip under the threshold
+func (s Store) IncrementLoginFailures(ctx context.Context, ID string, now, expiresAt int64) (*LoginAttempts, error) {
+	// create the api params
+	params := &dynamodb.UpdateItemInput{
+		TableName: aws.String("LoginAttempts"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+		UpdateExpression: aws.String("add failures :one set lastFailure = :n, #t = :t"),
+		ExpressionAttributeNames: map[string]*string{
+			"#t": aws.String("ttl"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":one": {N: aws.String("1")},
+			":n":   {N: aws.String(fmt.Sprintf("%d", now))},
+			":t":   {N: aws.String(fmt.Sprintf("%d", expiresAt))},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueAllNew),
+	}
+
+	// update the item
+	resp, err := s.db.UpdateItem(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var a LoginAttempts
+	err = dynamodbattribute.UnmarshalMap(resp.Attributes, &a)
+	if err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+func (s Store) UpdateLoginLockout(ctx context.Context, ID string, lockedUntil, expiresAt int64) error {
+	// create the api params
+	params := &dynamodb.UpdateItemInput{
+		TableName: aws.String("LoginAttempts"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+		UpdateExpression: aws.String("set lockedUntil = :l, #t = :t"),
+		ExpressionAttributeNames: map[string]*string{
+			"#t": aws.String("ttl"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":l": {N: aws.String(fmt.Sprintf("%d", lockedUntil))},
+			":t": {N: aws.String(fmt.Sprintf("%d", expiresAt))},
+		},
+	}
+
+	// update the item
+	_, err := s.db.UpdateItem(params)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s Store) RemoveLoginAttemptsByID(ctx context.Context, ID string) error {
+	return s.deleteFromTableByID(ID, "LoginAttempts")
+}
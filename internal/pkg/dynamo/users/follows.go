@@ -0,0 +1,89 @@
+package users
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+func (s Store) CreateFollow(ctx context.Context, follow Follow) error {
+	return s.writeToTable(follow, "Follows")
+}
+
+func (s Store) FindFollowsByUserID(ctx context.Context, userID string) (*[]Follow, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Follows"),
+		FilterExpression: aws.String("userId = :u"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":u": {S: aws.String(userID)},
+		},
+	}
+
+	return s.scanFollows(params)
+}
+
+func (s Store) FindAllFollows(ctx context.Context) (*[]Follow, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName: aws.String("Follows"),
+	}
+
+	return s.scanFollows(params)
+}
+
+func (s Store) RemoveFollowByID(ctx context.Context, ID string) error {
+	return s.deleteFromTableByID(ID, "Follows")
+}
+
+func (s Store) UpdateFollowLastNotified(ctx context.Context, ID, airDate string) error {
+	// create the api params
+	params := &dynamodb.UpdateItemInput{
+		TableName: aws.String("Follows"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+		UpdateExpression: aws.String("set lastNotified = :l"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":l": {S: aws.String(airDate)},
+		},
+	}
+
+	// update the item
+	_, err := s.db.UpdateItem(params)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//DynamoDB Helpers
+func (s Store) scanFollows(params *dynamodb.ScanInput) (*[]Follow, error) {
+	follows := make([]Follow, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Follow
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		follows = append(follows, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &follows, nil
+}
@@ -0,0 +1,81 @@
+package users
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+func (s Store) CreateIdentity(ctx context.Context, identity Identity) error {
+	return s.writeToTable(identity, "Identities")
+}
+
+func (s Store) FindIdentityByID(ctx context.Context, ID string) (*Identity, error) {
+	// create the api params
+	params := &dynamodb.GetItemInput{
+		TableName: aws.String("Identities"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+	}
+
+	// read the item
+	resp, err := s.db.GetItem(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Item) == 0 {
+		return nil, nil
+	}
+
+	var i Identity
+	err = dynamodbattribute.UnmarshalMap(resp.Item, &i)
+	if err != nil {
+		return nil, err
+	}
+
+	return &i, nil
+}
+
+func (s Store) FindIdentitiesByUserID(ctx context.Context, userID string) (*[]Identity, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Identities"),
+		FilterExpression: aws.String("userId = :u"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":u": {S: aws.String(userID)},
+		},
+	}
+
+	identities := make([]Identity, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Identity
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &identities, nil
+}
+
+func (s Store) RemoveIdentityByID(ctx context.Context, ID string) error {
+	return s.deleteFromTableByID(ID, "Identities")
+}
@@ -0,0 +1,135 @@
+package users
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+//CreateList also saves edits, lists are written whole
+func (s Store) CreateList(ctx context.Context, list List) error {
+	return s.writeToTable(list, "Lists")
+}
+
+func (s Store) FindListByID(ctx context.Context, ID string) (*List, error) {
+	// create the api params
+	params := &dynamodb.GetItemInput{
+		TableName: aws.String("Lists"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+	}
+
+	// read the item
+	resp, err := s.db.GetItem(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Item) == 0 {
+		return nil, nil
+	}
+
+	var l List
+	err = dynamodbattribute.UnmarshalMap(resp.Item, &l)
+	if err != nil {
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+func (s Store) FindListBySlug(ctx context.Context, slug string) (*List, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Lists"),
+		FilterExpression: aws.String("slug = :s"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":s": {S: aws.String(slug)},
+		},
+	}
+
+	lists, err := s.scanLists(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*lists) == 0 {
+		return nil, nil
+	}
+
+	return &(*lists)[0], nil
+}
+
+func (s Store) FindListsByProfileID(ctx context.Context, profileID string) (*[]List, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Lists"),
+		FilterExpression: aws.String("profileId = :p"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":p": {S: aws.String(profileID)},
+		},
+	}
+
+	return s.scanLists(params)
+}
+
+func (s Store) FindListsByAccountID(ctx context.Context, accountID string) (*[]List, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Lists"),
+		FilterExpression: aws.String("accountId = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":a": {S: aws.String(accountID)},
+		},
+	}
+
+	return s.scanLists(params)
+}
+
+func (s Store) FindListsByVisibility(ctx context.Context, visibility string) (*[]List, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Lists"),
+		FilterExpression: aws.String("visibility = :v"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":v": {S: aws.String(visibility)},
+		},
+	}
+
+	return s.scanLists(params)
+}
+
+func (s Store) RemoveListByID(ctx context.Context, ID string) error {
+	return s.deleteFromTableByID(ID, "Lists")
+}
+
+//DynamoDB Helpers
+func (s Store) scanLists(params *dynamodb.ScanInput) (*[]List, error) {
+	lists := make([]List, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []List
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &lists, nil
+}
@@ -16,10 +16,81 @@ func (s Store) FindUserProfileByID(ctx context.Context, ID string) (*UserProfile
 	return s.readFromProfilesByType("id", ID)
 }
 
+func (s Store) FindUserProfilesByAccountID(ctx context.Context, accountID string) (*[]UserProfile, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Profiles"),
+		FilterExpression: aws.String("accountId = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":a": {S: aws.String(accountID)},
+		},
+	}
+
+	profiles := make([]UserProfile, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []UserProfile
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &profiles, nil
+}
+
 func (s Store) RemoveUserProfileByID(ctx context.Context, ID string) error {
 	return s.deleteFromTableByID(ID, "Profiles")
 }
 
+func (s Store) UpdateUserProfileNotifications(ctx context.Context, ID string, prefs NotificationPreferences) (*UserProfile, error) {
+	av, err := dynamodbattribute.Marshal(prefs)
+	if err != nil {
+		return nil, err
+	}
+
+	// create the api params
+	params := &dynamodb.UpdateItemInput{
+		TableName: aws.String("Profiles"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+		UpdateExpression: aws.String("set notifications = :n"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":n": av,
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueAllNew),
+	}
+
+	// update the item
+	resp, err := s.db.UpdateItem(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// unmarshal the dynamodb attribute values into a custom struct
+	var prof UserProfile
+	err = dynamodbattribute.UnmarshalMap(resp.Attributes, &prof)
+	if err != nil {
+		return nil, err
+	}
+
+	return &prof, nil
+}
+
 //DynamoDB Helpers
 func (s Store) readFromProfilesByType(typ, val string) (*UserProfile, error) {
 	// create the api params
@@ -0,0 +1,81 @@
+package users
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+func (s Store) CreateProfileSession(ctx context.Context, session ProfileSession) error {
+	return s.writeToTable(session, "ProfileSessions")
+}
+
+func (s Store) FindProfileSessionByID(ctx context.Context, ID string) (*ProfileSession, error) {
+	// create the api params
+	params := &dynamodb.GetItemInput{
+		TableName: aws.String("ProfileSessions"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+	}
+
+	// read the item
+	resp, err := s.db.GetItem(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Item) == 0 {
+		return nil, nil
+	}
+
+	var ps ProfileSession
+	err = dynamodbattribute.UnmarshalMap(resp.Item, &ps)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ps, nil
+}
+
+func (s Store) FindProfileSessionsByAccountID(ctx context.Context, accountID string) (*[]ProfileSession, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("ProfileSessions"),
+		FilterExpression: aws.String("accountId = :a"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":a": {S: aws.String(accountID)},
+		},
+	}
+
+	sessions := make([]ProfileSession, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []ProfileSession
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &sessions, nil
+}
+
+func (s Store) RemoveProfileSessionByID(ctx context.Context, ID string) error {
+	return s.deleteFromTableByID(ID, "ProfileSessions")
+}
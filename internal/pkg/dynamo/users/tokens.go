@@ -0,0 +1,83 @@
+package users
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+func (s Store) CreateToken(ctx context.Context, token Token) error {
+	return s.writeToTable(token, "Tokens")
+}
+
+//ConsumeTokenByID deletes the token and returns what was stored, so two requests can never redeem the same one
+func (s Store) ConsumeTokenByID(ctx context.Context, ID string) (*Token, error) {
+	// create the api params
+	params := &dynamodb.DeleteItemInput{
+		TableName: aws.String("Tokens"),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(ID),
+			},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueAllOld),
+	}
+
+	// delete the item
+	resp, err := s.db.DeleteItem(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Attributes) == 0 {
+		return nil, nil
+	}
+
+	var t Token
+	err = dynamodbattribute.UnmarshalMap(resp.Attributes, &t)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (s Store) FindTokensByUserID(ctx context.Context, userID string) (*[]Token, error) {
+	// create the api params
+	params := &dynamodb.ScanInput{
+		TableName:        aws.String("Tokens"),
+		FilterExpression: aws.String("userId = :u"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":u": {S: aws.String(userID)},
+		},
+	}
+
+	tokens := make([]Token, 0)
+	for {
+		// read the items
+		resp, err := s.db.Scan(params)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []Token
+		err = dynamodbattribute.UnmarshalListOfMaps(resp.Items, &page)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, page...)
+
+		if len(resp.LastEvaluatedKey) == 0 {
+			break
+		}
+		params.ExclusiveStartKey = resp.LastEvaluatedKey
+	}
+
+	return &tokens, nil
+}
+
+func (s Store) RemoveTokenByID(ctx context.Context, ID string) error {
+	return s.deleteFromTableByID(ID, "Tokens")
+}
@@ -11,7 +11,8 @@ type StreamAccounts struct {
 }
 
 type Content struct {
-	ID int64 `json:"id"`
+	ID   int64  `json:"id"`
+	Type string `json:"type,omitempty"`
 }
 
 type Library struct {
@@ -23,14 +24,142 @@ type UserAccount struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 	Token    string `json:"tkn"`
+	Verified bool   `json:"verified"`
+
+	CalendarToken string    `json:"calTkn,omitempty"`
+	TwoFactor     TwoFactor `json:"twoFactor"`
+	Role          string    `json:"role,omitempty"`
+	Disabled      bool      `json:"disabled,omitempty"`
+	//DeleteAfter is when a requested deletion goes through, zero when none is pending
+	DeleteAfter int64 `json:"deleteAfter,omitempty"`
+}
+
+type TwoFactor struct {
+	Enabled       bool     `json:"enabled"`
+	Secret        string   `json:"secret,omitempty"`
+	RecoveryCodes []string `json:"recoveryCodes,omitempty"`
+	LastStep      int64    `json:"lastStep,omitempty"`
 }
 
+type NotificationPreferences struct {
+	Channels      []string `json:"channels"`
+	WebhookURL    string   `json:"webhookUrl,omitempty"`
+	WebhookSecret string   `json:"webhookSecret,omitempty"`
+	Digest        bool     `json:"digest"`
+}
+
+type Watched struct {
+	ID        int64  `json:"id"`
+	Type      string `json:"type,omitempty"`
+	Season    int    `json:"season,omitempty"`
+	Episode   int    `json:"episode,omitempty"`
+	WatchedAt int64  `json:"watchedAt"`
+}
+
+//ParentalControls hold the most restrictive certification a profile may see in Country's rating system
+type ParentalControls struct {
+	Country      string   `json:"country"`
+	MaxMovie     string   `json:"maxMovie,omitempty"`
+	MaxTV        string   `json:"maxTv,omitempty"`
+	BlockUnrated bool     `json:"blockUnrated,omitempty"`
+	Allowed      []string `json:"allowed,omitempty"`
+}
+
+//UserProfile is a viewer on an account. The account owner's profile shares the account ID,
+//the rest of the household point back to it with AccountID.
 type UserProfile struct {
-	ID             string           `json:"id"`
-	Name           string           `json:"name"`
-	Email          string           `json:"email"`
-	StreamAccounts []StreamAccounts `json:"StreamAccounts"`
-	Library        Library          `json:"library"`
+	ID             string                  `json:"id"`
+	AccountID      string                  `json:"accountId,omitempty"`
+	Name           string                  `json:"name"`
+	Email          string                  `json:"email"`
+	Kids           bool                    `json:"kids,omitempty"`
+	PIN            string                  `json:"pin,omitempty"`
+	Parental       *ParentalControls       `json:"parental,omitempty"`
+	Language       string                  `json:"language,omitempty"`
+	Region         string                  `json:"region,omitempty"`
+	StreamAccounts []StreamAccounts        `json:"StreamAccounts"`
+	Library        Library                 `json:"library"`
+	WatchHistory   []Watched               `json:"watchHistory,omitempty"`
+	Notifications  NotificationPreferences `json:"notifications"`
+}
+
+type Follow struct {
+	ID           string `json:"id"`
+	UserID       string `json:"userId"`
+	ShowID       string `json:"showId"`
+	LastNotified string `json:"lastNotified"`
+	CreatedAt    int64  `json:"createdAt"`
+}
+
+type Token struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"userId"`
+	Purpose   string            `json:"purpose"`
+	Data      map[string]string `json:"data,omitempty"`
+	ExpiresAt int64             `json:"ttl"`
+}
+
+type Identity struct {
+	ID        string `json:"id"`
+	UserID    string `json:"userId"`
+	Provider  string `json:"provider"`
+	Issuer    string `json:"issuer"`
+	Subject   string `json:"subject"`
+	Email     string `json:"email,omitempty"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+//ProfileSession is a token scoped to one viewer profile, AccountToken ties it to the account
+//session it was picked from so signing out of the account ends it too
+type ProfileSession struct {
+	ID           string `json:"id"`
+	AccountID    string `json:"accountId"`
+	ProfileID    string `json:"profileId"`
+	AccountToken string `json:"accountTkn"`
+	ExpiresAt    int64  `json:"ttl"`
+}
+
+//APIKey is a personal key for scripts and integrations. Only the hash of the secret is stored,
+//the ID is part of the key so it can be looked up without a scan.
+type APIKey struct {
+	ID         string   `json:"id"`
+	UserID     string   `json:"userId"`
+	ProfileID  string   `json:"profileId"`
+	Name       string   `json:"name"`
+	SecretHash string   `json:"secretHash"`
+	Scopes     []string `json:"scopes"`
+	CreatedAt  int64    `json:"createdAt"`
+	LastUsedAt int64    `json:"lastUsedAt,omitempty"`
+	ExpiresAt  int64    `json:"expiresAt,omitempty"`
+}
+
+//List is a named list a profile keeps alongside its library, Items are in the order the owner set
+type List struct {
+	ID          string     `json:"id"`
+	AccountID   string     `json:"accountId"`
+	ProfileID   string     `json:"profileId"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Visibility  string     `json:"visibility"`
+	Slug        string     `json:"slug"`
+	Items       []ListItem `json:"items"`
+	CreatedAt   int64      `json:"createdAt"`
+	UpdatedAt   int64      `json:"updatedAt"`
+}
+
+type ListItem struct {
+	ID      int64  `json:"id"`
+	Type    string `json:"type"`
+	Note    string `json:"note,omitempty"`
+	AddedAt int64  `json:"addedAt"`
+}
+
+type LoginAttempts struct {
+	ID          string `json:"id"`
+	Failures    int    `json:"failures"`
+	LastFailure int64  `json:"lastFailure"`
+	LockedUntil int64  `json:"lockedUntil"`
+	ExpiresAt   int64  `json:"ttl"`
 }
 
 //DynamoDB Helpers
@@ -0,0 +1,233 @@
+//Package exports writes a profile's library, lists, ratings and watch history as CSV or JSON.
+//The CSV columns are the ones Letterboxd's importer reads, so files can be taken there as well
+//as brought back through the imports package.
+package exports
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+
+	SectionLibrary = "library"
+	SectionLists   = "lists"
+	SectionRatings = "ratings"
+	SectionHistory = "history"
+
+	dateLayout = "2006-01-02"
+	//shows and their episodes, anything else is a film
+	mediaTV = "tv"
+	//rows are flushed to the writer in batches rather than held until the end
+	flushEvery = 500
+)
+
+//Sections is every section in the order a full JSON export has them
+var Sections = []string{SectionLibrary, SectionLists, SectionRatings, SectionHistory}
+
+var ErrUnknownSection = errors.New("section must be library, lists, ratings or history")
+
+//Entry is one title in a section, which of the dates and fields are set depends on the section.
+//TMDBID is the show's for an episode.
+type Entry struct {
+	MediaType string `json:"type"`
+	TMDBID    string `json:"tmdbId"`
+	IMDbID    string `json:"imdbId,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Year      int    `json:"year,omitempty"`
+	Season    int    `json:"season,omitempty"`
+	Episode   int    `json:"episode,omitempty"`
+
+	AddedAt   int64  `json:"addedAt,omitempty"`
+	WatchedAt int64  `json:"watchedAt,omitempty"`
+	Rewatch   bool   `json:"rewatch,omitempty"`
+	RatedAt   int64  `json:"ratedAt,omitempty"`
+	Rating    int    `json:"rating,omitempty"`
+	Review    string `json:"review,omitempty"`
+	List      string `json:"list,omitempty"`
+	Position  int    `json:"position,omitempty"`
+	Note      string `json:"note,omitempty"`
+}
+
+//Section is a named run of entries, JSON exports hold several
+type Section struct {
+	Name    string
+	Entries []Entry
+}
+
+//ValidSection reports whether the section can be exported
+func ValidSection(section string) bool {
+	for _, s := range Sections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+//WriteCSV writes one section with a header row
+func WriteCSV(w io.Writer, s Section) error {
+	header, ok := csvColumns[s.Name]
+	if !ok {
+		return ErrUnknownSection
+	}
+
+	cw := csv.NewWriter(w)
+	err := cw.Write(header)
+	if err != nil {
+		return err
+	}
+
+	for i, e := range s.Entries {
+		err = cw.Write(csvRecord(header, e))
+		if err != nil {
+			return err
+		}
+
+		if (i+1)%flushEvery == 0 {
+			cw.Flush()
+			if err = cw.Error(); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+//WriteJSON writes {"exportedAt":...,"profile":...,"<section>":[...]} an entry at a time
+func WriteJSON(w io.Writer, exportedAt time.Time, profile string, sections []Section) error {
+	head, err := json.Marshal(profile)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, `{"exportedAt":`+strconv.FormatInt(exportedAt.Unix(), 10)+`,"profile":`+string(head))
+	if err != nil {
+		return err
+	}
+
+	for _, s := range sections {
+		_, err = io.WriteString(w, `,"`+s.Name+`":[`)
+		if err != nil {
+			return err
+		}
+
+		for i, e := range s.Entries {
+			js, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+
+			if i > 0 {
+				js = append([]byte(","), js...)
+			}
+
+			_, err = w.Write(js)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = io.WriteString(w, "]")
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w, "}\n")
+	return err
+}
+
+//Helpers
+
+//csvColumns names the columns as Letterboxd's importer expects them, tmdbShowID, Type, Season
+//and Episode are ours and are ignored there
+var csvColumns = map[string][]string{
+	SectionLibrary: {"Title", "Year", "tmdbID", "tmdbShowID", "imdbID", "Type", "Date"},
+	SectionLists:   {"List", "Position", "Title", "Year", "tmdbID", "tmdbShowID", "imdbID", "Type", "Description", "Date"},
+	SectionRatings: {"Title", "Year", "tmdbID", "tmdbShowID", "imdbID", "Type", "Season", "Episode", "Rating", "Rating10", "Review", "Date"},
+	SectionHistory: {"Title", "Year", "tmdbID", "tmdbShowID", "imdbID", "Type", "Season", "Episode", "WatchedDate", "Rewatch"},
+}
+
+func csvRecord(header []string, e Entry) []string {
+	rec := make([]string, len(header))
+	for i, h := range header {
+		switch h {
+		case "Title":
+			rec[i] = e.Title
+		case "Year":
+			rec[i] = formatInt(e.Year)
+		case "tmdbID":
+			//Letterboxd takes any tmdbID for a film's, a show's would import as whichever film has its number
+			if e.MediaType != mediaTV {
+				rec[i] = e.TMDBID
+			}
+		case "tmdbShowID":
+			if e.MediaType == mediaTV {
+				rec[i] = e.TMDBID
+			}
+		case "imdbID":
+			rec[i] = e.IMDbID
+		case "Type":
+			rec[i] = e.MediaType
+		case "Season":
+			if e.Episode != 0 {
+				rec[i] = strconv.Itoa(e.Season)
+			}
+		case "Episode":
+			rec[i] = formatInt(e.Episode)
+		case "List":
+			rec[i] = e.List
+		case "Position":
+			rec[i] = formatInt(e.Position)
+		case "Description":
+			rec[i] = e.Note
+		case "Rating":
+			//Letterboxd rates in half stars out of five
+			if e.Rating != 0 {
+				rec[i] = strconv.FormatFloat(float64(e.Rating)/2, 'f', -1, 64)
+			}
+		case "Rating10":
+			rec[i] = formatInt(e.Rating)
+		case "Review":
+			rec[i] = e.Review
+		case "WatchedDate":
+			rec[i] = formatDate(e.WatchedAt)
+		case "Rewatch":
+			if e.Rewatch {
+				rec[i] = "Yes"
+			}
+		case "Date":
+			//when it was added to the library or a list, or rated
+			date := e.AddedAt
+			if date == 0 {
+				date = e.RatedAt
+			}
+			rec[i] = formatDate(date)
+		}
+	}
+
+	return rec
+}
+
+func formatInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func formatDate(unix int64) string {
+	if unix == 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).UTC().Format(dateLayout)
+}
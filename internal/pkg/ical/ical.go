@@ -0,0 +1,134 @@
+package ical
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405Z"
+	maxLineOctets  = 75
+)
+
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Stamp       time.Time
+	Sequence    int
+	//Status is CONFIRMED, TENTATIVE or CANCELLED, left out when empty
+	Status string
+}
+
+//Encode renders the calendar as an RFC 5545 document with CRLF line endings
+func (c Calendar) Encode() string {
+	var b strings.Builder
+
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+c.ProdID)
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(&b, "X-WR-CALNAME:"+escape(c.Name))
+	}
+
+	for _, e := range c.Events {
+		e.encode(&b)
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+
+	return b.String()
+}
+
+func (e Event) encode(b *strings.Builder) {
+	stamp := e.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+
+	writeLine(b, "BEGIN:VEVENT")
+	writeLine(b, "UID:"+e.UID)
+	writeLine(b, "DTSTAMP:"+stamp.UTC().Format(dateTimeLayout))
+
+	if e.AllDay {
+		end := e.End
+		if end.IsZero() || !end.After(e.Start) {
+			end = e.Start.AddDate(0, 0, 1)
+		}
+		writeLine(b, "DTSTART;VALUE=DATE:"+e.Start.Format(dateLayout))
+		writeLine(b, "DTEND;VALUE=DATE:"+end.Format(dateLayout))
+	} else {
+		writeLine(b, "DTSTART:"+e.Start.UTC().Format(dateTimeLayout))
+		if !e.End.IsZero() {
+			writeLine(b, "DTEND:"+e.End.UTC().Format(dateTimeLayout))
+		}
+	}
+
+	if e.Sequence > 0 {
+		writeLine(b, fmt.Sprintf("SEQUENCE:%d", e.Sequence))
+	}
+	if e.Status != "" {
+		writeLine(b, "STATUS:"+e.Status)
+	}
+	writeLine(b, "SUMMARY:"+escape(e.Summary))
+	if e.Description != "" {
+		writeLine(b, "DESCRIPTION:"+escape(e.Description))
+	}
+	if e.Location != "" {
+		writeLine(b, "LOCATION:"+escape(e.Location))
+	}
+	if e.URL != "" {
+		writeLine(b, "URL:"+e.URL)
+	}
+	writeLine(b, "END:VEVENT")
+}
+
+//Helpers
+
+//writeLine folds content lines longer than 75 octets without splitting a UTF-8 sequence
+func writeLine(b *strings.Builder, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		//continuation lines lose one octet to the leading space
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
+
+func escape(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return r.Replace(s)
+}
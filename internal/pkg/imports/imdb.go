@@ -0,0 +1,66 @@
+package imports
+
+import (
+	"strings"
+)
+
+//parseIMDb reads the ratings.csv or a list export such as the watchlist from IMDb. Newer exports
+//capitalise the title types, eg TV Series instead of tvSeries.
+func parseIMDb(data []byte) ([]Row, error) {
+	t, err := readCSV(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !t.has("const", "title") {
+		return nil, ErrUnknownFormat
+	}
+
+	//a rated list export also has a your rating column, only ratings.csv has date rated
+	ratings := t.has("your rating", "date rated")
+
+	rows := make([]Row, 0, len(t.records))
+	for i, rec := range t.records {
+		row := Row{
+			Line:      i + 2,
+			MediaType: imdbMediaType(t.get(rec, "title type")),
+			Title:     t.get(rec, "title"),
+			Year:      t.getInt(rec, "year"),
+			IMDbID:    t.get(rec, "const"),
+		}
+
+		if ratings {
+			row.Rating = t.getInt(rec, "your rating")
+			row.RatedAt = parseDate(t.get(rec, "date rated"))
+			if row.Rating < 1 || row.Rating > 10 {
+				row.Skip = "rating is missing"
+			}
+		} else {
+			row.Library = true
+			if row.MediaType == MediaEpisode {
+				row.Skip = "episodes can't be added to the library"
+			}
+		}
+
+		if !strings.HasPrefix(row.IMDbID, "tt") {
+			row.Skip = "const is not an IMDb title ID"
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func imdbMediaType(titleType string) string {
+	titleType = strings.ToLower(strings.ReplaceAll(titleType, " ", ""))
+	switch {
+	case strings.Contains(titleType, "episode"):
+		return MediaEpisode
+	case strings.Contains(titleType, "series"):
+		return MediaTV
+	}
+
+	//films, shorts, tv movies and videos are all movies on TMDB
+	return MediaMovie
+}
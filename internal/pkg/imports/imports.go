@@ -0,0 +1,173 @@
+//Package imports reads the library, history and rating exports of other services into Rows.
+//Rows only say what the file said, matching them to TMDB titles is left to the caller.
+package imports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SourceLetterboxd = "letterboxd"
+	SourceIMDb       = "imdb"
+	SourceTrakt      = "trakt"
+
+	MediaMovie   = "movie"
+	MediaTV      = "tv"
+	MediaEpisode = "episode"
+
+	//a watch history much longer than this no longer fits in a profile item
+	MaxRows = 5000
+
+	dateLayout = "2006-01-02"
+)
+
+//Sources is every export Parse can read
+var Sources = []string{SourceLetterboxd, SourceIMDb, SourceTrakt}
+
+var (
+	ErrUnknownSource = errors.New("source must be letterboxd, imdb or trakt")
+	ErrUnknownFormat = errors.New("file is not a known export of the source")
+	ErrEmptyFile     = errors.New("file has no rows")
+	ErrTooManyRows   = errors.New("file has too many rows")
+)
+
+//Row is one line of an export. IMDbID and TMDBID are set when the file carries them, Title and
+//Year are the fallback. For episodes TMDBID is the show's and Season and Episode pick it out.
+//Library, WatchedAt and Rating say where the row goes, a row can go to several places.
+type Row struct {
+	Line      int
+	MediaType string
+	Title     string
+	Year      int
+	IMDbID    string
+	TMDBID    int64
+	Season    int
+	Episode   int
+
+	Library bool
+	//WatchedAt is zero when the row isn't a watch
+	WatchedAt int64
+	//Rating is on a 1 to 10 scale, zero when the row isn't rated
+	Rating  int
+	RatedAt int64
+	//Skip says why a row that was read can't be imported, eg a rating for a whole season
+	Skip string
+}
+
+//Parse reads an export of source. file names the export when the columns alone can't tell,
+//Letterboxd's watched.csv and watchlist.csv look the same so watchlist has to be passed for one.
+func Parse(source, file string, data []byte) ([]Row, error) {
+	//exports saved by Excel start with a byte order mark
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	var rows []Row
+	var err error
+	switch source {
+	case SourceLetterboxd:
+		rows, err = parseLetterboxd(file, data)
+	case SourceIMDb:
+		rows, err = parseIMDb(data)
+	case SourceTrakt:
+		rows, err = parseTrakt(data)
+	default:
+		return nil, ErrUnknownSource
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	if len(rows) > MaxRows {
+		return nil, ErrTooManyRows
+	}
+
+	return rows, nil
+}
+
+//Helpers
+
+//csvTable is a CSV file with its columns looked up by header name, headers differ in order,
+//case and spacing between export versions so "Watched Date" and "WatchedDate" are one column
+type csvTable struct {
+	columns map[string]int
+	records [][]string
+}
+
+func readCSV(data []byte) (*csvTable, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, ErrEmptyFile
+	}
+	if err != nil {
+		return nil, ErrUnknownFormat
+	}
+
+	t := csvTable{columns: make(map[string]int)}
+	for i, h := range header {
+		t.columns[columnName(h)] = i
+	}
+
+	t.records, err = r.ReadAll()
+	if err != nil {
+		return nil, ErrUnknownFormat
+	}
+
+	return &t, nil
+}
+
+func (t csvTable) has(columns ...string) bool {
+	for _, c := range columns {
+		if _, ok := t.columns[columnName(c)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (t csvTable) get(record []string, column string) string {
+	i, ok := t.columns[columnName(column)]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+func (t csvTable) getInt(record []string, column string) int {
+	n, _ := strconv.Atoi(t.get(record, column))
+	return n
+}
+
+func columnName(header string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(header), " ", ""))
+}
+
+//parseDate reads the yyyy-mm-dd dates the CSV exports use, zero when missing
+func parseDate(s string) int64 {
+	d, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return 0
+	}
+	return d.Unix()
+}
+
+//parseTime reads Trakt's RFC 3339 timestamps, zero when missing
+func parseTime(s string) int64 {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,103 @@
+package imports
+
+import (
+	"math"
+	"strconv"
+)
+
+const LetterboxdWatchlist = "watchlist"
+
+//parseLetterboxd reads one CSV from a Letterboxd export. diary.csv and reviews.csv are watches that
+//may be rated, ratings.csv is just ratings, and watched.csv and watchlist.csv only list films.
+//Files in Letterboxd's import format, which is what the exports package writes, are read too, and
+//our library and lists files go back into the library.
+func parseLetterboxd(file string, data []byte) ([]Row, error) {
+	t, err := readCSV(data)
+	if err != nil {
+		return nil, err
+	}
+
+	//exports name the film, the import format titles it
+	title := "name"
+	if !t.has(title) {
+		title = "title"
+	}
+
+	if !t.has(title) {
+		return nil, ErrUnknownFormat
+	}
+
+	diary := t.has("watched date")
+	rated := t.has("rating") || t.has("rating10")
+	//our own library and lists files, Letterboxd's exports never have a type column
+	library := t.has("list") || t.has("type")
+
+	rows := make([]Row, 0, len(t.records))
+	for i, rec := range t.records {
+		row := Row{
+			//the header is line 1
+			Line:      i + 2,
+			MediaType: MediaMovie,
+			Title:     t.get(rec, title),
+			Year:      t.getInt(rec, "year"),
+			IMDbID:    t.get(rec, "imdbid"),
+		}
+		row.TMDBID, _ = strconv.ParseInt(t.get(rec, "tmdbid"), 10, 64)
+
+		//only our own exports have shows in them, with the show's ID apart from the films'
+		if t.get(rec, "type") == MediaTV {
+			row.MediaType = MediaTV
+			if t.has("tmdbshowid") {
+				row.TMDBID, _ = strconv.ParseInt(t.get(rec, "tmdbshowid"), 10, 64)
+			}
+			row.Episode = t.getInt(rec, "episode")
+			if row.Episode != 0 {
+				row.MediaType = MediaEpisode
+				row.Season = t.getInt(rec, "season")
+			}
+		}
+
+		switch {
+		case diary:
+			row.WatchedAt = parseDate(t.get(rec, "watched date"))
+			if row.WatchedAt == 0 {
+				row.WatchedAt = parseDate(t.get(rec, "date"))
+			}
+			row.Rating = letterboxdRating(t, rec)
+			row.RatedAt = row.WatchedAt
+		case rated:
+			row.Rating = letterboxdRating(t, rec)
+			row.RatedAt = parseDate(t.get(rec, "date"))
+			if row.Rating == 0 {
+				row.Skip = "rating is missing"
+			}
+		case file == LetterboxdWatchlist || library:
+			row.Library = true
+		default:
+			row.WatchedAt = parseDate(t.get(rec, "date"))
+		}
+
+		if row.Title == "" && row.TMDBID == 0 && row.IMDbID == "" {
+			row.Skip = "name is missing"
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+//letterboxdRating turns half stars out of five into our 1 to 10, zero when unrated. Rating10
+//is already on our scale.
+func letterboxdRating(t *csvTable, rec []string) int {
+	if r := t.getInt(rec, "rating10"); r >= 1 && r <= 10 {
+		return r
+	}
+
+	stars, err := strconv.ParseFloat(t.get(rec, "rating"), 64)
+	if err != nil || stars <= 0 {
+		return 0
+	}
+
+	return int(math.Min(10, math.Round(stars*2)))
+}
@@ -0,0 +1,132 @@
+package imports
+
+import (
+	"encoding/json"
+)
+
+type traktIDs struct {
+	IMDb string `json:"imdb"`
+	TMDB int64  `json:"tmdb"`
+}
+
+type traktTitle struct {
+	Title string   `json:"title"`
+	Year  int      `json:"year"`
+	IDs   traktIDs `json:"ids"`
+}
+
+type traktEpisode struct {
+	Season int      `json:"season"`
+	Number int      `json:"number"`
+	Title  string   `json:"title"`
+	IDs    traktIDs `json:"ids"`
+}
+
+//traktEntry covers history, ratings, watchlist and watched entries, which one it is shows in
+//the timestamp it carries
+type traktEntry struct {
+	Type    string        `json:"type"`
+	Movie   *traktTitle   `json:"movie"`
+	Show    *traktTitle   `json:"show"`
+	Episode *traktEpisode `json:"episode"`
+
+	WatchedAt     string `json:"watched_at"`
+	RatedAt       string `json:"rated_at"`
+	Rating        int    `json:"rating"`
+	ListedAt      string `json:"listed_at"`
+	LastWatchedAt string `json:"last_watched_at"`
+
+	//watched-shows.json nests the episodes watched under the show
+	Seasons []struct {
+		Number   int `json:"number"`
+		Episodes []struct {
+			Number        int    `json:"number"`
+			LastWatchedAt string `json:"last_watched_at"`
+		} `json:"episodes"`
+	} `json:"seasons"`
+}
+
+//parseTrakt reads a JSON file from a Trakt export, history, ratings, watchlist and the watched
+//movies and shows files all work. Line is the entry's position in the array.
+func parseTrakt(data []byte) ([]Row, error) {
+	var entries []traktEntry
+	err := json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, ErrUnknownFormat
+	}
+
+	rows := make([]Row, 0, len(entries))
+	for i, e := range entries {
+		row := Row{Line: i + 1}
+
+		switch {
+		case e.Episode != nil && e.Show != nil:
+			//episodes are matched through their show's IDs
+			row.setTitle(e.Show)
+			row.MediaType = MediaEpisode
+			row.Season = e.Episode.Season
+			row.Episode = e.Episode.Number
+		case e.Movie != nil:
+			row.setTitle(e.Movie)
+			row.MediaType = MediaMovie
+		case e.Show != nil:
+			row.setTitle(e.Show)
+			row.MediaType = MediaTV
+		default:
+			row.Skip = "entry has no movie or show"
+			rows = append(rows, row)
+			continue
+		}
+
+		switch {
+		case e.WatchedAt != "":
+			row.WatchedAt = parseTime(e.WatchedAt)
+		case e.RatedAt != "":
+			row.Rating = e.Rating
+			row.RatedAt = parseTime(e.RatedAt)
+			if e.Type == "season" {
+				row.Skip = "seasons can't be rated"
+			}
+		case e.ListedAt != "":
+			//a season or episode on the watchlist adds its show
+			row.Library = true
+			row.MediaType = traktLibraryType(row.MediaType)
+			row.Season, row.Episode = 0, 0
+		case len(e.Seasons) > 0:
+			//one row per episode so each lands in the history
+			for _, s := range e.Seasons {
+				for _, ep := range s.Episodes {
+					epRow := row
+					epRow.MediaType = MediaEpisode
+					epRow.Season = s.Number
+					epRow.Episode = ep.Number
+					epRow.WatchedAt = parseTime(ep.LastWatchedAt)
+					rows = append(rows, epRow)
+				}
+			}
+			continue
+		case e.LastWatchedAt != "":
+			row.WatchedAt = parseTime(e.LastWatchedAt)
+		default:
+			row.Skip = "entry is not a watch, rating or watchlist item"
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func (r *Row) setTitle(t *traktTitle) {
+	r.Title = t.Title
+	r.Year = t.Year
+	r.IMDbID = t.IDs.IMDb
+	r.TMDBID = t.IDs.TMDB
+}
+
+func traktLibraryType(mediaType string) string {
+	if mediaType == MediaEpisode {
+		return MediaTV
+	}
+	return mediaType
+}
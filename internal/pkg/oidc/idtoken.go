@@ -0,0 +1,190 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+//allowed clock drift between us and the provider
+const leeway = time.Minute
+
+type Claims struct {
+	Issuer        string   `json:"iss"`
+	Subject       string   `json:"sub"`
+	Audience      Audience `json:"aud"`
+	AuthorizedBy  string   `json:"azp,omitempty"`
+	Expiry        int64    `json:"exp"`
+	IssuedAt      int64    `json:"iat"`
+	Nonce         string   `json:"nonce,omitempty"`
+	Email         string   `json:"email,omitempty"`
+	EmailVerified Bool     `json:"email_verified,omitempty"`
+	Name          string   `json:"name,omitempty"`
+}
+
+//Audience is a single string or a list in the token
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		*a = Audience{s}
+		return nil
+	}
+
+	var l []string
+	err := json.Unmarshal(b, &l)
+	if err != nil {
+		return err
+	}
+	*a = l
+	return nil
+}
+
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a Audience) Contains(s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+//Bool accepts "true" as well as true, some providers send email_verified as a string
+type Bool bool
+
+func (b *Bool) UnmarshalJSON(v []byte) error {
+	switch strings.Trim(string(v), `"`) {
+	case "true":
+		*b = true
+	case "false", "null", "":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean %s", v)
+	}
+	return nil
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+//VerifyIDToken checks the signature against the provider's JWKS, then the issuer, audience, expiry and nonce
+func (p Provider) VerifyIDToken(ctx context.Context, raw, nonce string, now time.Time) (*Claims, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidIDToken
+	}
+
+	var h header
+	err := decodeSegment(parts[0], &h)
+	if err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	//only asymmetric algorithms, "none" and HS256 would let anyone who knows the client id mint tokens
+	if h.Alg != "RS256" && h.Alg != "ES256" {
+		return nil, fmt.Errorf("%w: unsupported alg %q", ErrInvalidIDToken, h.Alg)
+	}
+
+	key, err := p.findKey(ctx, h.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	pub, err := key.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	err = verifySignature(h.Alg, pub, parts[0]+"."+parts[1], sig)
+	if err != nil {
+		return nil, err
+	}
+
+	var c Claims
+	err = decodeSegment(parts[1], &c)
+	if err != nil {
+		return nil, ErrInvalidIDToken
+	}
+
+	switch {
+	case c.Issuer != p.Config.Issuer:
+		return nil, fmt.Errorf("%w: issuer %q", ErrInvalidIDToken, c.Issuer)
+	case !c.Audience.Contains(p.Config.ClientID):
+		return nil, fmt.Errorf("%w: audience %v", ErrInvalidIDToken, c.Audience)
+	case len(c.Audience) > 1 && c.AuthorizedBy != p.Config.ClientID:
+		return nil, fmt.Errorf("%w: authorized party %q", ErrInvalidIDToken, c.AuthorizedBy)
+	case c.Subject == "":
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	case now.Add(-leeway).Unix() >= c.Expiry:
+		return nil, fmt.Errorf("%w: expired", ErrInvalidIDToken)
+	case c.IssuedAt > now.Add(leeway).Unix():
+		return nil, fmt.Errorf("%w: issued in the future", ErrInvalidIDToken)
+	case c.Nonce != nonce:
+		return nil, ErrNonceMismatch
+	}
+
+	return &c, nil
+}
+
+//Helpers
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+func verifySignature(alg string, pub crypto.PublicKey, signed string, sig []byte) error {
+	sum := sha256.Sum256([]byte(signed))
+
+	switch alg {
+	case "RS256":
+		k, ok := pub.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: key does not match alg", ErrInvalidIDToken)
+		}
+		err := rsa.VerifyPKCS1v15(k, crypto.SHA256, sum[:], sig)
+		if err != nil {
+			return fmt.Errorf("%w: bad signature", ErrInvalidIDToken)
+		}
+	case "ES256":
+		k, ok := pub.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return fmt.Errorf("%w: key does not match alg", ErrInvalidIDToken)
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(k, sum[:], r, s) {
+			return fmt.Errorf("%w: bad signature", ErrInvalidIDToken)
+		}
+	}
+
+	return nil
+}
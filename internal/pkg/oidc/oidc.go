@@ -0,0 +1,208 @@
+//Package oidc signs users in with an external OpenID Connect provider using the authorization
+//code flow with PKCE. Providers are found through their discovery document and ID tokens are
+//checked against the provider's JWKS, so nothing here is specific to one provider.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidIDToken = errors.New("id token is invalid")
+	ErrNonceMismatch  = errors.New("id token nonce does not match")
+)
+
+//Config is one provider as listed in the OIDC_PROVIDERS env
+type Config struct {
+	Name         string   `json:"name"`
+	Issuer       string   `json:"issuer"`
+	ClientID     string   `json:"clientId"`
+	ClientSecret string   `json:"clientSecret,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+}
+
+type Configs []Config
+
+//Decode lets envconfig read the providers from a JSON array
+func (c *Configs) Decode(value string) error {
+	if strings.TrimSpace(value) == "" {
+		*c = nil
+		return nil
+	}
+
+	return json.Unmarshal([]byte(value), c)
+}
+
+func (c Configs) Find(name string) (Config, bool) {
+	for _, p := range c {
+		if p.Name == name {
+			return p, true
+		}
+	}
+
+	return Config{}, false
+}
+
+type Discovery struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	UserinfoEndpoint      string   `json:"userinfo_endpoint,omitempty"`
+	JWKSURI               string   `json:"jwks_uri"`
+	SigningAlgs           []string `json:"id_token_signing_alg_values_supported,omitempty"`
+	ChallengeMethods      []string `json:"code_challenge_methods_supported,omitempty"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	IDToken      string `json:"id_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+	Error        string `json:"error,omitempty"`
+	Description  string `json:"error_description,omitempty"`
+}
+
+type Provider struct {
+	Config      Config
+	RedirectURL string
+	Discovery   Discovery
+	client      *http.Client
+}
+
+//NewProvider reads the issuer's discovery document
+func NewProvider(ctx context.Context, c Config, redirectURL string) (*Provider, error) {
+	p := &Provider{
+		Config:      c,
+		RedirectURL: redirectURL,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+
+	u := strings.TrimSuffix(c.Issuer, "/") + "/.well-known/openid-configuration"
+	err := p.getJSON(ctx, u, &p.Discovery)
+	if err != nil {
+		return nil, err
+	}
+
+	//the spec requires an exact match, otherwise tokens from one issuer could be passed off as another's
+	if p.Discovery.Issuer != c.Issuer {
+		return nil, fmt.Errorf("discovery issuer %q does not match %q", p.Discovery.Issuer, c.Issuer)
+	}
+
+	if p.Discovery.AuthorizationEndpoint == "" || p.Discovery.TokenEndpoint == "" || p.Discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery document for %q is missing endpoints", c.Issuer)
+	}
+
+	return p, nil
+}
+
+func (p Provider) Name() string {
+	return p.Config.Name
+}
+
+//AuthCodeURL is where the user is sent to sign in, the verifier stays with us and only its S256 challenge is sent
+func (p Provider) AuthCodeURL(state, nonce, verifier string) string {
+	scopes := p.Config.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+
+	v := url.Values{}
+	v.Set("response_type", "code")
+	v.Set("client_id", p.Config.ClientID)
+	v.Set("redirect_uri", p.RedirectURL)
+	v.Set("scope", strings.Join(scopes, " "))
+	v.Set("state", state)
+	v.Set("nonce", nonce)
+	v.Set("code_challenge", CodeChallenge(verifier))
+	v.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(p.Discovery.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return p.Discovery.AuthorizationEndpoint + sep + v.Encode()
+}
+
+//Exchange trades the code from the redirect for tokens
+func (p Provider) Exchange(ctx context.Context, code, verifier string) (*TokenResponse, error) {
+	v := url.Values{}
+	v.Set("grant_type", "authorization_code")
+	v.Set("code", code)
+	v.Set("redirect_uri", p.RedirectURL)
+	v.Set("code_verifier", verifier)
+	v.Set("client_id", p.Config.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.Discovery.TokenEndpoint, strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.Config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.Config.ClientID), url.QueryEscape(p.Config.ClientSecret))
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var t TokenResponse
+	err = json.Unmarshal(body, &t)
+	if err != nil {
+		return nil, fmt.Errorf("token endpoint returned %d: %v", res.StatusCode, err)
+	}
+
+	if res.StatusCode != http.StatusOK || t.Error != "" {
+		return nil, fmt.Errorf("token endpoint returned %d: %s %s", res.StatusCode, t.Error, t.Description)
+	}
+
+	if t.IDToken == "" {
+		return nil, fmt.Errorf("token endpoint did not return an id token")
+	}
+
+	return &t, nil
+}
+
+//Helpers
+
+func (p Provider) getJSON(ctx context.Context, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %d", u, res.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
@@ -0,0 +1,33 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+//NewVerifier returns a PKCE code verifier, 43 characters from 32 random bytes
+func NewVerifier() (string, error) {
+	return randomString(32)
+}
+
+//NewNonce returns a value to bind an ID token to the login that asked for it
+func NewNonce() (string, error) {
+	return randomString(24)
+}
+
+//CodeChallenge is the S256 challenge for a verifier
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,69 @@
+package downloads
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+//Store keeps files too big for an API Gateway response, the bucket's lifecycle rule removes them
+type Store struct {
+	s3       *s3.S3
+	uploader *s3manager.Uploader
+	bucket   string
+}
+
+func NewStore(region, bucket string) (*Store, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(region),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{
+		s3:       s3.New(sess),
+		uploader: s3manager.NewUploader(sess),
+		bucket:   bucket,
+	}, nil
+}
+
+//File is what is uploaded, Encoding is empty unless the body is compressed
+type File struct {
+	Key         string
+	Name        string
+	ContentType string
+	Encoding    string
+}
+
+//Upload reads body until it ends, sending it in parts as it goes so the whole file is never in memory
+func (s Store) Upload(ctx context.Context, f File, body io.Reader) error {
+	input := &s3manager.UploadInput{
+		Bucket:             aws.String(s.bucket),
+		Key:                aws.String(f.Key),
+		Body:               body,
+		ContentType:        aws.String(f.ContentType),
+		ContentDisposition: aws.String("attachment; filename=\"" + f.Name + "\""),
+	}
+	if f.Encoding != "" {
+		input.ContentEncoding = aws.String(f.Encoding)
+	}
+
+	_, err := s.uploader.UploadWithContext(ctx, input)
+	return err
+}
+
+//DownloadURL is a link anyone can download the file from until it expires
+func (s Store) DownloadURL(key string, expires time.Duration) (string, error) {
+	req, _ := s.s3.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+
+	return req.Presign(expires)
+}
@@ -0,0 +1,89 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrNotHTTPS       = errors.New("webhook url must use https")
+	ErrUnknownHost    = errors.New("webhook host could not be resolved")
+	ErrPrivateAddress = errors.New("webhook host must be a public address")
+)
+
+//sharedAddressSpace is carrier grade NAT, it isn't reachable from the internet either
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+//CheckURL is for when a webhook is saved, it resolves the host so names pointing at internal
+//addresses are turned away too. The client from NewClient checks again when it connects,
+//because the name can resolve somewhere else by then.
+func CheckURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return ErrNotHTTPS
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return ErrUnknownHost
+	}
+
+	for _, a := range addrs {
+		if !Public(a.IP) {
+			return ErrPrivateAddress
+		}
+	}
+
+	return nil
+}
+
+//NewClient only connects to public addresses and never through a proxy, so the address checked is
+//the one the request goes to
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if !Public(net.ParseIP(host)) {
+				return ErrPrivateAddress
+			}
+
+			return nil
+		},
+	}
+
+	//redirects are dialed the same way, so they can't reach an internal address either
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+		},
+	}
+}
+
+//Public is false for loopback, private, link local, multicast and unspecified addresses
+func Public(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
@@ -0,0 +1,142 @@
+package audit
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/audit"
+	"github.com/segmentio/ksuid"
+)
+
+const (
+	Signup          = "account.signup"
+	LoginSucceeded  = "login.success"
+	LoginFailed     = "login.failure"
+	Logout          = "logout"
+	TokenRefreshed  = "token.refresh"
+	ProfileChanged  = "profile.update"
+	PasswordChanged = "password.change"
+	TwoFactorOn     = "twofactor.enable"
+	TwoFactorOff    = "twofactor.disable"
+
+	DeletionRequested = "account.delete_request"
+	DeletionCancelled = "account.delete_cancel"
+	AccountDeleted    = "account.delete"
+	DataExported      = "account.export"
+
+	APIKeyCreated = "apikey.create"
+	APIKeyRevoked = "apikey.revoke"
+)
+
+const (
+	AdminDisableUser = "admin.disable_user"
+	AdminEnableUser  = "admin.enable_user"
+	AdminLogoutUser  = "admin.logout_user"
+	AdminChangeRole  = "admin.change_role"
+	AdminViewUser    = "admin.view_user"
+	AdminSearchUsers = "admin.search_users"
+)
+
+//MaxActivity caps how far back the recent activity page goes
+const MaxActivity = 200
+
+//Activity is an event as the account owner sees it, staff stay anonymous
+type Activity struct {
+	Action    string            `json:"action"`
+	SourceIP  string            `json:"sourceIp,omitempty"`
+	UserAgent string            `json:"userAgent,omitempty"`
+	ByStaff   bool              `json:"byStaff,omitempty"`
+	Data      map[string]string `json:"data,omitempty"`
+	CreatedAt int64             `json:"createdAt"`
+}
+
+type Audit struct {
+	s store
+}
+
+func NewAuditService(conn, region string) (*Audit, error) {
+	var s store
+	s, err := audit.NewStore(conn, region)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Audit{
+		s: s,
+	}, nil
+}
+
+//Record stamps the event with a time ordered ID, the caller fills in who, what and from where
+func (a Audit) Record(ctx context.Context, e audit.Event) error {
+	now := time.Now()
+	ID, err := ksuid.NewRandomWithTime(now)
+	if err != nil {
+		return err
+	}
+
+	e.ID = ID.String()
+	e.CreatedAt = now.Unix()
+
+	return a.s.CreateEvent(ctx, e)
+}
+
+//FindEventsByActorID is what an admin did, newest first
+func (a Audit) FindEventsByActorID(ctx context.Context, actorID string) (*[]audit.Event, error) {
+	events, err := a.s.FindEventsByActorID(ctx, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	newestFirst(*events)
+	return events, nil
+}
+
+//FindRecentActivity is the newest events about an account, including what staff did to it
+func (a Audit) FindRecentActivity(ctx context.Context, userID string, limit int) (*[]Activity, error) {
+	activity, err := a.FindActivity(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit < 1 || limit > MaxActivity {
+		limit = MaxActivity
+	}
+	if len(*activity) > limit {
+		*activity = (*activity)[:limit]
+	}
+
+	return activity, nil
+}
+
+//FindActivity is every event about an account newest first, for data exports
+func (a Audit) FindActivity(ctx context.Context, userID string) (*[]Activity, error) {
+	events, err := a.s.FindEventsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	newestFirst(*events)
+	activity := make([]Activity, 0, len(*events))
+	for _, e := range *events {
+		activity = append(activity, Activity{
+			Action:    e.Action,
+			SourceIP:  e.SourceIP,
+			UserAgent: e.UserAgent,
+			ByStaff:   e.ActorID != "" && e.ActorID != e.UserID,
+			Data:      e.Data,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+
+	return &activity, nil
+}
+
+//Helpers
+
+//ksuids sort by time, so the ID is enough to order events
+func newestFirst(events []audit.Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].ID > events[j].ID
+	})
+}
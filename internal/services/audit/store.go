@@ -0,0 +1,13 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/audit"
+)
+
+type store interface {
+	CreateEvent(ctx context.Context, event audit.Event) error
+	FindEventsByActorID(ctx context.Context, actorID string) (*[]audit.Event, error)
+	FindEventsByUserID(ctx context.Context, userID string) (*[]audit.Event, error)
+}
@@ -0,0 +1,72 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/ical"
+)
+
+const calendarDomain = "finalproject"
+
+//BuildCalendar collects air dates for the shows and release dates for the movies between from and to.
+//UIDs only depend on the title and episode so calendar clients update entries instead of duplicating them.
+//A show or movie TMDB can't find is left out and returned in missing, keyed by TitleKey, rather than
+//failing the whole feed.
+func (c Content) BuildCalendar(ctx context.Context, name string, showIDs, movieIDs []string, from, to time.Time) (*ical.Calendar, map[string]error) {
+	cal := &ical.Calendar{
+		ProdID: "-//finalProject//Upcoming Episodes//EN",
+		Name:   name,
+		Events: make([]ical.Event, 0),
+	}
+
+	episodes, missingShows := c.FindUpcomingEpisodes(ctx, showIDs, from, to)
+
+	missing := make(map[string]error)
+	for ID, err := range missingShows {
+		missing[TitleKey(MediaTV, ID)] = err
+	}
+
+	for _, e := range *episodes {
+		start, err := time.Parse(airDateLayout, e.AirDate)
+		if err != nil {
+			continue
+		}
+
+		cal.Events = append(cal.Events, ical.Event{
+			UID:         fmt.Sprintf("tv-%s-s%de%d@%s", e.ShowID, e.SeasonNumber, e.EpisodeNumber, calendarDomain),
+			Summary:     fmt.Sprintf("%s S%02dE%02d: %s", e.ShowTitle, e.SeasonNumber, e.EpisodeNumber, e.Title),
+			Description: e.Description,
+			Start:       start,
+			AllDay:      true,
+		})
+	}
+
+	for _, ID := range movieIDs {
+		m, err := c.FindMovieReleaseByID(ctx, ID)
+		if err != nil {
+			missing[TitleKey(MediaMovie, ID)] = err
+			continue
+		}
+
+		if !AirsBetween(m.ReleaseDate, from, to) {
+			continue
+		}
+
+		start, err := time.Parse(airDateLayout, m.ReleaseDate)
+		if err != nil {
+			continue
+		}
+
+		cal.Events = append(cal.Events, ical.Event{
+			UID:         fmt.Sprintf("movie-%s@%s", m.ID, calendarDomain),
+			Summary:     fmt.Sprintf("%s (release)", m.Title),
+			Description: m.Description,
+			Start:       start,
+			AllDay:      true,
+		})
+	}
+
+	return cal, missing
+}
@@ -0,0 +1,152 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+//CollectionRef is the collection a movie belongs to, as shown on its details
+type CollectionRef struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	URL   string `json:"thumbnailURL,omitempty"`
+	Image *Image `json:"image,omitempty"`
+}
+
+//CollectionPart is one movie in a collection, Watched is only set by WithProgress
+type CollectionPart struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	ReleaseDate string `json:"releaseDate,omitempty"`
+	URL         string `json:"thumbnailURL,omitempty"`
+	Image       *Image `json:"image,omitempty"`
+	Watched     bool   `json:"watched"`
+}
+
+//CollectionProgress is how far through the collection a profile is, Next is the first part they
+//haven't watched in release order
+type CollectionProgress struct {
+	Watched int    `json:"watched"`
+	Total   int    `json:"total"`
+	Next    string `json:"next,omitempty"`
+}
+
+//Collection is a franchise of movies with its parts in release order
+type Collection struct {
+	ID       string              `json:"id"`
+	Name     string              `json:"name"`
+	Overview string              `json:"overview,omitempty"`
+	URL      string              `json:"thumbnailURL,omitempty"`
+	Poster   *Image              `json:"poster,omitempty"`
+	Backdrop *Image              `json:"backdrop,omitempty"`
+	Parts    []CollectionPart    `json:"parts"`
+	Progress *CollectionProgress `json:"progress,omitempty"`
+}
+
+type collectionResponse struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Overview     string `json:"overview"`
+	PosterPath   string `json:"poster_path"`
+	BackdropPath string `json:"backdrop_path"`
+	Parts        []struct {
+		ID          int    `json:"id"`
+		Title       string `json:"title"`
+		ReleaseDate string `json:"release_date"`
+		PosterPath  string `json:"poster_path"`
+	} `json:"parts"`
+}
+
+//FindCollectionByID looks the collection up with its parts in release order, parts without a
+//release date yet go last
+func (c Content) FindCollectionByID(ctx context.Context, ID string) (*Collection, error) {
+	var resp collectionResponse
+	err := c.getTMDB(ctx, fmt.Sprintf("https://api.themoviedb.org/3/collection/%s?language=%s&api_key=%s", ID, c.Locale.tmdbLanguage(), c.ApiKey), &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	images := c.images(ctx)
+	poster := images.poster(resp.PosterPath)
+	col := Collection{
+		ID:       fmt.Sprintf("%d", resp.ID),
+		Name:     resp.Name,
+		Overview: resp.Overview,
+		URL:      imageURL(poster),
+		Poster:   poster,
+		Backdrop: images.backdrop(resp.BackdropPath),
+		Parts:    make([]CollectionPart, 0, len(resp.Parts)),
+	}
+
+	for _, p := range resp.Parts {
+		img := images.poster(p.PosterPath)
+		col.Parts = append(col.Parts, CollectionPart{
+			ID:          fmt.Sprintf("%d", p.ID),
+			Title:       p.Title,
+			ReleaseDate: p.ReleaseDate,
+			URL:         imageURL(img),
+			Image:       img,
+		})
+	}
+
+	//release dates are YYYY-MM-DD so they sort as strings
+	sort.SliceStable(col.Parts, func(i, j int) bool {
+		a, b := col.Parts[i].ReleaseDate, col.Parts[j].ReleaseDate
+		if a == "" || b == "" {
+			return a != "" && b == ""
+		}
+		return a < b
+	})
+
+	return &col, nil
+}
+
+//WithProgress marks the parts the profile has watched, watched is keyed by movie ID
+func (col Collection) WithProgress(watched map[string]bool) Collection {
+	parts := make([]CollectionPart, 0, len(col.Parts))
+	progress := CollectionProgress{Total: len(col.Parts)}
+	for _, p := range col.Parts {
+		p.Watched = watched[p.ID]
+		if p.Watched {
+			progress.Watched++
+		} else if progress.Next == "" {
+			progress.Next = p.ID
+		}
+		parts = append(parts, p)
+	}
+
+	col.Parts = parts
+	col.Progress = &progress
+	return col
+}
+
+//FilterPartsByRating drops parts over the limit, a franchise often mixes ratings
+func (c Content) FilterPartsByRating(ctx context.Context, col Collection, limit *RatingLimit) (Collection, error) {
+	list := make([]Thumbnail, 0, len(col.Parts))
+	for _, p := range col.Parts {
+		list = append(list, Thumbnail{ID: p.ID, Type: MediaMovie})
+	}
+
+	s, err := c.FilterByRating(ctx, []Suggestion{{List: list}}, limit)
+	if err != nil {
+		return col, err
+	}
+
+	allowed := make(map[string]bool)
+	for _, row := range s {
+		for _, t := range row.List {
+			allowed[t.ID] = true
+		}
+	}
+
+	parts := make([]CollectionPart, 0, len(col.Parts))
+	for _, p := range col.Parts {
+		if allowed[p.ID] {
+			parts = append(parts, p)
+		}
+	}
+
+	col.Parts = parts
+	return col, nil
+}
@@ -14,12 +14,20 @@ import (
 type Content struct {
 	ApiKey   string            `json:"key"`
 	GuideBox guidebox.GuideBox `json:"GuideBox"`
+	//Locale is set per request with WithLocale, the zero value is DefaultLocale
+	Locale Locale `json:"locale"`
+	//ImageBase is the CDN images are served from, TMDB's own host when it is empty
+	ImageBase string `json:"imageBase"`
 }
 
 type Thumbnail struct {
 	ID   string `json:"id"`
 	Type string `json:"type,omitempty"`
 	URL  string `json:"url"`
+	//Image is the poster in every size, URL is its default size
+	Image *Image `json:"image,omitempty"`
+	//Providers is the viewer's subscribed services streaming the title, only set on related rows
+	Providers []int64 `json:"providers,omitempty"`
 }
 
 type Suggestion struct {
@@ -105,7 +113,7 @@ func (c Content) FindAllContentSuggestions(ctx context.Context) (*[]Suggestion,
 
 func (c Content) Search(ctx context.Context, query string) (*[]Suggestion, error) {
 	suggestions := make([]Suggestion, 0)
-	url := fmt.Sprintf("https://api.themoviedb.org/3/search/multi?include_adult=false&page=1&query=%s&language=en-US&api_key=%s", query, c.ApiKey)
+	url := fmt.Sprintf("https://api.themoviedb.org/3/search/multi?include_adult=false&page=1&query=%s&language=%s&api_key=%s", query, c.Locale.tmdbLanguage(), c.ApiKey)
 	payload := strings.NewReader("{}")
 
 	req, _ := http.NewRequest("GET", url, payload)
@@ -128,18 +136,21 @@ func (c Content) Search(ctx context.Context, query string) (*[]Suggestion, error
 
 	println(string(j))
 
+	images := c.images(ctx)
 	thumbnails := make([]Thumbnail, 0)
 	for _, r := range resp.Results {
 
-		var u string
-		if r.PosterPath != nil {
-			u = fmt.Sprintf("https://image.tmdb.org/t/p/w185%v", r.PosterPath)
+		//people have a photo instead of a poster, poster_path is null when there is neither
+		img := images.profile(r.ProfilePath)
+		if path, ok := r.PosterPath.(string); ok {
+			img = images.poster(path)
 		}
 
 		thumbnails = append(thumbnails, Thumbnail{
-			ID:   fmt.Sprintf("%d", r.ID),
-			Type: r.MediaType,
-			URL:  u,
+			ID:    fmt.Sprintf("%d", r.ID),
+			Type:  r.MediaType,
+			URL:   imageURL(img),
+			Image: img,
 		})
 	}
 
@@ -149,3 +160,26 @@ func (c Content) Search(ctx context.Context, query string) (*[]Suggestion, error
 
 	return &suggestions, nil
 }
+
+//FilterSuggestions drops thumbnails keep rejects, and rows left empty. keep gets the title's
+//TitleKey, movies and shows can have the same ID.
+func FilterSuggestions(suggestions []Suggestion, keep func(key string, t Thumbnail) bool) []Suggestion {
+	filtered := make([]Suggestion, 0, len(suggestions))
+	for _, s := range suggestions {
+		list := make([]Thumbnail, 0, len(s.List))
+		for _, t := range s.List {
+			if keep(TitleKey(thumbnailMediaType(s, t), t.ID), t) {
+				list = append(list, t)
+			}
+		}
+
+		if len(list) == 0 {
+			continue
+		}
+
+		s.List = list
+		filtered = append(filtered, s)
+	}
+
+	return filtered
+}
@@ -0,0 +1,74 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+//TitleInfo is what an export needs to name a title outside TMDB, IMDbID is empty when TMDB
+//doesn't know it
+type TitleInfo struct {
+	MediaType string
+	ID        string
+	Title     string
+	Year      int
+	IMDbID    string
+}
+
+type titleInfoResponse struct {
+	Title        string `json:"title"`
+	Name         string `json:"name"`
+	ReleaseDate  string `json:"release_date"`
+	FirstAirDate string `json:"first_air_date"`
+	ExternalIDs  struct {
+		IMDbID string `json:"imdb_id"`
+	} `json:"external_ids"`
+}
+
+//FindTitleInfo looks up the titles by TitleKey, the map is keyed the same way. Titles that fail
+//are left out and the first error is returned with the rest, so a caller can go on without them.
+func (c Content) FindTitleInfo(ctx context.Context, keys []string) (map[string]TitleInfo, error) {
+	info := make(map[string]TitleInfo)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	sem := make(chan struct{}, titleLookups)
+	for _, key := range keys {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			parts := strings.SplitN(key, ":", 2)
+			if len(parts) != 2 || (parts[0] != MediaMovie && parts[0] != MediaTV) {
+				return
+			}
+
+			//no language on purpose, exports are read back by sites that match on the English title
+			var resp titleInfoResponse
+			err := c.getTMDB(ctx, fmt.Sprintf("https://api.themoviedb.org/3/%s/%s?append_to_response=external_ids&api_key=%s", parts[0], parts[1], c.ApiKey), &resp)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+
+			t := TitleInfo{MediaType: parts[0], ID: parts[1], Title: resp.Title, Year: yearOf(resp.ReleaseDate), IMDbID: resp.ExternalIDs.IMDbID}
+			if parts[0] == MediaTV {
+				t.Title, t.Year = resp.Name, yearOf(resp.FirstAirDate)
+			}
+			info[key] = t
+		}(key)
+	}
+	wg.Wait()
+
+	return info, firstErr
+}
@@ -0,0 +1,212 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Image kinds, each comes in its own set of sizes
+const (
+	ImagePoster   = "poster"
+	ImageBackdrop = "backdrop"
+	ImageStill    = "still"
+	ImageProfile  = "profile"
+)
+
+//ImageConfigTTL is how long TMDB's image configuration is kept, it almost never changes
+const ImageConfigTTL = 24 * time.Hour
+
+//Image is one image at every size TMDB has it in, Sizes is keyed by TMDB's size name like w342
+//or original. URL is the size we have always sent for clients that don't pick one and Srcset
+//is the fixed width sizes ready for an img tag.
+type Image struct {
+	URL    string            `json:"url"`
+	Srcset string            `json:"srcset,omitempty"`
+	Sizes  map[string]string `json:"sizes"`
+}
+
+//ImageConfig is where TMDB serves images from and the sizes each kind comes in
+type ImageConfig struct {
+	BaseURL string
+	Sizes   map[string][]string
+}
+
+type configurationResponse struct {
+	Images struct {
+		SecureBaseURL string   `json:"secure_base_url"`
+		PosterSizes   []string `json:"poster_sizes"`
+		BackdropSizes []string `json:"backdrop_sizes"`
+		StillSizes    []string `json:"still_sizes"`
+		ProfileSizes  []string `json:"profile_sizes"`
+	} `json:"images"`
+}
+
+//DefaultImageConfig is what TMDB documents, used until the configuration endpoint answers
+var DefaultImageConfig = ImageConfig{
+	BaseURL: "https://image.tmdb.org/t/p/",
+	Sizes: map[string][]string{
+		ImagePoster:   {"w92", "w154", "w185", "w342", "w500", "w780", "original"},
+		ImageBackdrop: {"w300", "w780", "w1280", "original"},
+		ImageStill:    {"w92", "w185", "w300", "original"},
+		ImageProfile:  {"w45", "w185", "h632", "original"},
+	},
+}
+
+//defaultImageSizes is the size URL is set to, posters and stills stay at w185 like before
+var defaultImageSizes = map[string]string{
+	ImagePoster:   "w185",
+	ImageBackdrop: "w780",
+	ImageStill:    "w185",
+	ImageProfile:  "w185",
+}
+
+//the configuration lives as long as the lambda container, like the rails
+var (
+	imageConfigMu      sync.Mutex
+	imageConfig        *ImageConfig
+	imageConfigExpires time.Time
+)
+
+//FindImageConfig is TMDB's image configuration, cached for ImageConfigTTL
+func (c Content) FindImageConfig(ctx context.Context) (*ImageConfig, error) {
+	imageConfigMu.Lock()
+	cached, expires := imageConfig, imageConfigExpires
+	imageConfigMu.Unlock()
+	if cached != nil && time.Now().Before(expires) {
+		return cached, nil
+	}
+
+	var resp configurationResponse
+	err := c.getTMDB(ctx, fmt.Sprintf("https://api.themoviedb.org/3/configuration?api_key=%s", c.ApiKey), &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := ImageConfig{
+		BaseURL: resp.Images.SecureBaseURL,
+		Sizes: map[string][]string{
+			ImagePoster:   resp.Images.PosterSizes,
+			ImageBackdrop: resp.Images.BackdropSizes,
+			ImageStill:    resp.Images.StillSizes,
+			ImageProfile:  resp.Images.ProfileSizes,
+		},
+	}
+
+	//anything missing from the response falls back to the documented values
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultImageConfig.BaseURL
+	}
+	for kind, sizes := range cfg.Sizes {
+		if len(sizes) == 0 {
+			cfg.Sizes[kind] = DefaultImageConfig.Sizes[kind]
+		}
+	}
+
+	imageConfigMu.Lock()
+	imageConfig = &cfg
+	imageConfigExpires = time.Now().Add(ImageConfigTTL)
+	imageConfigMu.Unlock()
+
+	return &cfg, nil
+}
+
+//Image is the image at path in every size, nil when there is no image so clients never get a
+//URL that points at nothing. A base replaces TMDB's host when images go through a CDN.
+func (cfg ImageConfig) Image(base, kind, path string) *Image {
+	if path == "" {
+		return nil
+	}
+	if base == "" {
+		base = cfg.BaseURL
+	}
+	base = strings.TrimSuffix(base, "/") + "/"
+
+	sizes := cfg.Sizes[kind]
+	if len(sizes) == 0 {
+		sizes = DefaultImageConfig.Sizes[kind]
+	}
+
+	img := Image{Sizes: make(map[string]string, len(sizes))}
+
+	type width struct {
+		url string
+		w   int
+	}
+	widths := make([]width, 0, len(sizes))
+	for _, size := range sizes {
+		u := base + size + path
+		img.Sizes[size] = u
+
+		//only w sizes can go in a srcset, h632 and original don't say how wide they are
+		if strings.HasPrefix(size, "w") {
+			w, err := strconv.Atoi(strings.TrimPrefix(size, "w"))
+			if err == nil {
+				widths = append(widths, width{url: u, w: w})
+			}
+		}
+	}
+
+	sort.Slice(widths, func(i, j int) bool {
+		return widths[i].w < widths[j].w
+	})
+
+	srcset := make([]string, 0, len(widths))
+	for _, w := range widths {
+		srcset = append(srcset, fmt.Sprintf("%s %dw", w.url, w.w))
+	}
+	img.Srcset = strings.Join(srcset, ", ")
+
+	img.URL = img.Sizes[defaultImageSizes[kind]]
+	if img.URL == "" {
+		img.URL = base + "original" + path
+	}
+
+	return &img
+}
+
+//Helpers
+
+//images is the image configuration for a lookup. TMDB being unable to describe its images
+//shouldn't fail the lookup, so the documented sizes are used instead.
+func (c Content) images(ctx context.Context) imageBuilder {
+	cfg, err := c.FindImageConfig(ctx)
+	if err != nil {
+		cfg = &DefaultImageConfig
+	}
+
+	return imageBuilder{cfg: *cfg, base: c.ImageBase}
+}
+
+type imageBuilder struct {
+	cfg  ImageConfig
+	base string
+}
+
+func (b imageBuilder) poster(path string) *Image {
+	return b.cfg.Image(b.base, ImagePoster, path)
+}
+
+func (b imageBuilder) backdrop(path string) *Image {
+	return b.cfg.Image(b.base, ImageBackdrop, path)
+}
+
+func (b imageBuilder) still(path string) *Image {
+	return b.cfg.Image(b.base, ImageStill, path)
+}
+
+func (b imageBuilder) profile(path string) *Image {
+	return b.cfg.Image(b.base, ImageProfile, path)
+}
+
+//imageURL is the default size, or empty when there is no image
+func imageURL(img *Image) string {
+	if img == nil {
+		return ""
+	}
+	return img.URL
+}
@@ -0,0 +1,192 @@
+package content
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultLanguage = "en"
+	DefaultRegion   = "US"
+)
+
+//Locale is the language titles and overviews come back in and the country release dates,
+//certifications and availability are for. Language is ISO 639-1, Region ISO 3166-1.
+type Locale struct {
+	Language string `json:"language"`
+	Region   string `json:"region"`
+}
+
+var DefaultLocale = Locale{Language: DefaultLanguage, Region: DefaultRegion}
+
+var (
+	languagePattern = regexp.MustCompile(`^[a-z]{2}$`)
+	regionPattern   = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+//ValidLanguage is true for a two letter language code like en or es
+func ValidLanguage(language string) bool {
+	return languagePattern.MatchString(strings.ToLower(language))
+}
+
+//ValidRegion is true for an ISO 3166-1 style code like US or GB
+func ValidRegion(region string) bool {
+	return regionPattern.MatchString(strings.ToUpper(region))
+}
+
+//ParseLanguageTag splits a tag like es-MX or pt_BR, either part is empty when it isn't valid
+func ParseLanguageTag(tag string) (string, string) {
+	parts := strings.SplitN(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"), "-", 3)
+
+	var language, region string
+	if ValidLanguage(parts[0]) {
+		language = strings.ToLower(parts[0])
+	}
+	if len(parts) > 1 && ValidRegion(parts[1]) {
+		region = strings.ToUpper(parts[1])
+	}
+
+	return language, region
+}
+
+//ParseAcceptLanguage is the client's most preferred language we can use from an Accept-Language header
+func ParseAcceptLanguage(header string) (string, string) {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	tags := make([]weighted, 0)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		w := weighted{tag: strings.TrimSpace(fields[0]), q: 1}
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if strings.HasPrefix(f, "q=") {
+				q, err := strconv.ParseFloat(strings.TrimPrefix(f, "q="), 64)
+				if err == nil {
+					w.q = q
+				}
+			}
+		}
+
+		if w.tag != "" && w.tag != "*" && w.q > 0 {
+			tags = append(tags, w)
+		}
+	}
+
+	//equal weights keep the order the client sent them in
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+
+	for _, t := range tags {
+		language, region := ParseLanguageTag(t.tag)
+		if language != "" {
+			return language, region
+		}
+	}
+
+	return "", ""
+}
+
+//ResolveLocale starts from the profile's locale, the Accept-Language header overrides it and the
+//language and region query params override both. Anything invalid is ignored. The header's region
+//only fills in a region the profile doesn't have, browsers send one whether the user chose it or not.
+func ResolveLocale(profile Locale, acceptLanguage, language, region string) Locale {
+	l := profile
+
+	headerLanguage, headerRegion := ParseAcceptLanguage(acceptLanguage)
+	if headerLanguage != "" {
+		l.Language = headerLanguage
+	}
+	if headerRegion != "" && l.Region == "" {
+		l.Region = headerRegion
+	}
+
+	queryLanguage, queryRegion := ParseLanguageTag(language)
+	if queryLanguage != "" {
+		l.Language = queryLanguage
+	}
+	if queryRegion != "" {
+		l.Region = queryRegion
+	}
+	if ValidRegion(region) {
+		l.Region = strings.ToUpper(region)
+	}
+
+	return l.withDefaults()
+}
+
+//WithLocale is the content service for a request, every TMDB call it makes uses the locale
+func (c Content) WithLocale(l Locale) Content {
+	c.Locale = l.withDefaults()
+	return c
+}
+
+func (l Locale) withDefaults() Locale {
+	if l.Language == "" {
+		l.Language = DefaultLanguage
+	}
+	if l.Region == "" {
+		l.Region = DefaultRegion
+	}
+	return l
+}
+
+//tmdbLanguage is the language param TMDB wants, es-MX asks for Mexican Spanish before any other
+func (l Locale) tmdbLanguage() string {
+	l = l.withDefaults()
+	return l.Language + "-" + l.Region
+}
+
+type translation struct {
+	Region   string `json:"iso_3166_1"`
+	Language string `json:"iso_639_1"`
+	Data     struct {
+		Title    string `json:"title"`
+		Name     string `json:"name"`
+		Overview string `json:"overview"`
+	} `json:"data"`
+}
+
+//translationsResponse is what append_to_response=translations adds to a details response
+type translationsResponse struct {
+	Translations struct {
+		Translations []translation `json:"translations"`
+	} `json:"translations"`
+}
+
+//fallbackText fills a blank title or overview from the closest translation TMDB has, the same
+//language in another region first and then English
+func (l Locale) fallbackText(t translationsResponse, title, overview string) (string, string) {
+	l = l.withDefaults()
+	closest := []func(tr translation) bool{
+		func(tr translation) bool { return tr.Language == l.Language && tr.Region == l.Region },
+		func(tr translation) bool { return tr.Language == l.Language },
+		func(tr translation) bool { return tr.Language == DefaultLanguage },
+	}
+
+	for _, match := range closest {
+		for _, tr := range t.Translations.Translations {
+			if !match(tr) {
+				continue
+			}
+
+			name := tr.Data.Title
+			if name == "" {
+				name = tr.Data.Name
+			}
+			if title == "" {
+				title = name
+			}
+			if overview == "" {
+				overview = tr.Data.Overview
+			}
+		}
+	}
+
+	return title, overview
+}
@@ -0,0 +1,309 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	//titles are looked up in parallel when resolving an import
+	titleLookups = 8
+	//how alike two normalised titles must be, 1 being the same, for a search result to count
+	titleSimilarity = 0.85
+	maxCandidates   = 3
+)
+
+//TitleQuery is a title from another service, IMDbID and TMDBID are used before Title and Year.
+//For episodes set Season and Episode with the show's IDs or title, or only an episode IMDbID.
+type TitleQuery struct {
+	MediaType string
+	Title     string
+	Year      int
+	IMDbID    string
+	TMDBID    int64
+	Season    int
+	Episode   int
+}
+
+//Match is the TMDB title a query resolved to, ID is the show's for an episode
+type Match struct {
+	MediaType string `json:"mediaType"`
+	ID        int64  `json:"id"`
+	Title     string `json:"title,omitempty"`
+	Year      int    `json:"year,omitempty"`
+	Season    int    `json:"season,omitempty"`
+	Episode   int    `json:"episode,omitempty"`
+}
+
+//TitleResolution is nil Match when nothing was close enough, Candidates are then the nearest
+//search results so the user can pick one. Err is set when TMDB could not be reached.
+type TitleResolution struct {
+	Match      *Match
+	Candidates []Match
+	Err        error
+}
+
+type findResponse struct {
+	MovieResults []struct {
+		ID          int64  `json:"id"`
+		Title       string `json:"title"`
+		ReleaseDate string `json:"release_date"`
+	} `json:"movie_results"`
+	TVResults []struct {
+		ID           int64  `json:"id"`
+		Name         string `json:"name"`
+		FirstAirDate string `json:"first_air_date"`
+	} `json:"tv_results"`
+	TVEpisodeResults []struct {
+		ShowID        int64 `json:"show_id"`
+		SeasonNumber  int   `json:"season_number"`
+		EpisodeNumber int   `json:"episode_number"`
+	} `json:"tv_episode_results"`
+}
+
+type titleSearchResponse struct {
+	Results []struct {
+		ID           int64  `json:"id"`
+		Title        string `json:"title"`
+		Name         string `json:"name"`
+		ReleaseDate  string `json:"release_date"`
+		FirstAirDate string `json:"first_air_date"`
+	} `json:"results"`
+}
+
+//ResolveTitles matches each query to a TMDB title, results are in the order of queries. A
+//failed lookup only fails its own query.
+func (c Content) ResolveTitles(ctx context.Context, queries []TitleQuery) []TitleResolution {
+	resolved := make([]TitleResolution, len(queries))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, titleLookups)
+	for i, q := range queries {
+		wg.Add(1)
+		go func(i int, q TitleQuery) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			resolved[i] = c.resolveTitle(ctx, q)
+		}(i, q)
+	}
+	wg.Wait()
+
+	return resolved
+}
+
+//Helpers
+
+func (c Content) resolveTitle(ctx context.Context, q TitleQuery) TitleResolution {
+	//episodes keep the season and episode of the query whatever the show matched on
+	episode := func(m *Match) *Match {
+		if q.Episode != 0 {
+			m.Season, m.Episode = q.Season, q.Episode
+		}
+		return m
+	}
+
+	if q.TMDBID != 0 {
+		mediaType := q.MediaType
+		if mediaType != MediaMovie {
+			mediaType = MediaTV
+		}
+		return TitleResolution{Match: episode(&Match{MediaType: mediaType, ID: q.TMDBID, Title: q.Title, Year: q.Year})}
+	}
+
+	if q.IMDbID != "" {
+		m, err := c.findByIMDbID(ctx, q)
+		if err != nil {
+			return TitleResolution{Err: err}
+		}
+		if m != nil {
+			return TitleResolution{Match: episode(m)}
+		}
+	}
+
+	if q.Title == "" {
+		return TitleResolution{}
+	}
+
+	candidates, err := c.searchTitle(ctx, q)
+	if err != nil {
+		return TitleResolution{Err: err}
+	}
+
+	best := bestCandidate(q, candidates)
+	if best == nil {
+		if len(candidates) > maxCandidates {
+			candidates = candidates[:maxCandidates]
+		}
+		return TitleResolution{Candidates: candidates}
+	}
+
+	return TitleResolution{Match: episode(best)}
+}
+
+func (c Content) findByIMDbID(ctx context.Context, q TitleQuery) (*Match, error) {
+	var resp findResponse
+	err := c.getTMDB(ctx, fmt.Sprintf("https://api.themoviedb.org/3/find/%s?external_source=imdb_id&api_key=%s", url.PathEscape(q.IMDbID), c.ApiKey), &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	//an episode's own IMDb ID finds the episode, a show's finds the show
+	if len(resp.TVEpisodeResults) > 0 {
+		e := resp.TVEpisodeResults[0]
+		return &Match{MediaType: MediaTV, ID: e.ShowID, Title: q.Title, Season: e.SeasonNumber, Episode: e.EpisodeNumber}, nil
+	}
+
+	if len(resp.MovieResults) > 0 {
+		m := resp.MovieResults[0]
+		return &Match{MediaType: MediaMovie, ID: m.ID, Title: m.Title, Year: yearOf(m.ReleaseDate)}, nil
+	}
+
+	if len(resp.TVResults) > 0 {
+		s := resp.TVResults[0]
+		return &Match{MediaType: MediaTV, ID: s.ID, Title: s.Name, Year: yearOf(s.FirstAirDate)}, nil
+	}
+
+	return nil, nil
+}
+
+//searchTitle searches the query's media type, episodes search for their show
+func (c Content) searchTitle(ctx context.Context, q TitleQuery) ([]Match, error) {
+	mediaType := MediaMovie
+	if q.MediaType != MediaMovie {
+		mediaType = MediaTV
+	}
+
+	//the year is left off the search so a release year that is off by one still turns up
+	u := fmt.Sprintf("https://api.themoviedb.org/3/search/%s?include_adult=false&page=1&query=%s&api_key=%s", mediaType, url.QueryEscape(q.Title), c.ApiKey)
+
+	var resp titleSearchResponse
+	err := c.getTMDB(ctx, u, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]Match, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		m := Match{MediaType: mediaType, ID: r.ID, Title: r.Title, Year: yearOf(r.ReleaseDate)}
+		if mediaType == MediaTV {
+			m.Title, m.Year = r.Name, yearOf(r.FirstAirDate)
+		}
+		candidates = append(candidates, m)
+	}
+
+	return candidates, nil
+}
+
+//bestCandidate is the first result, in TMDB's popularity order, whose title is close enough and
+//whose year is within one of the query's. An exact year wins over an off by one.
+func bestCandidate(q TitleQuery, candidates []Match) *Match {
+	want := normalizeTitle(q.Title)
+
+	var best *Match
+	for i := range candidates {
+		m := candidates[i]
+		if similarity(want, normalizeTitle(m.Title)) < titleSimilarity {
+			continue
+		}
+
+		if q.Year == 0 || m.Year == q.Year {
+			return &m
+		}
+
+		if best == nil && m.Year != 0 && abs(m.Year-q.Year) <= 1 {
+			best = &m
+		}
+	}
+
+	return best
+}
+
+//normalizeTitle lower cases, drops punctuation and a leading article, and spells out &
+func normalizeTitle(title string) string {
+	title = strings.ToLower(strings.ReplaceAll(title, "&", " and "))
+
+	var b strings.Builder
+	for _, r := range title {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		} else if unicode.IsSpace(r) || r == '-' || r == ':' {
+			b.WriteRune(' ')
+		}
+	}
+
+	words := strings.Fields(b.String())
+	if len(words) > 1 && (words[0] == "the" || words[0] == "a" || words[0] == "an") {
+		words = words[1:]
+	}
+
+	return strings.Join(words, " ")
+}
+
+//similarity is one minus the edit distance over the longer title's length
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(editDistance(ra, rb))/float64(longest)
+}
+
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
+
+//yearOf is the year of a TMDB date, zero when it has none
+func yearOf(date string) int {
+	if len(date) < 4 {
+		return 0
+	}
+	y, _ := strconv.Atoi(date[:4])
+	return y
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
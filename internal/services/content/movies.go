@@ -21,7 +21,15 @@ type MovieDetails struct {
 	Title       string            `json:"title"`
 	Description string            `json:"description"`
 	URL         string            `json:"thumbnailURL"`
+	Poster      *Image            `json:"poster,omitempty"`
+	Backdrop    *Image            `json:"backdrop,omitempty"`
+	ReleaseDate string            `json:"releaseDate,omitempty"`
+	Rating      string            `json:"rating,omitempty"`
+	CommonSense string            `json:"commonSenseMedia,omitempty"`
+	VoteAverage float64           `json:"voteAverage"`
+	VoteCount   int               `json:"voteCount"`
 	Sources     []guidebox.Source `json:"source"`
+	Collection  *CollectionRef    `json:"collection,omitempty"`
 }
 
 type MovieSearchResponse struct {
@@ -50,9 +58,10 @@ type Results struct {
 
 func (c Content) FindMovieSuggestions(ctx context.Context) (*[]Suggestion, error) {
 	query := []string{"Action", "Comedy", "Family", "Science Fiction"}
+	images := c.images(ctx)
 	suggestions := make([]Suggestion, 0)
 	for _, q := range query {
-		url := fmt.Sprintf("https://api.themoviedb.org/3/search/movie?include_adult=false&page=1&query=%s&language=en-US&api_key=%s", q, c.ApiKey)
+		url := fmt.Sprintf("https://api.themoviedb.org/3/search/movie?include_adult=false&page=1&query=%s&language=%s&api_key=%s", q, c.Locale.tmdbLanguage(), c.ApiKey)
 		payload := strings.NewReader("{}")
 
 		req, _ := http.NewRequest("GET", url, payload)
@@ -70,9 +79,11 @@ func (c Content) FindMovieSuggestions(ctx context.Context) (*[]Suggestion, error
 
 		thumbnails := make([]Thumbnail, 0)
 		for _, r := range resp.Results {
+			img := images.poster(r.PosterPath)
 			thumbnails = append(thumbnails, Thumbnail{
-				ID:  fmt.Sprintf("%v", r.ID),
-				URL: fmt.Sprintf("https://image.tmdb.org/t/p/w185%v", r.PosterPath),
+				ID:    fmt.Sprintf("%v", r.ID),
+				URL:   imageURL(img),
+				Image: img,
 			})
 		}
 
@@ -87,7 +98,7 @@ func (c Content) FindMovieSuggestions(ctx context.Context) (*[]Suggestion, error
 }
 
 func (c Content) FindMovieDetailsByID(ctx context.Context, ID string) (*MovieDetails, error) {
-	url := fmt.Sprintf("https://api.themoviedb.org/3/movie/%s?language=en-US&api_key=%s", ID, c.ApiKey)
+	url := fmt.Sprintf("https://api.themoviedb.org/3/movie/%s?append_to_response=release_dates,translations&language=%s&api_key=%s", ID, c.Locale.tmdbLanguage(), c.ApiKey)
 
 	payload := strings.NewReader("{}")
 	req, _ := http.NewRequest("GET", url, payload)
@@ -113,9 +124,61 @@ func (c Content) FindMovieDetailsByID(ctx context.Context, ID string) (*MovieDet
 		title = fmt.Sprintf("%v", val)
 	}
 
-	var u string
-	if val, ok := b["poster_path"]; ok {
-		u = fmt.Sprintf("https://image.tmdb.org/t/p/w185%v", val)
+	//the paths are null when TMDB has no image
+	images := c.images(ctx)
+	posterPath, _ := b["poster_path"].(string)
+	backdropPath, _ := b["backdrop_path"].(string)
+	poster := images.poster(posterPath)
+
+	var releaseDate string
+	if val, ok := b["release_date"]; ok && val != nil {
+		releaseDate = fmt.Sprintf("%v", val)
+	}
+
+	//json numbers come out of the map as float64
+	var voteAverage float64
+	if val, ok := b["vote_average"].(float64); ok {
+		voteAverage = val
+	}
+
+	var voteCount int
+	if val, ok := b["vote_count"].(float64); ok {
+		voteCount = int(val)
+	}
+
+	//release dates and translations are easier to read typed than out of the map
+	var extra struct {
+		translationsResponse
+		ReleaseDates releaseDatesResponse `json:"release_dates"`
+	}
+	err = json.Unmarshal(body, &extra)
+	if err != nil {
+		return nil, err
+	}
+
+	//TMDB leaves the overview blank when it has no translation in the language
+	title, description = c.Locale.fallbackText(extra.translationsResponse, title, description)
+
+	region := c.Locale.withDefaults().Region
+	regionalDate, regionalCert := regionalRelease(extra.ReleaseDates, region)
+	if regionalDate != "" {
+		releaseDate = regionalDate
+	}
+
+	//the franchise the movie is part of, null for most movies
+	var collection *CollectionRef
+	if val, ok := b["belongs_to_collection"].(map[string]interface{}); ok {
+		if id, ok := val["id"].(float64); ok {
+			name, _ := val["name"].(string)
+			path, _ := val["poster_path"].(string)
+			img := images.poster(path)
+			collection = &CollectionRef{
+				ID:    fmt.Sprintf("%d", int64(id)),
+				Name:  name,
+				URL:   imageURL(img),
+				Image: img,
+			}
+		}
 	}
 
 	//Call search here
@@ -124,18 +187,32 @@ func (c Content) FindMovieDetailsByID(ctx context.Context, ID string) (*MovieDet
 		return nil, err
 	}
 
-	//Call details
-	sources, err := c.GuideBox.FindMovieSources(ctx, guideBoxId)
+	//Call details, the rating comes along with the sources
+	gb, err := c.GuideBox.FindMovieDetails(ctx, guideBoxId)
 	if err != nil {
 		return nil, err
 	}
 
+	//GuideBox only knows US ratings
+	rating := regionalCert
+	if region == "US" && gb.Rating != "" {
+		rating = gb.Rating
+	}
+
 	d := MovieDetails{
 		ID:          ID,
 		Title:       title,
 		Description: description,
-		URL:         u,
-		Sources:     *sources,
+		URL:         imageURL(poster),
+		Poster:      poster,
+		Backdrop:    images.backdrop(backdropPath),
+		ReleaseDate: releaseDate,
+		Rating:      rating,
+		CommonSense: gb.CommonSenseMedia,
+		VoteAverage: voteAverage,
+		VoteCount:   voteCount,
+		Sources:     gb.AndroidSources(),
+		Collection:  collection,
 	}
 
 	return &d, nil
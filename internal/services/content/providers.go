@@ -0,0 +1,70 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+type watchProvidersResponse struct {
+	Results map[string]struct {
+		Flatrate []struct {
+			ProviderID   int64  `json:"provider_id"`
+			ProviderName string `json:"provider_name"`
+		} `json:"flatrate"`
+	} `json:"results"`
+}
+
+//FindStreamingProviders looks up which subscription services carry each title in the region, the
+//IDs are TMDB's watch provider IDs, the same ones profiles keep in StreamAccounts. The map is keyed
+//by TitleKey and titles that fail are left out with the first error returned, like FindTitleInfo.
+//An empty region is the locale's.
+func (c Content) FindStreamingProviders(ctx context.Context, keys []string, region string) (map[string][]int64, error) {
+	providers := make(map[string][]int64)
+
+	region = strings.ToUpper(region)
+	if region == "" {
+		region = c.Locale.withDefaults().Region
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	sem := make(chan struct{}, titleLookups)
+	for _, key := range keys {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			parts := strings.SplitN(key, ":", 2)
+			if len(parts) != 2 || (parts[0] != MediaMovie && parts[0] != MediaTV) {
+				return
+			}
+
+			var resp watchProvidersResponse
+			err := c.getTMDB(ctx, fmt.Sprintf("https://api.themoviedb.org/3/%s/%s/watch/providers?api_key=%s", parts[0], parts[1], c.ApiKey), &resp)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+
+			//a title on no service in the region still gets an entry, it's known to be unavailable
+			IDs := make([]int64, 0)
+			for _, p := range resp.Results[region].Flatrate {
+				IDs = append(IDs, p.ProviderID)
+			}
+			providers[key] = IDs
+		}(key)
+	}
+	wg.Wait()
+
+	return providers, firstErr
+}
@@ -0,0 +1,210 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Rails are TMDB's curated lists, shown as rows on the home screen
+const (
+	RailTrendingDay    = "trending_day"
+	RailTrendingWeek   = "trending_week"
+	RailPopularMovies  = "popular_movies"
+	RailPopularShows   = "popular_shows"
+	RailTopRatedMovies = "top_rated_movies"
+	RailTopRatedShows  = "top_rated_shows"
+	RailNowPlaying     = "now_playing"
+	RailUpcoming       = "upcoming"
+	RailAiringToday    = "airing_today"
+	RailOnTheAir       = "on_the_air"
+)
+
+//RailTTL is how long a rail is kept in memory, TMDB only refreshes these lists a few times a day
+const RailTTL = 3 * time.Hour
+
+var ErrUnknownRail = errors.New("unknown rail")
+var ErrInvalidRegion = errors.New("region must be a two letter country code")
+
+type rail struct {
+	path     string
+	typ      string
+	category string
+	//TMDB only narrows the movie lists by region, the others are the same everywhere
+	regional bool
+}
+
+//Rails is every rail in the order the home screen shows them
+var Rails = []string{
+	RailTrendingDay,
+	RailTrendingWeek,
+	RailPopularMovies,
+	RailPopularShows,
+	RailTopRatedMovies,
+	RailTopRatedShows,
+	RailNowPlaying,
+	RailUpcoming,
+	RailAiringToday,
+	RailOnTheAir,
+}
+
+var rails = map[string]rail{
+	RailTrendingDay:    {path: "trending/all/day", category: "Trending Today"},
+	RailTrendingWeek:   {path: "trending/all/week", category: "Trending This Week"},
+	RailPopularMovies:  {path: "movie/popular", typ: "Movie", category: "Popular", regional: true},
+	RailPopularShows:   {path: "tv/popular", typ: "TV", category: "Popular"},
+	RailTopRatedMovies: {path: "movie/top_rated", typ: "Movie", category: "Top Rated", regional: true},
+	RailTopRatedShows:  {path: "tv/top_rated", typ: "TV", category: "Top Rated"},
+	RailNowPlaying:     {path: "movie/now_playing", typ: "Movie", category: "Now Playing", regional: true},
+	RailUpcoming:       {path: "movie/upcoming", typ: "Movie", category: "Coming Soon", regional: true},
+	RailAiringToday:    {path: "tv/airing_today", typ: "TV", category: "Airing Today"},
+	RailOnTheAir:       {path: "tv/on_the_air", typ: "TV", category: "On The Air"},
+}
+
+type railResponse struct {
+	Results []struct {
+		ID         int    `json:"id"`
+		MediaType  string `json:"media_type"`
+		PosterPath string `json:"poster_path"`
+	} `json:"results"`
+}
+
+type cachedRail struct {
+	s       Suggestion
+	expires time.Time
+}
+
+//the cache lives as long as the lambda container, so warm invocations skip TMDB
+var (
+	railCacheMu sync.Mutex
+	railCache   = make(map[string]cachedRail)
+)
+
+//FindRail is one rail as a Suggestion row for the region, the locale's region when it is empty.
+//Trending rails mix movies and shows so every thumbnail carries its own type.
+func (c Content) FindRail(ctx context.Context, name, region string) (*Suggestion, error) {
+	r, ok := rails[name]
+	if !ok {
+		return nil, ErrUnknownRail
+	}
+
+	region = strings.ToUpper(region)
+	if region == "" {
+		region = c.Locale.withDefaults().Region
+	}
+	if !ValidRegion(region) {
+		return nil, ErrInvalidRegion
+	}
+
+	//posters are localized too, so every language is cached on its own
+	key := name + ":" + c.Locale.tmdbLanguage()
+	if r.regional {
+		key += ":" + region
+	}
+
+	railCacheMu.Lock()
+	cached, ok := railCache[key]
+	railCacheMu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return copyRail(cached.s), nil
+	}
+
+	url := fmt.Sprintf("https://api.themoviedb.org/3/%s?language=%s&page=1&api_key=%s", r.path, c.Locale.tmdbLanguage(), c.ApiKey)
+	if r.regional {
+		url += "&region=" + region
+	}
+
+	var resp railResponse
+	err := c.getTMDB(ctx, url, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	images := c.images(ctx)
+	thumbnails := make([]Thumbnail, 0, len(resp.Results))
+	for _, t := range resp.Results {
+		typ := t.MediaType
+		if typ == "" {
+			typ = thumbnailMediaType(Suggestion{Type: r.typ}, Thumbnail{})
+		}
+
+		//trending includes people
+		if typ != MediaMovie && typ != MediaTV {
+			continue
+		}
+
+		img := images.poster(t.PosterPath)
+		thumbnails = append(thumbnails, Thumbnail{
+			ID:    fmt.Sprintf("%d", t.ID),
+			Type:  typ,
+			URL:   imageURL(img),
+			Image: img,
+		})
+	}
+
+	s := Suggestion{
+		Type:     r.typ,
+		Category: r.category,
+		List:     thumbnails,
+	}
+
+	railCacheMu.Lock()
+	railCache[key] = cachedRail{s: s, expires: time.Now().Add(RailTTL)}
+	railCacheMu.Unlock()
+
+	return copyRail(s), nil
+}
+
+//FindRails looks the rails up together and keeps their order. A rail that fails is left out and
+//the first error is returned with the rest, like FindTitleInfo.
+func (c Content) FindRails(ctx context.Context, names []string, region string) (*[]Suggestion, error) {
+	found := make([]*Suggestion, len(names))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+
+			s, err := c.FindRail(ctx, name, region)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			found[i] = s
+		}(i, name)
+	}
+	wg.Wait()
+
+	suggestions := make([]Suggestion, 0, len(names))
+	for _, s := range found {
+		if s != nil {
+			suggestions = append(suggestions, *s)
+		}
+	}
+
+	return &suggestions, firstErr
+}
+
+func ValidRail(name string) bool {
+	_, ok := rails[name]
+	return ok
+}
+
+//Helpers
+
+//callers filter the list, they mustn't change the cached one
+func copyRail(s Suggestion) *Suggestion {
+	s.List = append([]Thumbnail(nil), s.List...)
+	return &s
+}
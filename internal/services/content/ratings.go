@@ -0,0 +1,340 @@
+package content
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	MediaMovie = "movie"
+	MediaTV    = "tv"
+
+	//certification lookups run in parallel when filtering a page of suggestions
+	ratingLookups = 8
+)
+
+//certifications lists each country's ratings from least to most restrictive, as TMDB reports them
+var certifications = map[string]map[string][]string{
+	"US": {
+		MediaMovie: {"G", "PG", "PG-13", "R", "NC-17"},
+		MediaTV:    {"TV-Y", "TV-Y7", "TV-G", "TV-PG", "TV-14", "TV-MA"},
+	},
+	"GB": {
+		MediaMovie: {"U", "PG", "12A", "12", "15", "18", "R18"},
+		MediaTV:    {"U", "PG", "12", "15", "18"},
+	},
+	"CA": {
+		MediaMovie: {"G", "PG", "14A", "18A", "R"},
+		MediaTV:    {"C", "C8", "G", "PG", "14+", "18+"},
+	},
+	"AU": {
+		MediaMovie: {"G", "PG", "M", "MA15+", "R18+", "X18+"},
+		MediaTV:    {"P", "C", "G", "PG", "M", "MA15+", "AV15+", "R18+"},
+	},
+	"DE": {
+		MediaMovie: {"0", "6", "12", "16", "18"},
+		MediaTV:    {"0", "6", "12", "16", "18"},
+	},
+	"FR": {
+		MediaMovie: {"U", "10", "12", "16", "18"},
+		MediaTV:    {"NR", "10", "12", "16", "18"},
+	},
+}
+
+//RatingLimit is a profile's parental controls. A nil limit allows everything.
+type RatingLimit struct {
+	Country  string
+	MaxMovie string
+	MaxTV    string
+	//BlockUnrated hides titles with no certification in Country
+	BlockUnrated bool
+	//Allowed are "type:id" keys a parent unlocked with their PIN
+	Allowed []string
+}
+
+type RatingCheck struct {
+	Allowed       bool   `json:"allowed"`
+	Certification string `json:"certification,omitempty"`
+}
+
+type releaseDatesResponse struct {
+	Results []struct {
+		Country      string `json:"iso_3166_1"`
+		ReleaseDates []struct {
+			Certification string `json:"certification"`
+			ReleaseDate   string `json:"release_date"`
+			Type          int    `json:"type"`
+		} `json:"release_dates"`
+	} `json:"results"`
+}
+
+type contentRatingsResponse struct {
+	Results []struct {
+		Country string `json:"iso_3166_1"`
+		Rating  string `json:"rating"`
+	} `json:"results"`
+}
+
+//ValidCertification is for checking parental controls before they are saved
+func ValidCertification(country, mediaType, cert string) bool {
+	_, ok := certificationLevel(country, mediaType, cert)
+	return ok
+}
+
+//Certifications is the rating system a client should offer for a country
+func Certifications(country string) (map[string][]string, bool) {
+	c, ok := certifications[strings.ToUpper(country)]
+	return c, ok
+}
+
+//TitleKey tells titles apart wherever movies and shows are kept together, like RatingLimit.Allowed,
+//because they share TMDB IDs
+func TitleKey(mediaType, ID string) string {
+	return mediaType + ":" + ID
+}
+
+//FindCertification is the title's rating in the country, empty when it has none.
+//US movies TMDB has no rating for fall back to GuideBox's MPAA rating.
+func (c Content) FindCertification(ctx context.Context, mediaType, ID, country string) (string, error) {
+	country = strings.ToUpper(country)
+
+	if mediaType == MediaTV {
+		var resp contentRatingsResponse
+		err := c.getTMDB(ctx, fmt.Sprintf("https://api.themoviedb.org/3/tv/%s/content_ratings?api_key=%s", ID, c.ApiKey), &resp)
+		if err != nil {
+			return "", err
+		}
+
+		for _, r := range resp.Results {
+			if r.Country == country {
+				return r.Rating, nil
+			}
+		}
+
+		return "", nil
+	}
+
+	var resp releaseDatesResponse
+	err := c.getTMDB(ctx, fmt.Sprintf("https://api.themoviedb.org/3/movie/%s/release_dates?api_key=%s", ID, c.ApiKey), &resp)
+	if err != nil {
+		return "", err
+	}
+
+	_, cert := regionalRelease(resp, country)
+	if cert == "" && country == "US" {
+		guideBoxID, err := c.GuideBox.SearchByIDAndType(ctx, "movie", ID)
+		if err != nil {
+			return "", err
+		}
+
+		gb, err := c.GuideBox.FindMovieDetails(ctx, guideBoxID)
+		if err != nil {
+			return "", err
+		}
+		cert = gb.Rating
+	}
+
+	return cert, nil
+}
+
+//CheckRating is used by the detail endpoints, seasons and episodes are checked with their show
+func (c Content) CheckRating(ctx context.Context, limit *RatingLimit, mediaType, ID string) (*RatingCheck, error) {
+	if limit == nil {
+		return &RatingCheck{Allowed: true}, nil
+	}
+
+	for _, key := range limit.Allowed {
+		if key == TitleKey(mediaType, ID) {
+			return &RatingCheck{Allowed: true}, nil
+		}
+	}
+
+	cert, err := c.FindCertification(ctx, mediaType, ID, limit.Country)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RatingCheck{
+		Allowed:       limit.allows(mediaType, cert),
+		Certification: cert,
+	}, nil
+}
+
+//FilterByRating removes titles over the limit from suggestion rows and search results
+func (c Content) FilterByRating(ctx context.Context, suggestions []Suggestion, limit *RatingLimit) ([]Suggestion, error) {
+	if limit == nil {
+		return suggestions, nil
+	}
+
+	//look each title up once, the same title can show up in several rows
+	checks := make(map[string]*RatingCheck)
+	keys := make([]string, 0)
+	for _, s := range suggestions {
+		for _, t := range s.List {
+			typ := thumbnailMediaType(s, t)
+			if typ == "" {
+				continue
+			}
+
+			key := TitleKey(typ, t.ID)
+			if _, ok := checks[key]; !ok {
+				checks[key] = nil
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	sem := make(chan struct{}, ratingLookups)
+	for _, key := range keys {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			parts := strings.SplitN(key, ":", 2)
+			check, err := c.CheckRating(ctx, limit, parts[0], parts[1])
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			checks[key] = check
+		}(key)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	filtered := make([]Suggestion, 0, len(suggestions))
+	for _, s := range suggestions {
+		list := make([]Thumbnail, 0, len(s.List))
+		for _, t := range s.List {
+			typ := thumbnailMediaType(s, t)
+			//people in search results have no rating
+			if typ == "" || checks[TitleKey(typ, t.ID)].Allowed {
+				list = append(list, t)
+			}
+		}
+
+		if len(list) == 0 {
+			continue
+		}
+
+		s.List = list
+		filtered = append(filtered, s)
+	}
+
+	return filtered, nil
+}
+
+//Helpers
+
+func (l RatingLimit) allows(mediaType, cert string) bool {
+	max := l.MaxMovie
+	if mediaType == MediaTV {
+		max = l.MaxTV
+	}
+
+	level, ok := certificationLevel(l.Country, mediaType, cert)
+	if !ok {
+		return !l.BlockUnrated
+	}
+
+	//no maximum for this media type means only unrated titles are restricted
+	maxLevel, ok := certificationLevel(l.Country, mediaType, max)
+	if !ok {
+		return true
+	}
+
+	return level <= maxLevel
+}
+
+func certificationLevel(country, mediaType, cert string) (int, bool) {
+	for i, c := range certifications[strings.ToUpper(country)][mediaType] {
+		if strings.EqualFold(c, strings.TrimSpace(cert)) {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+//regionalRelease is the movie's release date and rating in the country, either is empty when TMDB
+//has none. The theatrical release, type 3, is preferred over premieres and home releases.
+func regionalRelease(resp releaseDatesResponse, country string) (string, string) {
+	var date, cert string
+	for _, r := range resp.Results {
+		if r.Country != country {
+			continue
+		}
+
+		for _, d := range r.ReleaseDates {
+			//dates come as full timestamps, the rest of the service uses YYYY-MM-DD
+			day := d.ReleaseDate
+			if len(day) > 10 {
+				day = day[:10]
+			}
+			if day != "" && (date == "" || d.Type == 3) {
+				date = day
+			}
+
+			if d.Certification != "" && (cert == "" || d.Type == 3) {
+				cert = d.Certification
+			}
+		}
+	}
+
+	return date, cert
+}
+
+func thumbnailMediaType(s Suggestion, t Thumbnail) string {
+	typ := strings.ToLower(t.Type)
+	if typ == "" {
+		typ = strings.ToLower(s.Type)
+	}
+
+	switch typ {
+	case "movie":
+		return MediaMovie
+	case "tv", "show":
+		return MediaTV
+	}
+
+	return ""
+}
+
+func (c Content) getTMDB(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("tmdb returned %d for %s", res.StatusCode, strings.Split(url, "?")[0])
+	}
+
+	return json.Unmarshal(body, v)
+}
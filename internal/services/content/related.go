@@ -0,0 +1,144 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type relatedResponse struct {
+	Title           string       `json:"title"`
+	Name            string       `json:"name"`
+	Recommendations railResponse `json:"recommendations"`
+	Similar         railResponse `json:"similar"`
+}
+
+//Related is what TMDB suggests from a title. Recommended is based on what people who liked it
+//watched, Similar on shared genres and keywords.
+type Related struct {
+	Title       string
+	Recommended []Thumbnail
+	Similar     []Thumbnail
+}
+
+//FindRelated looks up the title's recommendations and similar titles in one request, a title in
+//both lists is only kept in Recommended
+func (c Content) FindRelated(ctx context.Context, mediaType, ID string) (*Related, error) {
+	if mediaType != MediaMovie && mediaType != MediaTV {
+		return nil, fmt.Errorf("unknown media type %s", mediaType)
+	}
+
+	var resp relatedResponse
+	err := c.getTMDB(ctx, fmt.Sprintf("https://api.themoviedb.org/3/%s/%s?append_to_response=recommendations,similar&language=%s&api_key=%s", mediaType, ID, c.Locale.tmdbLanguage(), c.ApiKey), &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	title := resp.Title
+	if title == "" {
+		title = resp.Name
+	}
+
+	images := c.images(ctx)
+	r := Related{
+		Title:       title,
+		Recommended: relatedThumbnails(images, resp.Recommendations, mediaType, nil),
+	}
+
+	seen := make(map[string]bool)
+	for _, t := range r.Recommended {
+		seen[t.ID] = true
+	}
+	r.Similar = relatedThumbnails(images, resp.Similar, mediaType, seen)
+
+	return &r, nil
+}
+
+//Rows is the related titles as Suggestion rows, the recommendations row is headed "Because you
+//added" when the title is in the viewer's library. Empty rows are left out.
+func (r Related) Rows(inLibrary bool) []Suggestion {
+	category := "Recommended"
+	if inLibrary {
+		category = fmt.Sprintf("Because you added %s", r.Title)
+	}
+
+	rows := make([]Suggestion, 0, 2)
+	if len(r.Recommended) > 0 {
+		rows = append(rows, Suggestion{Category: category, List: r.Recommended})
+	}
+	if len(r.Similar) > 0 {
+		rows = append(rows, Suggestion{Category: "More Like This", List: r.Similar})
+	}
+
+	return rows
+}
+
+//AnnotateProviders sets Providers on each thumbnail to the subscribed services streaming it in
+//the region. Nothing is looked up when there are no subscriptions. Titles whose providers couldn't
+//be found are left without any and the first error is returned, like FindStreamingProviders.
+func (c Content) AnnotateProviders(ctx context.Context, suggestions []Suggestion, region string, subscribed map[int64]bool) ([]Suggestion, error) {
+	if len(subscribed) == 0 {
+		return suggestions, nil
+	}
+
+	keys := make([]string, 0)
+	added := make(map[string]bool)
+	for _, s := range suggestions {
+		for _, t := range s.List {
+			key := TitleKey(thumbnailMediaType(s, t), t.ID)
+			if !added[key] {
+				added[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	providers, err := c.FindStreamingProviders(ctx, keys, region)
+
+	annotated := make([]Suggestion, 0, len(suggestions))
+	for _, s := range suggestions {
+		list := make([]Thumbnail, 0, len(s.List))
+		for _, t := range s.List {
+			t.Providers = nil
+			for _, p := range providers[TitleKey(thumbnailMediaType(s, t), t.ID)] {
+				if subscribed[p] {
+					t.Providers = append(t.Providers, p)
+				}
+			}
+			list = append(list, t)
+		}
+
+		s.List = list
+		annotated = append(annotated, s)
+	}
+
+	return annotated, err
+}
+
+//Helpers
+
+func relatedThumbnails(images imageBuilder, resp railResponse, mediaType string, skip map[string]bool) []Thumbnail {
+	thumbnails := make([]Thumbnail, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		ID := fmt.Sprintf("%d", r.ID)
+		if skip[ID] {
+			continue
+		}
+
+		//recommendations are the same type as the title, TMDB only sometimes says so
+		typ := strings.ToLower(r.MediaType)
+		if typ == "" {
+			typ = mediaType
+		}
+
+		img := images.poster(r.PosterPath)
+		thumbnails = append(thumbnails, Thumbnail{
+			ID:    ID,
+			Type:  typ,
+			URL:   imageURL(img),
+			Image: img,
+		})
+	}
+
+	return thumbnails
+}
@@ -0,0 +1,152 @@
+package content
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const airDateLayout = "2006-01-02"
+
+type AiringEpisode struct {
+	ShowID        string `json:"showId"`
+	ShowTitle     string `json:"showTitle"`
+	SeasonNumber  int    `json:"season_number"`
+	EpisodeNumber int    `json:"episode_number"`
+	Title         string `json:"title"`
+	Description   string `json:"description,omitempty"`
+	AirDate       string `json:"airDate"`
+	URL           string `json:"thumbnailURL,omitempty"`
+	Still         *Image `json:"still,omitempty"`
+}
+
+type MovieRelease struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	ReleaseDate string `json:"releaseDate"`
+}
+
+func newAiringEpisode(images imageBuilder, showID int, showTitle string, e *EpisodeToAir) *AiringEpisode {
+	if e == nil || e.AirDate == "" {
+		return nil
+	}
+
+	still := images.still(e.StillPath)
+
+	return &AiringEpisode{
+		ShowID:        fmt.Sprintf("%v", showID),
+		ShowTitle:     showTitle,
+		SeasonNumber:  e.SeasonNumber,
+		EpisodeNumber: e.EpisodeNumber,
+		Title:         e.Name,
+		Description:   e.Overview,
+		AirDate:       e.AirDate,
+		URL:           imageURL(still),
+		Still:         still,
+	}
+}
+
+//FindUpcomingEpisodes is every episode of the shows airing between from and to, soonest first. A show
+//TMDB can't find is left out and returned in missing so one removed show doesn't hide the rest.
+func (c Content) FindUpcomingEpisodes(ctx context.Context, showIDs []string, from, to time.Time) (*[]AiringEpisode, map[string]error) {
+	upcoming := make([]AiringEpisode, 0)
+	missing := make(map[string]error)
+	for _, ID := range showIDs {
+		show, err := c.FindShowDetailsByID(ctx, ID)
+		if err != nil {
+			missing[ID] = err
+			continue
+		}
+
+		if show.NextEpisode == nil {
+			continue
+		}
+
+		//the next episode only tells us where to start, the rest of the week comes from the season
+		season, err := c.FindSeasonDetailsByNumber(ctx, ID, fmt.Sprintf("%d", show.NextEpisode.SeasonNumber))
+		if err != nil {
+			missing[ID] = err
+			continue
+		}
+
+		for _, e := range season.Episodes {
+			if !AirsBetween(e.AirDate, from, to) {
+				continue
+			}
+
+			upcoming = append(upcoming, AiringEpisode{
+				ShowID:        show.ID,
+				ShowTitle:     show.Title,
+				SeasonNumber:  season.SeasonNumber,
+				EpisodeNumber: e.EpisodeNumber,
+				Title:         e.Title,
+				AirDate:       e.AirDate,
+				URL:           e.PosterPath,
+				Still:         e.Still,
+			})
+		}
+	}
+
+	sort.SliceStable(upcoming, func(i, j int) bool {
+		return upcoming[i].AirDate < upcoming[j].AirDate
+	})
+
+	return &upcoming, missing
+}
+
+func AirsBetween(airDate string, from, to time.Time) bool {
+	d, err := time.Parse(airDateLayout, airDate)
+	if err != nil {
+		return false
+	}
+
+	start := from.UTC().Truncate(24 * time.Hour)
+	end := to.UTC().Truncate(24 * time.Hour)
+
+	return !d.Before(start) && !d.After(end)
+}
+
+func (c Content) FindMovieReleaseByID(ctx context.Context, ID string) (*MovieRelease, error) {
+	url := fmt.Sprintf("https://api.themoviedb.org/3/movie/%s?append_to_response=release_dates,translations&language=%s&api_key=%s", ID, c.Locale.tmdbLanguage(), c.ApiKey)
+
+	payload := strings.NewReader("{}")
+	req, _ := http.NewRequest("GET", url, payload)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, _ := ioutil.ReadAll(res.Body)
+
+	var resp struct {
+		Results
+		translationsResponse
+		ReleaseDates releaseDatesResponse `json:"release_dates"`
+	}
+	err = json.Unmarshal(body, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	title, overview := c.Locale.fallbackText(resp.translationsResponse, resp.Title, resp.Overview)
+
+	//the calendar should show when it comes out where the viewer is
+	releaseDate := resp.ReleaseDate
+	if date, _ := regionalRelease(resp.ReleaseDates, c.Locale.withDefaults().Region); date != "" {
+		releaseDate = date
+	}
+
+	return &MovieRelease{
+		ID:          ID,
+		Title:       title,
+		Description: overview,
+		ReleaseDate: releaseDate,
+	}, nil
+}
@@ -13,11 +13,19 @@ import (
 )
 
 type ShowDetails struct {
-	ID          string   `json:"id"`
-	Title       string   `json:"title"`
-	Description string   `json:"description"`
-	URL         string   `json:"thumbnailURL"`
-	Seasons     []Season `json:"seasons"`
+	ID           string         `json:"id"`
+	Title        string         `json:"title"`
+	Description  string         `json:"description"`
+	URL          string         `json:"thumbnailURL"`
+	Poster       *Image         `json:"poster,omitempty"`
+	Backdrop     *Image         `json:"backdrop,omitempty"`
+	VoteAverage  float64        `json:"voteAverage"`
+	VoteCount    int            `json:"voteCount"`
+	Rating       string         `json:"rating,omitempty"`
+	Seasons      []Season       `json:"seasons"`
+	InProduction bool           `json:"inProduction"`
+	LastEpisode  *AiringEpisode `json:"lastEpisode,omitempty"`
+	NextEpisode  *AiringEpisode `json:"nextEpisode,omitempty"`
 }
 
 type SeasonDetails struct {
@@ -25,6 +33,7 @@ type SeasonDetails struct {
 	Title        string    `json:"title"`
 	Description  string    `json:"description"`
 	URL          string    `json:"thumbnailURL"`
+	Poster       *Image    `json:"poster,omitempty"`
 	Episodes     []Episode `json:"episodes"`
 }
 
@@ -33,20 +42,50 @@ type EpisodeDetails struct {
 	Title       string            `json:"title"`
 	Description string            `json:"description"`
 	URL         string            `json:"thumbnailURL"`
+	Still       *Image            `json:"still,omitempty"`
+	VoteAverage float64           `json:"voteAverage"`
+	VoteCount   int               `json:"voteCount"`
+	GuestStars  []GuestStar       `json:"guestStars,omitempty"`
 	Sources     []guidebox.Source `json:"sources"`
 }
 
+//GuestStar is someone who appears in just the one episode, Photo is left out when TMDB has none
+type GuestStar struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Character string `json:"character,omitempty"`
+	Photo     *Image `json:"photo,omitempty"`
+}
+
 type Season struct {
 	ID           string `json:"id"`
 	Title        string `json:"title"`
 	Description  string `json:"description"`
 	PosterPath   string `json:"thumbnailURL"`
+	Image        *Image `json:"image,omitempty"`
 	SeasonNumber int    `json:"season_number"`
 }
 
 type Episode struct {
 	EpisodeNumber int    `json:"episode_number"`
+	Title         string `json:"title,omitempty"`
+	AirDate       string `json:"airDate,omitempty"`
 	PosterPath    string `json:"thumbnailURL"`
+	Still         *Image `json:"still,omitempty"`
+}
+
+type EpisodeToAir struct {
+	AirDate        string  `json:"air_date"`
+	EpisodeNumber  int     `json:"episode_number"`
+	ID             int     `json:"id"`
+	Name           string  `json:"name"`
+	Overview       string  `json:"overview"`
+	ProductionCode string  `json:"production_code"`
+	SeasonNumber   int     `json:"season_number"`
+	ShowID         int     `json:"show_id"`
+	StillPath      string  `json:"still_path"`
+	VoteAverage    float64 `json:"vote_average"`
+	VoteCount      int     `json:"vote_count"`
 }
 
 type ResponseSeason struct {
@@ -68,26 +107,14 @@ type ShowDetailsResponse struct {
 		ID   int    `json:"id"`
 		Name string `json:"name"`
 	} `json:"genres"`
-	Homepage         string   `json:"homepage"`
-	ID               int      `json:"id"`
-	InProduction     bool     `json:"in_production"`
-	Languages        []string `json:"languages"`
-	LastAirDate      string   `json:"last_air_date"`
-	LastEpisodeToAir struct {
-		AirDate        string      `json:"air_date"`
-		EpisodeNumber  int         `json:"episode_number"`
-		ID             int         `json:"id"`
-		Name           string      `json:"name"`
-		Overview       string      `json:"overview"`
-		ProductionCode string      `json:"production_code"`
-		SeasonNumber   int         `json:"season_number"`
-		ShowID         int         `json:"show_id"`
-		StillPath      interface{} `json:"still_path"`
-		VoteAverage    float64     `json:"vote_average"`
-		VoteCount      int         `json:"vote_count"`
-	} `json:"last_episode_to_air"`
-	Name             string      `json:"name"`
-	NextEpisodeToAir interface{} `json:"next_episode_to_air"`
+	Homepage         string        `json:"homepage"`
+	ID               int           `json:"id"`
+	InProduction     bool          `json:"in_production"`
+	Languages        []string      `json:"languages"`
+	LastAirDate      string        `json:"last_air_date"`
+	LastEpisodeToAir *EpisodeToAir `json:"last_episode_to_air"`
+	Name             string        `json:"name"`
+	NextEpisodeToAir *EpisodeToAir `json:"next_episode_to_air"`
 	Networks         []struct {
 		Name          string `json:"name"`
 		ID            int    `json:"id"`
@@ -171,9 +198,10 @@ type EpisodeDetailResponse struct {
 
 func (c Content) FindShowSuggestions(ctx context.Context) (*[]Suggestion, error) {
 	query := []string{"Action", "Comedy", "Family", "Science Fiction"}
+	images := c.images(ctx)
 	suggestions := make([]Suggestion, 0)
 	for _, q := range query {
-		url := fmt.Sprintf("https://api.themoviedb.org/3/search/tv?include_adult=false&page=1&query=%s&language=en-US&api_key=%s", q, c.ApiKey)
+		url := fmt.Sprintf("https://api.themoviedb.org/3/search/tv?include_adult=false&page=1&query=%s&language=%s&api_key=%s", q, c.Locale.tmdbLanguage(), c.ApiKey)
 		payload := strings.NewReader("{}")
 
 		req, _ := http.NewRequest("GET", url, payload)
@@ -191,9 +219,11 @@ func (c Content) FindShowSuggestions(ctx context.Context) (*[]Suggestion, error)
 
 		thumbnails := make([]Thumbnail, 0)
 		for _, r := range resp.Results {
+			img := images.poster(r.PosterPath)
 			thumbnails = append(thumbnails, Thumbnail{
-				ID:  fmt.Sprintf("%v", r.ID),
-				URL: fmt.Sprintf("https://image.tmdb.org/t/p/w185%v", r.PosterPath),
+				ID:    fmt.Sprintf("%v", r.ID),
+				URL:   imageURL(img),
+				Image: img,
 			})
 		}
 
@@ -208,7 +238,7 @@ func (c Content) FindShowSuggestions(ctx context.Context) (*[]Suggestion, error)
 }
 
 func (c Content) FindShowDetailsByID(ctx context.Context, ID string) (*ShowDetails, error) {
-	url := fmt.Sprintf("https://api.themoviedb.org/3/tv/%s?language=en-US&api_key=%s", ID, c.ApiKey)
+	url := fmt.Sprintf("https://api.themoviedb.org/3/tv/%s?append_to_response=content_ratings,translations&language=%s&api_key=%s", ID, c.Locale.tmdbLanguage(), c.ApiKey)
 
 	payload := strings.NewReader("{}")
 	req, _ := http.NewRequest("GET", url, payload)
@@ -217,37 +247,65 @@ func (c Content) FindShowDetailsByID(ctx context.Context, ID string) (*ShowDetai
 
 	body, _ := ioutil.ReadAll(res.Body)
 
-	var resp ShowDetailsResponse
+	var resp struct {
+		ShowDetailsResponse
+		translationsResponse
+		ContentRatings contentRatingsResponse `json:"content_ratings"`
+	}
 
 	err := json.Unmarshal(body, &resp)
 	if err != nil {
 		return nil, err
 	}
 
+	//TMDB leaves the overview blank when it has no translation in the language
+	name, overview := c.Locale.fallbackText(resp.translationsResponse, resp.Name, resp.Overview)
+
+	var rating string
+	region := c.Locale.withDefaults().Region
+	for _, r := range resp.ContentRatings.Results {
+		if r.Country == region {
+			rating = r.Rating
+		}
+	}
+
+	images := c.images(ctx)
 	seasons := make([]Season, 0)
 	for _, s := range resp.Seasons {
+		img := images.poster(s.PosterPath)
 		seasons = append(seasons, Season{
 			ID:           fmt.Sprintf("%v", s.ID),
 			Title:        s.Name,
 			Description:  s.Overview,
-			PosterPath:   fmt.Sprintf("https://image.tmdb.org/t/p/w185%v", s.PosterPath),
+			PosterPath:   imageURL(img),
+			Image:        img,
 			SeasonNumber: s.SeasonNumber,
 		})
 	}
 
+	poster := images.poster(resp.PosterPath)
 	d := ShowDetails{
 		ID:          fmt.Sprintf("%v", resp.ID),
-		Title:       fmt.Sprintf("%v", resp.Name),
-		Description: fmt.Sprintf("%v", resp.Overview),
-		URL:         fmt.Sprintf("https://image.tmdb.org/t/p/w185%v", resp.PosterPath),
+		Title:       name,
+		Description: overview,
+		URL:         imageURL(poster),
+		Poster:      poster,
+		Backdrop:    images.backdrop(resp.BackdropPath),
+		VoteAverage: resp.VoteAverage,
+		VoteCount:   resp.VoteCount,
+		Rating:      rating,
 		Seasons:     seasons,
+
+		InProduction: resp.InProduction,
+		LastEpisode:  newAiringEpisode(images, resp.ID, name, resp.LastEpisodeToAir),
+		NextEpisode:  newAiringEpisode(images, resp.ID, name, resp.NextEpisodeToAir),
 	}
 
 	return &d, nil
 }
 
 func (c Content) FindSeasonDetailsByNumber(ctx context.Context, id, number string) (*SeasonDetails, error) {
-	url := fmt.Sprintf("https://api.themoviedb.org/3/tv/%s/season/%s?language=en-US&api_key=%s", id, number, c.ApiKey)
+	url := fmt.Sprintf("https://api.themoviedb.org/3/tv/%s/season/%s?append_to_response=translations&language=%s&api_key=%s", id, number, c.Locale.tmdbLanguage(), c.ApiKey)
 
 	payload := strings.NewReader("{}")
 	req, _ := http.NewRequest("GET", url, payload)
@@ -256,11 +314,15 @@ func (c Content) FindSeasonDetailsByNumber(ctx context.Context, id, number strin
 
 	body, _ := ioutil.ReadAll(res.Body)
 
-	var resp SeasonDetailsResponse
+	var resp struct {
+		SeasonDetailsResponse
+		translationsResponse
+	}
 	err := json.Unmarshal(body, &resp)
 	if err != nil {
 		return nil, err
 	}
+	resp.Name, resp.Overview = c.Locale.fallbackText(resp.translationsResponse, resp.Name, resp.Overview)
 
 	js, err := json.Marshal(resp)
 	if err != nil {
@@ -269,19 +331,26 @@ func (c Content) FindSeasonDetailsByNumber(ctx context.Context, id, number strin
 
 	println(string(js))
 
+	images := c.images(ctx)
 	episodes := make([]Episode, 0)
 	for _, e := range resp.Episodes {
+		still := images.still(e.StillPath)
 		episodes = append(episodes, Episode{
-			PosterPath:    fmt.Sprintf("https://image.tmdb.org/t/p/w185%v", e.StillPath),
+			PosterPath:    imageURL(still),
+			Still:         still,
 			EpisodeNumber: e.EpisodeNumber,
+			Title:         e.Name,
+			AirDate:       e.AirDate,
 		})
 	}
 
+	poster := images.poster(resp.PosterPath)
 	d := SeasonDetails{
 		SeasonNumber: resp.SeasonNumber,
 		Title:        fmt.Sprintf("%v", resp.Name),
 		Description:  fmt.Sprintf("%v", resp.Overview),
-		URL:          fmt.Sprintf("https://image.tmdb.org/t/p/w185%v", resp.PosterPath),
+		URL:          imageURL(poster),
+		Poster:       poster,
 		Episodes:     episodes,
 	}
 
@@ -289,7 +358,7 @@ func (c Content) FindSeasonDetailsByNumber(ctx context.Context, id, number strin
 }
 
 func (c Content) FindEpisodeDetailsByNumber(ctx context.Context, tvId, seasonNum, episodeNum string) (*EpisodeDetails, error) {
-	url := fmt.Sprintf("https://api.themoviedb.org/3/tv/%s/season/%s/episode/%s?language=en-US&api_key=%s", tvId, seasonNum, episodeNum, c.ApiKey)
+	url := fmt.Sprintf("https://api.themoviedb.org/3/tv/%s/season/%s/episode/%s?append_to_response=translations&language=%s&api_key=%s", tvId, seasonNum, episodeNum, c.Locale.tmdbLanguage(), c.ApiKey)
 
 	payload := strings.NewReader("{}")
 	req, _ := http.NewRequest("GET", url, payload)
@@ -298,12 +367,16 @@ func (c Content) FindEpisodeDetailsByNumber(ctx context.Context, tvId, seasonNum
 
 	body, _ := ioutil.ReadAll(res.Body)
 
-	var resp EpisodeDetailResponse
+	var resp struct {
+		EpisodeDetailResponse
+		translationsResponse
+	}
 
 	err := json.Unmarshal(body, &resp)
 	if err != nil {
 		return nil, err
 	}
+	resp.Name, resp.Overview = c.Locale.fallbackText(resp.translationsResponse, resp.Name, resp.Overview)
 
 	epNum, err := strconv.Atoi(episodeNum)
 	if err != nil {
@@ -342,11 +415,27 @@ func (c Content) FindEpisodeDetailsByNumber(ctx context.Context, tvId, seasonNum
 		}
 	}
 
+	images := c.images(ctx)
+	guests := make([]GuestStar, 0, len(resp.GuestStars))
+	for _, g := range resp.GuestStars {
+		guests = append(guests, GuestStar{
+			ID:        fmt.Sprintf("%v", g.ID),
+			Name:      g.Name,
+			Character: g.Character,
+			Photo:     images.profile(g.ProfilePath),
+		})
+	}
+
+	still := images.still(resp.StillPath)
 	d := EpisodeDetails{
 		ID:          fmt.Sprintf("%v", resp.ID),
 		Title:       fmt.Sprintf("%v", resp.Name),
 		Description: fmt.Sprintf("%v", resp.Overview),
-		URL:         fmt.Sprintf("https://image.tmdb.org/t/p/w185%v", resp.StillPath),
+		URL:         imageURL(still),
+		Still:       still,
+		VoteAverage: resp.VoteAverage,
+		VoteCount:   resp.VoteCount,
+		GuestStars:  guests,
 		Sources:     epSrc,
 	}
 
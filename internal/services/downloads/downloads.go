@@ -0,0 +1,81 @@
+package downloads
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/s3/downloads"
+	"github.com/segmentio/ksuid"
+)
+
+//Link is where a file can be downloaded from until ExpiresAt
+type Link struct {
+	URL       string `json:"url"`
+	ExpiresAt int64  `json:"expiresAt"`
+}
+
+type Downloads struct {
+	s store
+}
+
+func NewDownloadsService(region, bucket string) (*Downloads, error) {
+	var s store
+	s, err := downloads.NewStore(region, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Downloads{
+		s: s,
+	}, nil
+}
+
+//Write uploads whatever write produces as the owner's file and links to it. It is streamed through
+//a pipe, so a file is never held in memory and isn't limited by the size of a lambda response.
+func (d Downloads) Write(ctx context.Context, owner, name, contentType string, gzipped bool, expires time.Duration, write func(w io.Writer) error) (*Link, error) {
+	f := downloads.File{
+		Key:         fmt.Sprintf("%s/%s/%s", owner, ksuid.New().String(), name),
+		Name:        name,
+		ContentType: contentType,
+	}
+	if gzipped {
+		f.Encoding = "gzip"
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		var w io.Writer = pw
+		var gz *gzip.Writer
+		if gzipped {
+			gz = gzip.NewWriter(pw)
+			w = gz
+		}
+
+		err := write(w)
+		if err == nil && gz != nil {
+			err = gz.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	err := d.s.Upload(ctx, f, pr)
+	//stops the writer if the upload gave up part way
+	pr.CloseWithError(err)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	u, err := d.s.DownloadURL(f.Key, expires)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Link{
+		URL:       u,
+		ExpiresAt: now.Add(expires).Unix(),
+	}, nil
+}
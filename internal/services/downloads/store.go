@@ -0,0 +1,14 @@
+package downloads
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/s3/downloads"
+)
+
+type store interface {
+	DownloadURL(key string, expires time.Duration) (string, error)
+	Upload(ctx context.Context, f downloads.File, body io.Reader) error
+}
@@ -0,0 +1,180 @@
+package groups
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/groups"
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/services/content"
+)
+
+//rankedCandidates leaves room for the provider and parental filters to drop titles and still fill the vote
+const rankedCandidates = MaxCandidates * 4
+
+//RankCandidates draws titles from the members' libraries, the ones in everyone's library first and
+//then those in the most libraries. A lone member's whole library counts, otherwise a title has
+//to be in at least two. Titles every member has already watched are left out.
+func RankCandidates(profiles []*users.UserProfile) []groups.Candidate {
+	counts := make(map[string]int)
+	watched := make(map[string]int)
+	order := make([]groups.Candidate, 0)
+	for _, p := range profiles {
+		inLibrary := make(map[string]bool)
+		for _, c := range p.Library.ContentList {
+			key := candidateKey(c.Type, c.ID)
+			if inLibrary[key] {
+				continue
+			}
+			inLibrary[key] = true
+
+			if counts[key] == 0 {
+				order = append(order, groups.Candidate{Type: c.Type, ID: c.ID})
+			}
+			counts[key]++
+		}
+
+		seen := make(map[string]bool)
+		for _, w := range p.WatchHistory {
+			key := candidateKey(w.Type, w.ID)
+			if !seen[key] {
+				seen[key] = true
+				watched[key]++
+			}
+		}
+	}
+
+	least := 2
+	if len(profiles) < 2 {
+		least = 1
+	}
+
+	ranked := make([]groups.Candidate, 0)
+	for _, c := range order {
+		key := candidateKey(c.Type, c.ID)
+		if counts[key] < least || watched[key] == len(profiles) {
+			continue
+		}
+
+		c.Libraries = counts[key]
+		ranked = append(ranked, c)
+	}
+
+	//stable so titles in as many libraries keep the order they were first added in
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Libraries > ranked[j].Libraries
+	})
+
+	if len(ranked) > rankedCandidates {
+		ranked = ranked[:rankedCandidates]
+	}
+
+	return ranked
+}
+
+//SubscribedProviders is every service someone in the group subscribes to, from their StreamAccounts
+func SubscribedProviders(profiles []*users.UserProfile) map[int64]bool {
+	subscribed := make(map[int64]bool)
+	for _, p := range profiles {
+		for _, a := range p.StreamAccounts {
+			subscribed[a.ID] = true
+		}
+	}
+	return subscribed
+}
+
+//FilterByProviders keeps candidates the group can stream on a service one of them subscribes to.
+//Titles whose providers couldn't be looked up are kept, and nothing is dropped when nobody in the
+//group has told us their services.
+func FilterByProviders(candidates []groups.Candidate, providers map[string][]int64, subscribed map[int64]bool) []groups.Candidate {
+	if len(subscribed) == 0 {
+		return candidates
+	}
+
+	filtered := make([]groups.Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		IDs, ok := providers[candidateKey(c.Type, c.ID)]
+		if !ok {
+			filtered = append(filtered, c)
+			continue
+		}
+
+		c.Providers = make([]int64, 0)
+		for _, ID := range IDs {
+			if subscribed[ID] {
+				c.Providers = append(c.Providers, ID)
+			}
+		}
+
+		if len(c.Providers) > 0 {
+			filtered = append(filtered, c)
+		}
+	}
+
+	return filtered
+}
+
+//CandidateKeys are the TitleKeys of the candidates, for the content lookups
+func CandidateKeys(candidates []groups.Candidate) []string {
+	keys := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		keys = append(keys, candidateKey(c.Type, c.ID))
+	}
+	return keys
+}
+
+//Helpers
+
+func candidateKey(mediaType string, ID int64) string {
+	return content.TitleKey(mediaType, strconv.FormatInt(ID, 10))
+}
+
+func hasCandidate(candidates []groups.Candidate, key string) bool {
+	for _, c := range candidates {
+		if candidateKey(c.Type, c.ID) == key {
+			return true
+		}
+	}
+	return false
+}
+
+func tally(candidates []groups.Candidate, votes []groups.Vote) []Tally {
+	tallies := make([]Tally, 0, len(candidates))
+	for _, c := range candidates {
+		t := Tally{Type: c.Type, ID: c.ID}
+		key := candidateKey(c.Type, c.ID)
+		for _, v := range votes {
+			if v.ContentKey != key {
+				continue
+			}
+
+			if v.Value > 0 {
+				t.Yes++
+			} else {
+				t.No++
+			}
+		}
+		tallies = append(tallies, t)
+	}
+	return tallies
+}
+
+//pick is the candidate with the most yes votes over no votes, ties go to the one with more yes
+//votes and then to the one in more libraries, candidates are already in that order
+func pick(candidates []groups.Candidate, votes []groups.Vote) *groups.Candidate {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	tallies := tally(candidates, votes)
+	best := 0
+	for i, t := range tallies {
+		b := tallies[best]
+		if t.Yes-t.No > b.Yes-b.No || (t.Yes-t.No == b.Yes-b.No && t.Yes > b.Yes) {
+			best = i
+		}
+	}
+
+	c := candidates[best]
+	return &c
+}
@@ -0,0 +1,533 @@
+package groups
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/groups"
+	"github.com/segmentio/ksuid"
+)
+
+const (
+	StatusOpen     = "open"
+	StatusVoting   = "voting"
+	StatusResolved = "resolved"
+
+	//sessions are for deciding tonight, everything about one is gone after this
+	SessionTTL    = 6 * time.Hour
+	MaxMembers    = 12
+	MaxCandidates = 10
+
+	codeLength = 6
+	//no 0/O or 1/I so codes can be read out across a room
+	codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	codeAttempts = 5
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrNotHost         = errors.New("only the host can do that")
+	ErrSessionFull     = errors.New("session has the maximum number of members")
+	ErrSessionStarted  = errors.New("session has already started voting")
+	ErrNotVoting       = errors.New("session is not voting")
+	ErrNoCandidates    = errors.New("no titles to vote on")
+	ErrNotCandidate    = errors.New("title is not a candidate")
+	ErrInvalidVote     = errors.New("vote must be 1 or -1")
+	ErrCodeUnavailable = errors.New("failed to find a free join code")
+)
+
+//State is a session as a member sees it, Votes are only the member's own
+type State struct {
+	Session *groups.Session `json:"session"`
+	Members []groups.Member `json:"members"`
+	Tallies []Tally         `json:"tallies,omitempty"`
+	Votes   []groups.Vote   `json:"votes,omitempty"`
+}
+
+//Tally is the votes on one candidate so far
+type Tally struct {
+	Type string `json:"type"`
+	ID   int64  `json:"id"`
+	Yes  int    `json:"yes"`
+	No   int    `json:"no"`
+}
+
+type Groups struct {
+	s store
+}
+
+func NewGroupsService(conn, region string) (*Groups, error) {
+	var s store
+	s, err := groups.NewStore(conn, region)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Groups{
+		s: s,
+	}, nil
+}
+
+//CreateSession starts a session with the host as its first member
+func (g Groups) CreateSession(ctx context.Context, accountID, profileID, name string) (*groups.Session, error) {
+	code, err := g.freeCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := groups.Session{
+		ID:            ksuid.New().String(),
+		Code:          code,
+		HostID:        profileID,
+		HostAccountID: accountID,
+		Status:        StatusOpen,
+		CreatedAt:     now.Unix(),
+		ExpiresAt:     now.Add(SessionTTL).Unix(),
+	}
+
+	err = g.s.CreateSession(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	err = g.addMember(ctx, &session, accountID, profileID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return publicSession(session), nil
+}
+
+//JoinSession only lets members in before voting starts, the candidates are drawn from who is there.
+//Joining again is a no-op.
+func (g Groups) JoinSession(ctx context.Context, code, accountID, profileID, name string) (*groups.Session, error) {
+	session, err := g.findByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := g.s.FindMemberByID(ctx, memberID(session.ID, profileID))
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return publicSession(*session), nil
+	}
+
+	if session.Status != StatusOpen {
+		return nil, ErrSessionStarted
+	}
+
+	members, err := g.s.FindMembersBySessionID(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(*members) >= MaxMembers {
+		return nil, ErrSessionFull
+	}
+
+	err = g.addMember(ctx, session, accountID, profileID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return publicSession(*session), nil
+}
+
+//FindSession is for members only, anyone else gets ErrSessionNotFound
+func (g Groups) FindSession(ctx context.Context, profileID, ID string) (*State, error) {
+	session, members, err := g.findAsMember(ctx, profileID, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	votes, err := g.memberVotes(ctx, session.ID, members)
+	if err != nil {
+		return nil, err
+	}
+
+	own := make([]groups.Vote, 0)
+	for _, v := range votes {
+		if v.ProfileID == profileID {
+			v.AccountID = ""
+			own = append(own, v)
+		}
+	}
+
+	for i := range members {
+		members[i].AccountID = ""
+	}
+
+	return &State{
+		Session: publicSession(*session),
+		Members: members,
+		Tallies: tally(session.Candidates, votes),
+		Votes:   own,
+	}, nil
+}
+
+//FindMembers is who is in the session, for the host to draw candidates from
+func (g Groups) FindMembers(ctx context.Context, profileID, ID string) ([]groups.Member, error) {
+	session, members, err := g.findAsMember(ctx, profileID, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.HostID != profileID {
+		return nil, ErrNotHost
+	}
+
+	return members, nil
+}
+
+//StartVoting closes the session to new members and puts the candidates to the vote
+func (g Groups) StartVoting(ctx context.Context, profileID, ID string, candidates []groups.Candidate) (*groups.Session, error) {
+	session, _, err := g.findAsMember(ctx, profileID, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.HostID != profileID {
+		return nil, ErrNotHost
+	}
+
+	if session.Status != StatusOpen {
+		return nil, ErrSessionStarted
+	}
+
+	if len(candidates) == 0 {
+		return nil, ErrNoCandidates
+	}
+
+	if len(candidates) > MaxCandidates {
+		candidates = candidates[:MaxCandidates]
+	}
+
+	session.Status = StatusVoting
+	session.Candidates = candidates
+	err = g.s.CreateSession(ctx, *session)
+	if err != nil {
+		return nil, err
+	}
+
+	return publicSession(*session), nil
+}
+
+//Vote records a member's yes (1) or no (-1) on a candidate, voting again changes it. The session
+//resolves on its own once every member has voted on every candidate.
+func (g Groups) Vote(ctx context.Context, accountID, profileID, ID, mediaType string, tmdbID int64, value int) (*groups.Session, error) {
+	if value != 1 && value != -1 {
+		return nil, ErrInvalidVote
+	}
+
+	session, members, err := g.findAsMember(ctx, profileID, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Status != StatusVoting {
+		return nil, ErrNotVoting
+	}
+
+	key := candidateKey(mediaType, tmdbID)
+	if !hasCandidate(session.Candidates, key) {
+		return nil, ErrNotCandidate
+	}
+
+	err = g.s.CreateVote(ctx, groups.Vote{
+		ID:         voteID(session.ID, profileID, key),
+		SessionID:  session.ID,
+		ProfileID:  profileID,
+		AccountID:  accountID,
+		ContentKey: key,
+		Value:      value,
+		CreatedAt:  time.Now().Unix(),
+		ExpiresAt:  session.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	votes, err := g.memberVotes(ctx, session.ID, members)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(votes) < len(members)*len(session.Candidates) {
+		return publicSession(*session), nil
+	}
+
+	return g.resolve(ctx, session, votes)
+}
+
+//Resolve lets the host end voting early with the votes so far
+func (g Groups) Resolve(ctx context.Context, profileID, ID string) (*groups.Session, error) {
+	session, members, err := g.findAsMember(ctx, profileID, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.HostID != profileID {
+		return nil, ErrNotHost
+	}
+
+	if session.Status == StatusResolved {
+		return publicSession(*session), nil
+	}
+
+	if session.Status != StatusVoting {
+		return nil, ErrNotVoting
+	}
+
+	votes, err := g.memberVotes(ctx, session.ID, members)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.resolve(ctx, session, votes)
+}
+
+//LeaveSession takes back the member's votes, the host leaving ends the session for everyone
+func (g Groups) LeaveSession(ctx context.Context, profileID, ID string) error {
+	session, _, err := g.findAsMember(ctx, profileID, ID)
+	if err != nil {
+		return err
+	}
+
+	if session.HostID == profileID {
+		return g.removeSession(ctx, session.ID)
+	}
+
+	votes, err := g.s.FindVotesBySessionID(ctx, session.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range *votes {
+		if v.ProfileID != profileID {
+			continue
+		}
+
+		err = g.s.RemoveVoteByID(ctx, v.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return g.s.RemoveMemberByID(ctx, memberID(session.ID, profileID))
+}
+
+//RemoveUserData deletes the sessions the account hosted and its profiles' memberships and votes
+//elsewhere when it is purged
+func (g Groups) RemoveUserData(ctx context.Context, accountID string) error {
+	sessions, err := g.s.FindSessionsByHostAccountID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range *sessions {
+		err = g.removeSession(ctx, session.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	votes, err := g.s.FindVotesByAccountID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range *votes {
+		err = g.s.RemoveVoteByID(ctx, v.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	members, err := g.s.FindMembersByAccountID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range *members {
+		err = g.s.RemoveMemberByID(ctx, m.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//Helpers
+
+func memberID(sessionID, profileID string) string {
+	return sessionID + "#" + profileID
+}
+
+func voteID(sessionID, profileID, key string) string {
+	return sessionID + "#" + profileID + "#" + key
+}
+
+//sessions shown to members don't carry the host's account
+func publicSession(session groups.Session) *groups.Session {
+	session.HostAccountID = ""
+	return &session
+}
+
+func expired(session *groups.Session) bool {
+	return session.ExpiresAt <= time.Now().Unix()
+}
+
+func (g Groups) addMember(ctx context.Context, session *groups.Session, accountID, profileID, name string) error {
+	return g.s.CreateMember(ctx, groups.Member{
+		ID:        memberID(session.ID, profileID),
+		SessionID: session.ID,
+		ProfileID: profileID,
+		AccountID: accountID,
+		Name:      name,
+		JoinedAt:  time.Now().Unix(),
+		ExpiresAt: session.ExpiresAt,
+	})
+}
+
+//findAsMember reads a session the profile is in, the ttl can take a while so expiry is checked here
+func (g Groups) findAsMember(ctx context.Context, profileID, ID string) (*groups.Session, []groups.Member, error) {
+	session, err := g.s.FindSessionByID(ctx, ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if session == nil || expired(session) {
+		return nil, nil, ErrSessionNotFound
+	}
+
+	members, err := g.s.FindMembersBySessionID(ctx, ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, m := range *members {
+		if m.ProfileID == profileID {
+			return session, *members, nil
+		}
+	}
+
+	return nil, nil, ErrSessionNotFound
+}
+
+func (g Groups) findByCode(ctx context.Context, code string) (*groups.Session, error) {
+	sessions, err := g.s.FindSessionsByCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, session := range *sessions {
+		if !expired(&session) {
+			return &session, nil
+		}
+	}
+
+	return nil, ErrSessionNotFound
+}
+
+//freeCode picks a code no live session is using
+func (g Groups) freeCode(ctx context.Context) (string, error) {
+	for i := 0; i < codeAttempts; i++ {
+		code, err := newCode()
+		if err != nil {
+			return "", err
+		}
+
+		_, err = g.findByCode(ctx, code)
+		if err == ErrSessionNotFound {
+			return code, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return "", ErrCodeUnavailable
+}
+
+func newCode() (string, error) {
+	code := make([]byte, codeLength)
+	max := big.NewInt(int64(len(codeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = codeAlphabet[n.Int64()]
+	}
+
+	return string(code), nil
+}
+
+//memberVotes leaves out the votes of anyone who has since left
+func (g Groups) memberVotes(ctx context.Context, sessionID string, members []groups.Member) ([]groups.Vote, error) {
+	all, err := g.s.FindVotesBySessionID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	in := make(map[string]bool)
+	for _, m := range members {
+		in[m.ProfileID] = true
+	}
+
+	votes := make([]groups.Vote, 0, len(*all))
+	for _, v := range *all {
+		if in[v.ProfileID] {
+			votes = append(votes, v)
+		}
+	}
+
+	return votes, nil
+}
+
+func (g Groups) resolve(ctx context.Context, session *groups.Session, votes []groups.Vote) (*groups.Session, error) {
+	session.Pick = pick(session.Candidates, votes)
+	session.Status = StatusResolved
+	session.ResolvedAt = time.Now().Unix()
+
+	err := g.s.CreateSession(ctx, *session)
+	if err != nil {
+		return nil, err
+	}
+
+	return publicSession(*session), nil
+}
+
+func (g Groups) removeSession(ctx context.Context, ID string) error {
+	votes, err := g.s.FindVotesBySessionID(ctx, ID)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range *votes {
+		err = g.s.RemoveVoteByID(ctx, v.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	members, err := g.s.FindMembersBySessionID(ctx, ID)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range *members {
+		err = g.s.RemoveMemberByID(ctx, m.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return g.s.RemoveSessionByID(ctx, ID)
+}
@@ -0,0 +1,24 @@
+package groups
+
+import (
+	"context"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/groups"
+)
+
+type store interface {
+	CreateMember(ctx context.Context, m groups.Member) error
+	CreateSession(ctx context.Context, session groups.Session) error
+	CreateVote(ctx context.Context, v groups.Vote) error
+	FindMemberByID(ctx context.Context, ID string) (*groups.Member, error)
+	FindMembersByAccountID(ctx context.Context, accountID string) (*[]groups.Member, error)
+	FindMembersBySessionID(ctx context.Context, sessionID string) (*[]groups.Member, error)
+	FindSessionByID(ctx context.Context, ID string) (*groups.Session, error)
+	FindSessionsByCode(ctx context.Context, code string) (*[]groups.Session, error)
+	FindSessionsByHostAccountID(ctx context.Context, accountID string) (*[]groups.Session, error)
+	FindVotesByAccountID(ctx context.Context, accountID string) (*[]groups.Vote, error)
+	FindVotesBySessionID(ctx context.Context, sessionID string) (*[]groups.Vote, error)
+	RemoveMemberByID(ctx context.Context, ID string) error
+	RemoveSessionByID(ctx context.Context, ID string) error
+	RemoveVoteByID(ctx context.Context, ID string) error
+}
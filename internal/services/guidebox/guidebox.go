@@ -223,28 +223,46 @@ func (g GuideBox) SearchByIDAndType(ctx context.Context, t, q string) (string, e
 }
 
 func (g GuideBox) FindMovieSources(ctx context.Context, ID string) (*[]Source, error) {
+	resp, err := g.FindMovieDetails(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := resp.AndroidSources()
+	return &sources, nil
+}
+
+func (r MovieDetailsResponse) AndroidSources() []Source {
+	sources := make([]Source, 0)
+	sources = append(sources, r.FreeAndroidSources...)
+	sources = append(sources, r.SubscriptionAndroidSources...)
+	sources = append(sources, r.PurchaseAndroidSources...)
+
+	return sources
+}
+
+//FindMovieDetails is the whole GuideBox record, including the MPAA Rating and CommonSenseMedia age
+func (g GuideBox) FindMovieDetails(ctx context.Context, ID string) (*MovieDetailsResponse, error) {
 	url := fmt.Sprintf("http://api-public.guidebox.com/v2/movies/%s?api_key=%s", ID, g.ApiKey)
 	payload := strings.NewReader("{}")
 
 	req, _ := http.NewRequest("GET", url, payload)
 
-	res, _ := http.DefaultClient.Do(req)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
 
 	defer res.Body.Close()
 	body, _ := ioutil.ReadAll(res.Body)
 
 	var resp MovieDetailsResponse
-	err := json.Unmarshal(body, &resp)
+	err = json.Unmarshal(body, &resp)
 	if err != nil {
 		return nil, err
 	}
 
-	sources := make([]Source, 0)
-	sources = append(sources, resp.FreeAndroidSources...)
-	sources = append(sources, resp.SubscriptionAndroidSources...)
-	sources = append(sources, resp.PurchaseAndroidSources...)
-
-	return &sources, nil
+	return &resp, nil
 }
 
 func (g GuideBox) FindEpisodeDetails(ctx context.Context, ID, seasonNum string) (*SeasonSources, error) {
@@ -0,0 +1,51 @@
+package notifications
+
+import (
+	"context"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/notifications"
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+)
+
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, r notifications.Recipient, events []notifications.Event) error
+}
+
+type Subscriber struct {
+	Recipient notifications.Recipient
+	Channels  []string
+	Digest    bool
+}
+
+//ProfileFinder is the part of the users service deliveries need to find where to send
+type ProfileFinder interface {
+	FindUserProfileByID(ctx context.Context, ID string) (*users.UserProfile, error)
+}
+
+//SubscriberFor is the profile as Publish wants it, with the channels and digest setting they chose
+func SubscriberFor(prof *users.UserProfile) Subscriber {
+	return Subscriber{
+		Recipient: RecipientFor(prof),
+		Channels:  prof.Notifications.Channels,
+		Digest:    prof.Notifications.Digest,
+	}
+}
+
+//RecipientFor is where the profile's notifications go, the account owner's profile has no AccountID
+//and shares the account's
+func RecipientFor(prof *users.UserProfile) notifications.Recipient {
+	accountID := prof.AccountID
+	if accountID == "" {
+		accountID = prof.ID
+	}
+
+	return notifications.Recipient{
+		UserID:        prof.ID,
+		AccountID:     accountID,
+		Name:          prof.Name,
+		Email:         prof.Email,
+		WebhookURL:    prof.Notifications.WebhookURL,
+		WebhookSecret: prof.Notifications.WebhookSecret,
+	}
+}
@@ -0,0 +1,158 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/notifications"
+)
+
+const MaxAttempts = 5
+
+//DeadDeliveryRetention is how long a delivery that gave up is kept before DynamoDB expires it
+const DeadDeliveryRetention = 7 * 24 * time.Hour
+
+type DeliveryReport struct {
+	Sent   int `json:"sent"`
+	Failed int `json:"failed"`
+	Dead   int `json:"dead"`
+}
+
+//ProcessDeliveries sends every due delivery on the immediate channels, failures are retried with backoff
+func (n Notifications) ProcessDeliveries(ctx context.Context, profiles ProfileFinder, now time.Time) (*DeliveryReport, error) {
+	report := DeliveryReport{}
+	recipients := make(map[string]*notifications.Recipient)
+	for _, name := range []string{ChannelEmail, ChannelWebhook} {
+		ch, ok := n.channels[name]
+		if !ok {
+			continue
+		}
+
+		due, err := n.s.FindDueDeliveries(ctx, name, now.Unix())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, d := range *due {
+			r, err := findRecipient(ctx, profiles, recipients, d.UserID)
+			if err == nil && r == nil {
+				//the profile is gone, there is nobody left to tell
+				err = n.s.RemoveDeliveryByID(ctx, d.ID)
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if err == nil {
+				err = ch.Send(ctx, *r, []notifications.Event{d.Event})
+			}
+
+			err = n.recordAttempt(ctx, d, err, now, &report)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &report, nil
+}
+
+//SendDigests batches each user's pending digest deliveries into a single email
+func (n Notifications) SendDigests(ctx context.Context, profiles ProfileFinder, now time.Time) (*DeliveryReport, error) {
+	ch, ok := n.channels[ChannelEmail]
+	if !ok {
+		return nil, fmt.Errorf("email channel is not registered")
+	}
+
+	due, err := n.s.FindDueDeliveries(ctx, ChannelDigest, now.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	byUser := make(map[string][]notifications.Delivery)
+	for _, d := range *due {
+		byUser[d.UserID] = append(byUser[d.UserID], d)
+	}
+
+	report := DeliveryReport{}
+	recipients := make(map[string]*notifications.Recipient)
+	for userID, batch := range byUser {
+		r, sendErr := findRecipient(ctx, profiles, recipients, userID)
+		if sendErr == nil && r == nil {
+			//the profile is gone, there is nobody left to tell
+			for _, d := range batch {
+				err = n.s.RemoveDeliveryByID(ctx, d.ID)
+				if err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		if sendErr == nil {
+			events := make([]notifications.Event, 0)
+			for _, d := range batch {
+				events = append(events, d.Event)
+			}
+			sendErr = ch.Send(ctx, *r, events)
+		}
+
+		for _, d := range batch {
+			err = n.recordAttempt(ctx, d, sendErr, now, &report)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &report, nil
+}
+
+//Helpers
+
+//findRecipient reads the profile once per run, so the current address and webhook are used and
+//the secret never has to be stored with the delivery. It is nil when the profile no longer exists.
+func findRecipient(ctx context.Context, profiles ProfileFinder, found map[string]*notifications.Recipient, userID string) (*notifications.Recipient, error) {
+	if r, ok := found[userID]; ok {
+		return r, nil
+	}
+
+	prof, err := profiles.FindUserProfileByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var r *notifications.Recipient
+	if prof != nil && prof.ID != "" {
+		recipient := RecipientFor(prof)
+		r = &recipient
+	}
+
+	found[userID] = r
+	return r, nil
+}
+
+//recordAttempt removes sent deliveries, nothing needs them once the event is delivered
+func (n Notifications) recordAttempt(ctx context.Context, d notifications.Delivery, sendErr error, now time.Time, report *DeliveryReport) error {
+	d.Attempts++
+	d.UpdatedAt = now.Unix()
+
+	switch {
+	case sendErr == nil:
+		report.Sent++
+		return n.s.RemoveDeliveryByID(ctx, d.ID)
+	case d.Attempts >= MaxAttempts:
+		d.Status = notifications.DeliveryDead
+		d.LastError = sendErr.Error()
+		d.ExpiresAt = now.Add(DeadDeliveryRetention).Unix()
+		report.Dead++
+	default:
+		//back off 2, 4, 8, 16 minutes
+		d.NextAttemptAt = now.Add(time.Duration(1<<uint(d.Attempts)) * time.Minute).Unix()
+		d.LastError = sendErr.Error()
+		report.Failed++
+	}
+
+	return n.s.UpdateDelivery(ctx, d)
+}
@@ -0,0 +1,132 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmlTemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	textTemplate "text/template"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/notifications"
+)
+
+type EmailChannel struct {
+	Addr     string
+	Host     string
+	Username string
+	Password string
+	From     string
+	html     *htmlTemplate.Template
+	text     *textTemplate.Template
+}
+
+type emailData struct {
+	Name          string
+	Events        []notifications.Event
+	Transactional bool
+}
+
+//NewEmailChannel sends over plain SMTP, leave the username empty for servers that don't authenticate such as the local stand-in
+func NewEmailChannel(host string, port int, username, password, from string) (*EmailChannel, error) {
+	h, err := htmlTemplate.New("html").Parse(htmlEmailTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := textTemplate.New("text").Parse(textEmailTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EmailChannel{
+		Addr:     fmt.Sprintf("%s:%d", host, port),
+		Host:     host,
+		Username: username,
+		Password: password,
+		From:     from,
+		html:     h,
+		text:     t,
+	}, nil
+}
+
+func (e EmailChannel) Name() string {
+	return ChannelEmail
+}
+
+func (e EmailChannel) Send(ctx context.Context, r notifications.Recipient, events []notifications.Event) error {
+	if r.Email == "" {
+		return fmt.Errorf("recipient %s has no email address", r.UserID)
+	}
+
+	msg, err := e.buildMessage(r, events)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if e.Username != "" {
+		auth = smtp.PlainAuth("", e.Username, e.Password, e.Host)
+	}
+
+	return smtp.SendMail(e.Addr, auth, e.From, []string{r.Email}, msg)
+}
+
+//Helpers
+
+func (e EmailChannel) buildMessage(r notifications.Recipient, events []notifications.Event) ([]byte, error) {
+	subject := fmt.Sprintf("Your daily digest: %d updates", len(events))
+	if len(events) == 1 {
+		subject = events[0].Title
+	}
+
+	data := emailData{
+		Name:          r.Name,
+		Events:        events,
+		Transactional: len(events) == 1 && isTransactional(events[0].Type),
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	part, err := w.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=UTF-8"}})
+	if err != nil {
+		return nil, err
+	}
+	err = e.text.Execute(part, data)
+	if err != nil {
+		return nil, err
+	}
+
+	part, err = w.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/html; charset=UTF-8"}})
+	if err != nil {
+		return nil, err
+	}
+	err = e.html.Execute(part, data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", r.Email)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%q\r\n", w.Boundary())
+	fmt.Fprintf(&msg, "\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
+
+func isTransactional(typ string) bool {
+	return typ == EmailVerification || typ == PasswordReset
+}
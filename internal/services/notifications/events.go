@@ -0,0 +1,164 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/notifications"
+	"github.com/segmentio/ksuid"
+)
+
+//Publish records the event and queues a delivery on each of the subscriber's channels
+func (n Notifications) Publish(ctx context.Context, sub Subscriber, typ, title, message string, data map[string]string) error {
+	now := time.Now().Unix()
+	e := notifications.Event{
+		ID:        ksuid.New().String(),
+		UserID:    sub.Recipient.UserID,
+		AccountID: sub.Recipient.AccountID,
+		Type:      typ,
+		Title:     title,
+		Message:   message,
+		Data:      data,
+		CreatedAt: now,
+	}
+
+	err := n.s.CreateEvent(ctx, e)
+	if err != nil {
+		return err
+	}
+
+	for _, ch := range sub.Channels {
+		if ch == ChannelEmail && sub.Digest {
+			ch = ChannelDigest
+		}
+
+		if ch == ChannelWebhook && sub.Recipient.WebhookURL == "" {
+			continue
+		}
+
+		err = n.s.CreateDelivery(ctx, notifications.Delivery{
+			ID:            ksuid.New().String(),
+			Channel:       ch,
+			Status:        notifications.DeliveryPending,
+			NextAttemptAt: now,
+			UserID:        sub.Recipient.UserID,
+			AccountID:     sub.Recipient.AccountID,
+			Event:         e,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n Notifications) PublishEpisodeAired(ctx context.Context, sub Subscriber, showID, showTitle string, season, episode int, airDate string) error {
+	title := fmt.Sprintf("New episode of %s", showTitle)
+	message := fmt.Sprintf("Season %d Episode %d aired on %s", season, episode, airDate)
+	data := map[string]string{
+		"showId":         showID,
+		"season_number":  fmt.Sprintf("%d", season),
+		"episode_number": fmt.Sprintf("%d", episode),
+		"airDate":        airDate,
+	}
+	return n.Publish(ctx, sub, EpisodeAired, title, message, data)
+}
+
+//PublishPartyEvent is for invites, changes, cancellations and reminders of a watch party
+func (n Notifications) PublishPartyEvent(ctx context.Context, sub Subscriber, typ, partyID, title, message string, startsAt int64) error {
+	data := map[string]string{
+		"partyId":  partyID,
+		"startsAt": time.Unix(startsAt, 0).UTC().Format(time.RFC3339),
+	}
+	return n.Publish(ctx, sub, typ, title, message, data)
+}
+
+//SendTransactional emails the recipient straight away without queueing, because the event carries a secret
+//that must not be written to the notifications tables
+func (n Notifications) SendTransactional(ctx context.Context, r notifications.Recipient, typ, title, message, url string) error {
+	ch, ok := n.channels[ChannelEmail]
+	if !ok {
+		return fmt.Errorf("email channel is not registered")
+	}
+
+	e := notifications.Event{
+		ID:        ksuid.New().String(),
+		UserID:    r.UserID,
+		Type:      typ,
+		Title:     title,
+		Message:   message,
+		Data:      map[string]string{"url": url},
+		CreatedAt: time.Now().Unix(),
+	}
+
+	return ch.Send(ctx, r, []notifications.Event{e})
+}
+
+func (n Notifications) SendEmailVerification(ctx context.Context, r notifications.Recipient, url string) error {
+	return n.SendTransactional(ctx, r, EmailVerification, "Verify your email address", "Confirm this is your email address by opening the link below. It expires in 48 hours.", url)
+}
+
+func (n Notifications) SendPasswordReset(ctx context.Context, r notifications.Recipient, url string) error {
+	return n.SendTransactional(ctx, r, PasswordReset, "Reset your password", "Someone asked to reset the password for your account. If it was you, open the link below within the next hour, otherwise you can ignore this email.", url)
+}
+
+//FindEventsByAccountID is the notifications of every profile on the account
+func (n Notifications) FindEventsByAccountID(ctx context.Context, accountID string) (*[]notifications.Event, error) {
+	return n.s.FindEventsByAccountID(ctx, accountID)
+}
+
+//RemoveProfileData deletes a removed household profile's notifications and deliveries
+func (n Notifications) RemoveProfileData(ctx context.Context, profileID string) error {
+	deliveries, err := n.s.FindDeliveriesByUserID(ctx, profileID)
+	if err != nil {
+		return err
+	}
+
+	events, err := n.s.FindEventsByUserID(ctx, profileID)
+	if err != nil {
+		return err
+	}
+
+	return n.remove(ctx, *deliveries, *events)
+}
+
+//RemoveUserData deletes the notifications of every profile on the account and any deliveries still
+//queued or kept for them
+func (n Notifications) RemoveUserData(ctx context.Context, accountID string) error {
+	deliveries, err := n.s.FindDeliveriesByAccountID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	events, err := n.s.FindEventsByAccountID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	return n.remove(ctx, *deliveries, *events)
+}
+
+//Helpers
+
+//deliveries go first so none is left pointing at a removed event
+func (n Notifications) remove(ctx context.Context, deliveries []notifications.Delivery, events []notifications.Event) error {
+	for _, d := range deliveries {
+		err := n.s.RemoveDeliveryByID(ctx, d.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, e := range events {
+		err := n.s.RemoveEventByID(ctx, e.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
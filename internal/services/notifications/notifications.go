@@ -0,0 +1,44 @@
+package notifications
+
+import (
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/notifications"
+)
+
+const (
+	EpisodeAired      = "episode.aired"
+	EmailVerification = "account.verify_email"
+	PasswordReset     = "account.password_reset"
+	PartyInvite       = "party.invite"
+	PartyChanged      = "party.changed"
+	PartyCancelled    = "party.cancelled"
+	PartyReminder     = "party.reminder"
+)
+
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelDigest  = "digest"
+)
+
+type Notifications struct {
+	s        store
+	channels map[string]Channel
+}
+
+func NewNotificationsService(conn, region string) (*Notifications, error) {
+	var s store
+	s, err := notifications.NewStore(conn, region)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Notifications{
+		s:        s,
+		channels: make(map[string]Channel),
+	}, nil
+}
+
+//RegisterChannel makes a channel available for delivery, only the delivery lambdas need to register any
+func (n Notifications) RegisterChannel(c Channel) {
+	n.channels[c.Name()] = c
+}
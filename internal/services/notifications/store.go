@@ -0,0 +1,20 @@
+package notifications
+
+import (
+	"context"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/notifications"
+)
+
+type store interface {
+	CreateDelivery(ctx context.Context, delivery notifications.Delivery) error
+	CreateEvent(ctx context.Context, event notifications.Event) error
+	FindDeliveriesByAccountID(ctx context.Context, accountID string) (*[]notifications.Delivery, error)
+	FindDeliveriesByUserID(ctx context.Context, userID string) (*[]notifications.Delivery, error)
+	FindDueDeliveries(ctx context.Context, channel string, now int64) (*[]notifications.Delivery, error)
+	FindEventsByAccountID(ctx context.Context, accountID string) (*[]notifications.Event, error)
+	FindEventsByUserID(ctx context.Context, userID string) (*[]notifications.Event, error)
+	RemoveDeliveryByID(ctx context.Context, ID string) error
+	RemoveEventByID(ctx context.Context, ID string) error
+	UpdateDelivery(ctx context.Context, delivery notifications.Delivery) error
+}
@@ -0,0 +1,26 @@
+package notifications
+
+const textEmailTemplate = `Hi {{.Name}},
+{{range .Events}}
+{{.Title}}
+{{.Message}}
+{{with index .Data "url"}}{{.}}
+{{end}}{{end}}
+{{if not .Transactional}}You are receiving this because notifications are turned on in your profile.
+{{end}}`
+
+const htmlEmailTemplate = `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif;">
+<p>Hi {{.Name}},</p>
+{{range .Events}}
+<div style="margin-bottom: 16px;">
+<h3 style="margin: 0;">{{.Title}}</h3>
+<p style="margin: 4px 0;">{{.Message}}</p>
+{{with index .Data "url"}}<p style="margin: 4px 0;"><a href="{{.}}">{{.}}</a></p>{{end}}
+</div>
+{{end}}
+{{if not .Transactional}}<p style="color: #888888; font-size: 12px;">You are receiving this because notifications are turned on in your profile.</p>{{end}}
+</body>
+</html>
+`
@@ -0,0 +1,89 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/notifications"
+	"github.com/aschles4/finalProject/internal/pkg/webhooks"
+)
+
+type WebhookChannel struct {
+	client *http.Client
+}
+
+type webhookPayload struct {
+	UserID string                `json:"userId"`
+	Events []notifications.Event `json:"events"`
+}
+
+func NewWebhookChannel() *WebhookChannel {
+	return &WebhookChannel{
+		client: webhooks.NewClient(10 * time.Second),
+	}
+}
+
+func (w WebhookChannel) Name() string {
+	return ChannelWebhook
+}
+
+//Send posts the events as JSON. Receivers verify X-Signature, the hex HMAC-SHA256 of "<timestamp>.<body>"
+//keyed with the secret from their profile, and should reject stale timestamps. Only public https
+//addresses are posted to, whatever the URL resolves to when it is sent.
+func (w WebhookChannel) Send(ctx context.Context, r notifications.Recipient, events []notifications.Event) error {
+	u, err := url.Parse(r.WebhookURL)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "https" {
+		return fmt.Errorf("webhook url for %s must use https", r.UserID)
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		UserID: r.UserID,
+		Events: events,
+	})
+	if err != nil {
+		return err
+	}
+
+	ts := fmt.Sprintf("%d", time.Now().Unix())
+
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Timestamp", ts)
+	req.Header.Set("X-Signature", "sha256="+Sign(r.WebhookSecret, ts, body))
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,63 @@
+package parties
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/parties"
+	"github.com/aschles4/finalProject/internal/pkg/ical"
+)
+
+const calendarDomain = "finalproject"
+
+//Calendar is a one event calendar for the party. The UID stays the same and the sequence goes up on
+//every change so calendar clients move or cancel the event they already have.
+func Calendar(party parties.Party) ical.Calendar {
+	start := time.Unix(party.StartsAt, 0)
+
+	summary := party.Name
+	if summary == "" {
+		summary = "Watch party: " + Title(party)
+	}
+
+	description := fmt.Sprintf("Watching %s with %s.", Title(party), party.HostName)
+	if party.Note != "" {
+		description += "\n\n" + party.Note
+	}
+
+	status := "CONFIRMED"
+	if party.Status == StatusCancelled {
+		status = "CANCELLED"
+	}
+
+	return ical.Calendar{
+		ProdID: "-//finalProject//Watch Parties//EN",
+		Name:   summary,
+		Events: []ical.Event{
+			{
+				UID:         fmt.Sprintf("party-%s@%s", party.ID, calendarDomain),
+				Summary:     summary,
+				Description: description,
+				Start:       start,
+				End:         start.Add(time.Duration(party.Duration) * time.Minute),
+				Stamp:       time.Unix(party.UpdatedAt, 0),
+				Sequence:    party.Sequence,
+				Status:      status,
+			},
+		},
+	}
+}
+
+//Title is what the party is watching, the TMDB ID stands in when the title couldn't be looked up
+func Title(party parties.Party) string {
+	title := party.ContentTitle
+	if title == "" {
+		title = fmt.Sprintf("%s %d", party.MediaType, party.TMDBID)
+	}
+
+	if party.Episode > 0 {
+		title = fmt.Sprintf("%s S%02dE%02d", title, party.Season, party.Episode)
+	}
+
+	return title
+}
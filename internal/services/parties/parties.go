@@ -0,0 +1,613 @@
+package parties
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/parties"
+	"github.com/aschles4/finalProject/internal/services/content"
+	"github.com/segmentio/ksuid"
+)
+
+const (
+	StatusScheduled = "scheduled"
+	StatusCancelled = "cancelled"
+
+	RSVPPending  = "pending"
+	RSVPGoing    = "going"
+	RSVPMaybe    = "maybe"
+	RSVPDeclined = "declined"
+
+	MaxInvitees     = 20
+	MaxPartyNameLen = 100
+	MaxPartyNoteLen = 1000
+	DefaultDuration = 120
+	MaxDuration     = 12 * 60
+	DefaultReminder = 30
+	MaxReminder     = 7 * 24 * 60
+	//parties are kept for a while after they start so guests can still find them
+	PartyRetention = 30 * 24 * time.Hour
+)
+
+var (
+	ErrPartyNotFound    = errors.New("party not found")
+	ErrNotHost          = errors.New("only the host can change a party")
+	ErrInvalidTarget    = errors.New("parties are for a movie or an episode of a show")
+	ErrStartInPast      = errors.New("party must start in the future")
+	ErrInvalidDuration  = errors.New("duration must be between 1 and 720 minutes")
+	ErrTooManyInvitees  = errors.New("party has the maximum number of invitees")
+	ErrPartyNameTooLong = errors.New("party name is too long")
+	ErrPartyNoteTooLong = errors.New("party note is too long")
+	ErrInvalidRSVP      = errors.New("rsvp must be going, maybe or declined")
+	ErrInvalidReminder  = errors.New("reminder must be between 0 and 10080 minutes")
+	ErrPartyCancelled   = errors.New("party has been cancelled")
+)
+
+//Guest is a profile at a party, the host included
+type Guest struct {
+	AccountID string
+	ProfileID string
+	Name      string
+}
+
+//Details are what a party is for and when, Season and Episode are only set for an episode
+type Details struct {
+	Name         string
+	Note         string
+	MediaType    string
+	TMDBID       int64
+	Season       int
+	Episode      int
+	ContentTitle string
+	StartsAt     time.Time
+	Duration     int
+}
+
+//Changes leaves a detail alone when it is nil
+type Changes struct {
+	Name     *string
+	Note     *string
+	StartsAt *time.Time
+	Duration *int
+}
+
+//State is a party with everyone invited to it
+type State struct {
+	Party   *parties.Party   `json:"party"`
+	Invites []parties.Invite `json:"invites"`
+}
+
+//Reminder is a due reminder with the party it is for
+type Reminder struct {
+	Party  parties.Party
+	Invite parties.Invite
+}
+
+type Parties struct {
+	s store
+}
+
+func NewPartiesService(conn, region string) (*Parties, error) {
+	var s store
+	s, err := parties.NewStore(conn, region)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Parties{
+		s: s,
+	}, nil
+}
+
+//CreateParty invites the guests, who are checked by the caller, and the host, who is going
+func (p Parties) CreateParty(ctx context.Context, host Guest, d Details, guests []Guest) (*State, error) {
+	err := checkDetails(d)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(guests) > MaxInvitees {
+		return nil, ErrTooManyInvitees
+	}
+
+	now := time.Now()
+	party := parties.Party{
+		ID:            ksuid.New().String(),
+		HostID:        host.ProfileID,
+		HostAccountID: host.AccountID,
+		HostName:      host.Name,
+		Name:          d.Name,
+		Note:          d.Note,
+		MediaType:     d.MediaType,
+		TMDBID:        d.TMDBID,
+		Season:        d.Season,
+		Episode:       d.Episode,
+		ContentTitle:  d.ContentTitle,
+		StartsAt:      d.StartsAt.Unix(),
+		Duration:      d.Duration,
+		Status:        StatusScheduled,
+		CreatedAt:     now.Unix(),
+		UpdatedAt:     now.Unix(),
+	}
+	party.ExpiresAt = expiresAt(party)
+
+	err = p.s.CreateParty(ctx, party)
+	if err != nil {
+		return nil, err
+	}
+
+	invites, err := p.invite(ctx, party, append([]Guest{host}, guests...))
+	if err != nil {
+		return nil, err
+	}
+
+	return newState(party, invites), nil
+}
+
+//FindParty is for the host and invitees only, anyone else gets ErrPartyNotFound
+func (p Parties) FindParty(ctx context.Context, profileID, ID string) (*State, error) {
+	party, invites, err := p.findAsGuest(ctx, profileID, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return newState(*party, invites), nil
+}
+
+//FindParties is the parties the profile is hosting or invited to, soonest first. Parties that have
+//finished are left out unless past is set.
+func (p Parties) FindParties(ctx context.Context, profileID string, past bool) (*[]parties.Party, error) {
+	invites, err := p.s.FindInvitesByProfileID(ctx, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	found := make([]parties.Party, 0, len(*invites))
+	for _, i := range *invites {
+		party, err := p.s.FindPartyByID(ctx, i.PartyID)
+		if err != nil {
+			return nil, err
+		}
+		if party == nil {
+			continue
+		}
+
+		if !past && endsAt(*party) < now {
+			continue
+		}
+
+		found = append(found, *publicParty(*party))
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].StartsAt < found[j].StartsAt
+	})
+
+	return &found, nil
+}
+
+//UpdateParty changes the details and who is invited. A new start time moves everyone's reminder
+//and bumps the calendar sequence. Added is the invites that are new, for the caller to notify.
+func (p Parties) UpdateParty(ctx context.Context, profileID, ID string, c Changes, add []Guest, remove []string) (*State, []parties.Invite, error) {
+	party, invites, err := p.findAsGuest(ctx, profileID, ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if party.HostID != profileID {
+		return nil, nil, ErrNotHost
+	}
+
+	if party.Status == StatusCancelled {
+		return nil, nil, ErrPartyCancelled
+	}
+
+	d := Details{
+		Name:      party.Name,
+		Note:      party.Note,
+		MediaType: party.MediaType,
+		TMDBID:    party.TMDBID,
+		Season:    party.Season,
+		Episode:   party.Episode,
+		StartsAt:  time.Unix(party.StartsAt, 0),
+		Duration:  party.Duration,
+	}
+	if c.Name != nil {
+		d.Name = *c.Name
+	}
+	if c.Note != nil {
+		d.Note = *c.Note
+	}
+	if c.Duration != nil {
+		d.Duration = *c.Duration
+	}
+
+	rescheduled := c.StartsAt != nil && c.StartsAt.Unix() != party.StartsAt
+	if rescheduled {
+		d.StartsAt = *c.StartsAt
+	} else if d.StartsAt.Before(time.Now()) {
+		//a party that has started can still have its note changed
+		d.StartsAt = time.Now().Add(time.Minute)
+	}
+
+	err = checkDetails(d)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	party.Name, party.Note, party.Duration = d.Name, d.Note, d.Duration
+	if rescheduled {
+		party.StartsAt = c.StartsAt.Unix()
+		party.Sequence++
+	}
+	party.UpdatedAt = time.Now().Unix()
+	party.ExpiresAt = expiresAt(*party)
+
+	//the host can't uninvite themselves
+	removing := make(map[string]bool)
+	for _, ID := range remove {
+		if ID != party.HostID {
+			removing[ID] = true
+		}
+	}
+
+	kept := make([]parties.Invite, 0, len(invites))
+	invited := make(map[string]bool)
+	for _, i := range invites {
+		if removing[i.ProfileID] {
+			err = p.s.RemoveInviteByID(ctx, i.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		invited[i.ProfileID] = true
+		kept = append(kept, i)
+	}
+
+	adding := make([]Guest, 0, len(add))
+	for _, g := range add {
+		if !invited[g.ProfileID] {
+			invited[g.ProfileID] = true
+			adding = append(adding, g)
+		}
+	}
+
+	//the host's own invite doesn't count
+	if len(kept)-1+len(adding) > MaxInvitees {
+		return nil, nil, ErrTooManyInvitees
+	}
+
+	err = p.s.CreateParty(ctx, *party)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range kept {
+		//reminders go out again for the new time, and every invite lives as long as the party
+		if rescheduled {
+			kept[i].RemindedAt = 0
+		}
+		setReminder(&kept[i], *party)
+		kept[i].ExpiresAt = party.ExpiresAt
+
+		err = p.s.CreateInvite(ctx, kept[i])
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	added, err := p.invite(ctx, *party, adding)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newState(*party, append(kept, added...)), added, nil
+}
+
+//CancelParty keeps the party so guests can see it was called off, no reminders go out for it
+func (p Parties) CancelParty(ctx context.Context, profileID, ID string) (*State, error) {
+	party, invites, err := p.findAsGuest(ctx, profileID, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if party.HostID != profileID {
+		return nil, ErrNotHost
+	}
+
+	if party.Status == StatusCancelled {
+		return newState(*party, invites), nil
+	}
+
+	party.Status = StatusCancelled
+	party.Sequence++
+	party.UpdatedAt = time.Now().Unix()
+	err = p.s.CreateParty(ctx, *party)
+	if err != nil {
+		return nil, err
+	}
+
+	return newState(*party, invites), nil
+}
+
+//RSVP answers an invite, remindBefore changes the reminder when it isn't nil. Declining turns the
+//reminder off.
+func (p Parties) RSVP(ctx context.Context, profileID, ID, rsvp string, remindBefore *int) (*parties.Invite, error) {
+	if rsvp != RSVPGoing && rsvp != RSVPMaybe && rsvp != RSVPDeclined {
+		return nil, ErrInvalidRSVP
+	}
+
+	if remindBefore != nil && (*remindBefore < 0 || *remindBefore > MaxReminder) {
+		return nil, ErrInvalidReminder
+	}
+
+	party, _, err := p.findAsGuest(ctx, profileID, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if party.Status == StatusCancelled {
+		return nil, ErrPartyCancelled
+	}
+
+	i, err := p.s.FindInviteByID(ctx, inviteID(party.ID, profileID))
+	if err != nil {
+		return nil, err
+	}
+	if i == nil {
+		return nil, ErrPartyNotFound
+	}
+
+	i.RSVP = rsvp
+	i.RespondedAt = time.Now().Unix()
+	if remindBefore != nil {
+		i.RemindBefore = *remindBefore
+		i.RemindedAt = 0
+	}
+	setReminder(i, *party)
+
+	err = p.s.CreateInvite(ctx, *i)
+	if err != nil {
+		return nil, err
+	}
+
+	i.AccountID = ""
+	return i, nil
+}
+
+//FindDueReminders is the reminders to send now for parties that are still on
+func (p Parties) FindDueReminders(ctx context.Context, now time.Time) (*[]Reminder, error) {
+	invites, err := p.s.FindDueReminders(ctx, now.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	byParty := make(map[string]*parties.Party)
+	reminders := make([]Reminder, 0, len(*invites))
+	for _, i := range *invites {
+		party, ok := byParty[i.PartyID]
+		if !ok {
+			party, err = p.s.FindPartyByID(ctx, i.PartyID)
+			if err != nil {
+				return nil, err
+			}
+			byParty[i.PartyID] = party
+		}
+
+		if party == nil || party.Status != StatusScheduled || i.RSVP == RSVPDeclined {
+			continue
+		}
+
+		reminders = append(reminders, Reminder{Party: *party, Invite: i})
+	}
+
+	return &reminders, nil
+}
+
+//MarkReminded stops the reminder going out again
+func (p Parties) MarkReminded(ctx context.Context, i parties.Invite) error {
+	i.RemindedAt = time.Now().Unix()
+	return p.s.CreateInvite(ctx, i)
+}
+
+//Export is the parties the account hosted and its profiles' invites, for the account data export
+type Export struct {
+	Parties *[]parties.Party  `json:"parties"`
+	Invites *[]parties.Invite `json:"invites"`
+}
+
+func (p Parties) ExportAccount(ctx context.Context, accountID string) (*Export, error) {
+	hosted, err := p.s.FindPartiesByHostAccountID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	invites, err := p.s.FindInvitesByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Export{
+		Parties: hosted,
+		Invites: invites,
+	}, nil
+}
+
+//RemoveUserData deletes the parties the account hosted, with everyone's invites to them, and its
+//profiles' invites to other parties when it is purged
+func (p Parties) RemoveUserData(ctx context.Context, accountID string) error {
+	hosted, err := p.s.FindPartiesByHostAccountID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	for _, party := range *hosted {
+		invites, err := p.s.FindInvitesByPartyID(ctx, party.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, i := range *invites {
+			err = p.s.RemoveInviteByID(ctx, i.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = p.s.RemovePartyByID(ctx, party.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	invites, err := p.s.FindInvitesByAccountID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range *invites {
+		err = p.s.RemoveInviteByID(ctx, i.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//Helpers
+
+//one invite per party and profile
+func inviteID(partyID, profileID string) string {
+	return partyID + "#" + profileID
+}
+
+func checkDetails(d Details) error {
+	if d.MediaType != content.MediaMovie && d.MediaType != content.MediaTV {
+		return ErrInvalidTarget
+	}
+
+	//shows are watched an episode at a time
+	if d.TMDBID <= 0 || (d.MediaType == content.MediaTV && (d.Season < 0 || d.Episode <= 0)) {
+		return ErrInvalidTarget
+	}
+
+	if d.MediaType == content.MediaMovie && (d.Season != 0 || d.Episode != 0) {
+		return ErrInvalidTarget
+	}
+
+	if !d.StartsAt.After(time.Now()) {
+		return ErrStartInPast
+	}
+
+	if d.Duration < 1 || d.Duration > MaxDuration {
+		return ErrInvalidDuration
+	}
+
+	if len(d.Name) > MaxPartyNameLen {
+		return ErrPartyNameTooLong
+	}
+
+	if len(d.Note) > MaxPartyNoteLen {
+		return ErrPartyNoteTooLong
+	}
+
+	return nil
+}
+
+func endsAt(party parties.Party) int64 {
+	return party.StartsAt + int64(party.Duration)*60
+}
+
+func expiresAt(party parties.Party) int64 {
+	return time.Unix(endsAt(party), 0).Add(PartyRetention).Unix()
+}
+
+//setReminder works out when the invite's reminder goes out, there is none once it has been sent,
+//for a declined invite or when the profile asked for none
+func setReminder(i *parties.Invite, party parties.Party) {
+	i.RemindAt = 0
+	if i.RemindedAt != 0 || i.RSVP == RSVPDeclined || i.RemindBefore <= 0 {
+		return
+	}
+
+	i.RemindAt = party.StartsAt - int64(i.RemindBefore)*60
+}
+
+func (p Parties) invite(ctx context.Context, party parties.Party, guests []Guest) ([]parties.Invite, error) {
+	now := time.Now().Unix()
+	invites := make([]parties.Invite, 0, len(guests))
+	for _, g := range guests {
+		i := parties.Invite{
+			ID:           inviteID(party.ID, g.ProfileID),
+			PartyID:      party.ID,
+			ProfileID:    g.ProfileID,
+			AccountID:    g.AccountID,
+			Name:         g.Name,
+			RSVP:         RSVPPending,
+			RemindBefore: DefaultReminder,
+			CreatedAt:    now,
+			ExpiresAt:    party.ExpiresAt,
+		}
+		if g.ProfileID == party.HostID {
+			i.RSVP = RSVPGoing
+			i.RespondedAt = now
+		}
+		setReminder(&i, party)
+
+		err := p.s.CreateInvite(ctx, i)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, i)
+	}
+
+	return invites, nil
+}
+
+func (p Parties) findAsGuest(ctx context.Context, profileID, ID string) (*parties.Party, []parties.Invite, error) {
+	party, err := p.s.FindPartyByID(ctx, ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if party == nil {
+		return nil, nil, ErrPartyNotFound
+	}
+
+	invites, err := p.s.FindInvitesByPartyID(ctx, ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, i := range *invites {
+		if i.ProfileID == profileID {
+			return party, *invites, nil
+		}
+	}
+
+	return nil, nil, ErrPartyNotFound
+}
+
+//parties and invites shown to guests don't carry account IDs
+func publicParty(party parties.Party) *parties.Party {
+	party.HostAccountID = ""
+	return &party
+}
+
+func newState(party parties.Party, invites []parties.Invite) *State {
+	public := make([]parties.Invite, 0, len(invites))
+	for _, i := range invites {
+		i.AccountID = ""
+		public = append(public, i)
+	}
+
+	return &State{
+		Party:   publicParty(party),
+		Invites: public,
+	}
+}
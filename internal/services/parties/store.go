@@ -0,0 +1,21 @@
+package parties
+
+import (
+	"context"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/parties"
+)
+
+type store interface {
+	CreateInvite(ctx context.Context, i parties.Invite) error
+	CreateParty(ctx context.Context, p parties.Party) error
+	FindDueReminders(ctx context.Context, now int64) (*[]parties.Invite, error)
+	FindInviteByID(ctx context.Context, ID string) (*parties.Invite, error)
+	FindInvitesByAccountID(ctx context.Context, accountID string) (*[]parties.Invite, error)
+	FindInvitesByPartyID(ctx context.Context, partyID string) (*[]parties.Invite, error)
+	FindInvitesByProfileID(ctx context.Context, profileID string) (*[]parties.Invite, error)
+	FindPartiesByHostAccountID(ctx context.Context, accountID string) (*[]parties.Party, error)
+	FindPartyByID(ctx context.Context, ID string) (*parties.Party, error)
+	RemoveInviteByID(ctx context.Context, ID string) error
+	RemovePartyByID(ctx context.Context, ID string) error
+}
@@ -0,0 +1,385 @@
+package reviews
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/reviews"
+	"github.com/aschles4/finalProject/internal/services/content"
+)
+
+const (
+	//ratings use TMDB's 1 to 10 scale so community scores sit next to vote_average
+	MinRating = 1
+	MaxRating = 10
+
+	MaxReviewLength = 5000
+	MaxReviews      = 200
+)
+
+var (
+	ErrInvalidTarget  = errors.New("reviews are for a movie, show or episode")
+	ErrInvalidRating  = errors.New("rating must be between 1 and 10")
+	ErrReviewTooLong  = errors.New("review is too long")
+	ErrReviewNotFound = errors.New("review not found")
+)
+
+//Target is what is being reviewed, Season and Episode are only set for an episode of a show
+type Target struct {
+	MediaType string
+	TMDBID    string
+	Season    int
+	Episode   int
+}
+
+//Score is the community's take on a title, Average is zero when nobody has rated it
+type Score struct {
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+}
+
+//Rating is a profile's rating of a title without the review text, for ranking suggestions
+type Rating struct {
+	MediaType string `json:"mediaType"`
+	TMDBID    string `json:"tmdbId"`
+	Season    int    `json:"season,omitempty"`
+	Episode   int    `json:"episode,omitempty"`
+	Rating    int    `json:"rating"`
+	UpdatedAt int64  `json:"updatedAt"`
+}
+
+type Reviews struct {
+	s store
+}
+
+func NewReviewsService(conn, region string) (*Reviews, error) {
+	var s store
+	s, err := reviews.NewStore(conn, region)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Reviews{
+		s: s,
+	}, nil
+}
+
+//NewTarget checks the path parameters of a review request, season and episode are empty for a title
+func NewTarget(mediaType, ID, season, episode string) (*Target, error) {
+	if mediaType != content.MediaMovie && mediaType != content.MediaTV {
+		return nil, ErrInvalidTarget
+	}
+
+	if _, err := strconv.Atoi(ID); err != nil {
+		return nil, ErrInvalidTarget
+	}
+
+	t := Target{MediaType: mediaType, TMDBID: ID}
+	if season == "" && episode == "" {
+		return &t, nil
+	}
+
+	//movies have no episodes
+	if mediaType != content.MediaTV {
+		return nil, ErrInvalidTarget
+	}
+
+	var err error
+	t.Season, err = strconv.Atoi(season)
+	if err != nil || t.Season < 0 {
+		return nil, ErrInvalidTarget
+	}
+
+	t.Episode, err = strconv.Atoi(episode)
+	if err != nil || t.Episode < 1 {
+		return nil, ErrInvalidTarget
+	}
+
+	return &t, nil
+}
+
+//Key is the content key reviews of the target share, eg movie:603 or tv:1396:2:5
+func (t Target) Key() string {
+	key := content.TitleKey(t.MediaType, t.TMDBID)
+	if t.Episode == 0 {
+		return key
+	}
+
+	return fmt.Sprintf("%s:%d:%d", key, t.Season, t.Episode)
+}
+
+//SaveReview rates the target, or edits the profile's existing review of it. Text is optional,
+//an empty one clears a previous review and leaves just the rating.
+func (r Reviews) SaveReview(ctx context.Context, accountID, profileID, author string, t Target, rating int, text string) (*reviews.Review, error) {
+	if rating < MinRating || rating > MaxRating {
+		return nil, ErrInvalidRating
+	}
+
+	text = strings.TrimSpace(text)
+	if len([]rune(text)) > MaxReviewLength {
+		return nil, ErrReviewTooLong
+	}
+
+	ID := reviewID(profileID, t)
+	existing, err := r.s.FindReviewByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	review := reviews.Review{
+		ID:         ID,
+		ContentKey: t.Key(),
+		AccountID:  accountID,
+		ProfileID:  profileID,
+		Author:     author,
+		MediaType:  t.MediaType,
+		TMDBID:     t.TMDBID,
+		Season:     t.Season,
+		Episode:    t.Episode,
+		Rating:     rating,
+		Text:       text,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if existing != nil {
+		review.CreatedAt = existing.CreatedAt
+	}
+
+	err = r.s.CreateReview(ctx, review)
+	if err != nil {
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+//ImportRating saves a rating from another service dated ratedAt. A rating the profile made
+//here since then is kept, and so is the text of an existing review. It reports whether it saved.
+func (r Reviews) ImportRating(ctx context.Context, accountID, profileID, author string, t Target, rating int, ratedAt int64) (bool, error) {
+	if rating < MinRating || rating > MaxRating {
+		return false, ErrInvalidRating
+	}
+
+	ID := reviewID(profileID, t)
+	existing, err := r.s.FindReviewByID(ctx, ID)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now().Unix()
+	if ratedAt == 0 || ratedAt > now {
+		ratedAt = now
+	}
+
+	review := reviews.Review{
+		ID:         ID,
+		ContentKey: t.Key(),
+		AccountID:  accountID,
+		ProfileID:  profileID,
+		Author:     author,
+		MediaType:  t.MediaType,
+		TMDBID:     t.TMDBID,
+		Season:     t.Season,
+		Episode:    t.Episode,
+		Rating:     rating,
+		CreatedAt:  ratedAt,
+		UpdatedAt:  ratedAt,
+	}
+	if existing != nil {
+		if existing.UpdatedAt >= ratedAt {
+			return false, nil
+		}
+		review.Text = existing.Text
+		review.CreatedAt = existing.CreatedAt
+	}
+
+	err = r.s.CreateReview(ctx, review)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r Reviews) RemoveReview(ctx context.Context, profileID string, t Target) error {
+	ID := reviewID(profileID, t)
+	review, err := r.s.FindReviewByID(ctx, ID)
+	if err != nil {
+		return err
+	}
+
+	if review == nil {
+		return ErrReviewNotFound
+	}
+
+	return r.s.RemoveReviewByID(ctx, ID)
+}
+
+//FindReview is the profile's own review of the target, nil when it hasn't rated it
+func (r Reviews) FindReview(ctx context.Context, profileID string, t Target) (*reviews.Review, error) {
+	return r.s.FindReviewByID(ctx, reviewID(profileID, t))
+}
+
+//FindReviews is the newest reviews of the target with written text, the score counts every rating
+func (r Reviews) FindReviews(ctx context.Context, t Target, limit int) (*[]reviews.Review, *Score, error) {
+	all, err := r.s.FindReviewsByContentKey(ctx, t.Key())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	score := newScore(*all)
+
+	written := make([]reviews.Review, 0)
+	for _, rv := range *all {
+		if rv.Text != "" {
+			written = append(written, publicReview(rv))
+		}
+	}
+
+	sort.SliceStable(written, func(i, j int) bool {
+		return written[i].UpdatedAt > written[j].UpdatedAt
+	})
+
+	if limit < 1 || limit > MaxReviews {
+		limit = MaxReviews
+	}
+	if len(written) > limit {
+		written = written[:limit]
+	}
+
+	return &written, score, nil
+}
+
+//FindScore is the community score the detail pages show next to TMDB's vote average
+func (r Reviews) FindScore(ctx context.Context, t Target) (*Score, error) {
+	all, err := r.s.FindReviewsByContentKey(ctx, t.Key())
+	if err != nil {
+		return nil, err
+	}
+
+	return newScore(*all), nil
+}
+
+//FindRatingsByProfileID is everything the profile has rated, newest first
+func (r Reviews) FindRatingsByProfileID(ctx context.Context, profileID string) (*[]Rating, error) {
+	all, err := r.s.FindReviewsByProfileID(ctx, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(*all, func(i, j int) bool {
+		return (*all)[i].UpdatedAt > (*all)[j].UpdatedAt
+	})
+
+	ratings := make([]Rating, 0, len(*all))
+	for _, rv := range *all {
+		ratings = append(ratings, Rating{
+			MediaType: rv.MediaType,
+			TMDBID:    rv.TMDBID,
+			Season:    rv.Season,
+			Episode:   rv.Episode,
+			Rating:    rv.Rating,
+			UpdatedAt: rv.UpdatedAt,
+		})
+	}
+
+	return &ratings, nil
+}
+
+//FindReviewsByProfileID is the profile's ratings with their review text, newest first
+func (r Reviews) FindReviewsByProfileID(ctx context.Context, profileID string) (*[]reviews.Review, error) {
+	all, err := r.s.FindReviewsByProfileID(ctx, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(*all, func(i, j int) bool {
+		return (*all)[i].UpdatedAt > (*all)[j].UpdatedAt
+	})
+
+	return all, nil
+}
+
+//RatedTitles maps the movies and shows the profile rated to the rating, keyed by content.TitleKey
+//because they share TMDB IDs. Episodes are left out, an empty mediaType includes both.
+func RatedTitles(ratings []Rating, mediaType string) map[string]int {
+	rated := make(map[string]int)
+	for _, rt := range ratings {
+		if rt.Episode != 0 || (mediaType != "" && rt.MediaType != mediaType) {
+			continue
+		}
+		rated[content.TitleKey(rt.MediaType, rt.TMDBID)] = rt.Rating
+	}
+
+	return rated
+}
+
+//FindReviewsByAccountID is every review written by the account's profiles, for data exports
+func (r Reviews) FindReviewsByAccountID(ctx context.Context, accountID string) (*[]reviews.Review, error) {
+	all, err := r.s.FindReviewsByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(*all, func(i, j int) bool {
+		return (*all)[i].UpdatedAt > (*all)[j].UpdatedAt
+	})
+
+	return all, nil
+}
+
+//RemoveUserData deletes the account's reviews when it is purged, its ratings leave the community scores with it
+func (r Reviews) RemoveUserData(ctx context.Context, accountID string) error {
+	all, err := r.s.FindReviewsByAccountID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	for _, rv := range *all {
+		err = r.s.RemoveReviewByID(ctx, rv.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//Helpers
+
+//one review per profile and target, saving again overwrites it
+func reviewID(profileID string, t Target) string {
+	return t.Key() + "#" + profileID
+}
+
+//reviews shown to other viewers only carry the author's name, the owner's profile ID is the account ID
+func publicReview(rv reviews.Review) reviews.Review {
+	rv.AccountID = ""
+	rv.ProfileID = ""
+	return rv
+}
+
+func newScore(all []reviews.Review) *Score {
+	if len(all) == 0 {
+		return &Score{}
+	}
+
+	total := 0
+	for _, rv := range all {
+		total += rv.Rating
+	}
+
+	//one decimal, like TMDB
+	avg := float64(total) / float64(len(all))
+	return &Score{
+		Average: math.Round(avg*10) / 10,
+		Count:   len(all),
+	}
+}
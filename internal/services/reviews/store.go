@@ -0,0 +1,16 @@
+package reviews
+
+import (
+	"context"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/reviews"
+)
+
+type store interface {
+	CreateReview(ctx context.Context, review reviews.Review) error
+	FindReviewByID(ctx context.Context, ID string) (*reviews.Review, error)
+	FindReviewsByAccountID(ctx context.Context, accountID string) (*[]reviews.Review, error)
+	FindReviewsByContentKey(ctx context.Context, contentKey string) (*[]reviews.Review, error)
+	FindReviewsByProfileID(ctx context.Context, profileID string) (*[]reviews.Review, error)
+	RemoveReviewByID(ctx context.Context, ID string) error
+}
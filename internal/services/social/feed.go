@@ -0,0 +1,146 @@
+package social
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/social"
+	"github.com/segmentio/ksuid"
+)
+
+const (
+	//feed items are copied to every follower when they happen, old ones expire instead of piling up
+	FeedTTL         = 90 * 24 * time.Hour
+	DefaultFeedSize = 25
+	MaxFeedSize     = 100
+)
+
+//Activity is something a profile did that its followers may see, Rating is only set for ratings
+//and Season and Episode only for a watched episode
+type Activity struct {
+	Verb      string
+	MediaType string
+	TMDBID    int64
+	Season    int
+	Episode   int
+	Rating    int
+}
+
+//Publish copies the activity into the feed of each accepted follower. Kids profiles and activity
+//the member has chosen not to publish go nowhere.
+func (s Social) Publish(ctx context.Context, m Member, a Activity) error {
+	if !validActivity(a.Verb) {
+		return ErrInvalidActivity
+	}
+
+	if m.Kids {
+		return nil
+	}
+
+	settings, err := s.settingsFor(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	if !contains(settings.Publish, a.Verb) {
+		return nil
+	}
+
+	followers, err := s.acceptedFollowers(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	//the sort key orders by time, the ksuid keeps two items in the same second apart
+	sk := fmt.Sprintf("%010d#%s", now.Unix(), ksuid.New().String())
+	for _, f := range followers {
+		err = s.s.CreateFeedItem(ctx, social.FeedItem{
+			FeedID:         f.FollowerID,
+			SK:             sk,
+			OwnerAccountID: f.FollowerAccountID,
+			ActorID:        m.ProfileID,
+			ActorAccountID: m.AccountID,
+			ActorName:      m.Name,
+			Verb:           a.Verb,
+			MediaType:      a.MediaType,
+			TMDBID:         a.TMDBID,
+			Season:         a.Season,
+			Episode:        a.Episode,
+			Rating:         a.Rating,
+			CreatedAt:      now.Unix(),
+			TTL:            now.Add(FeedTTL).Unix(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//FindFeed reads a page of the member's feed newest first. Items from profiles the member has since
+//unfollowed or blocked are dropped as they are read, so a page can come back short. The cursor
+//for the next page is empty at the end of the feed.
+func (s Social) FindFeed(ctx context.Context, m Member, cursor string, limit int) (*[]social.FeedItem, string, error) {
+	if limit <= 0 {
+		limit = DefaultFeedSize
+	}
+	if limit > MaxFeedSize {
+		limit = MaxFeedSize
+	}
+
+	items, err := s.s.FindFeedItems(ctx, m.ProfileID, cursor, int64(limit))
+	if err != nil {
+		return nil, "", err
+	}
+
+	following, err := s.s.FindConnectionsByFollowerID(ctx, m.ProfileID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	followed := make(map[string]bool)
+	for _, c := range *following {
+		if c.Status == StatusAccepted {
+			followed[c.FolloweeID] = true
+		}
+	}
+
+	feed := make([]social.FeedItem, 0, len(*items))
+	for _, item := range *items {
+		if !followed[item.ActorID] {
+			continue
+		}
+
+		item.OwnerAccountID, item.ActorAccountID = "", ""
+		feed = append(feed, item)
+	}
+
+	next := ""
+	if len(*items) == limit {
+		next = (*items)[len(*items)-1].SK
+	}
+
+	return &feed, next, nil
+}
+
+//Helpers
+
+//acceptedFollowers keeps the account IDs FindFollowers strips, the feed items need them
+func (s Social) acceptedFollowers(ctx context.Context, m Member) ([]social.Connection, error) {
+	all, err := s.s.FindConnectionsByFolloweeID(ctx, m.ProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	followers := make([]social.Connection, 0)
+	for _, c := range *all {
+		if c.Status == StatusAccepted {
+			followers = append(followers, c)
+		}
+	}
+
+	return followers, nil
+}
@@ -0,0 +1,125 @@
+package social
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/social"
+)
+
+const (
+	ActivityLibraryAdd = "library_add"
+	ActivityRating     = "rating"
+	ActivityWatched    = "watched"
+)
+
+//Activities is every kind of activity a profile can publish
+var Activities = []string{ActivityLibraryAdd, ActivityRating, ActivityWatched}
+
+var ErrInvalidActivity = errors.New("activity must be library_add, rating or watched")
+
+//SettingsChanges leaves a setting alone when it is nil
+type SettingsChanges struct {
+	Private *bool
+	Publish *[]string
+}
+
+//FindSettings profiles start private and publishing everything, so nothing reaches anyone until
+//they accept a follower
+func (s Social) FindSettings(ctx context.Context, m Member) (*social.Settings, error) {
+	settings, err := s.settingsFor(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+
+	settings.AccountID = ""
+	return settings, nil
+}
+
+//UpdateSettings going public accepts the requests that were waiting, there is nobody left to ask
+func (s Social) UpdateSettings(ctx context.Context, m Member, c SettingsChanges) (*social.Settings, error) {
+	if m.Kids {
+		return nil, ErrKidsProfile
+	}
+
+	settings, err := s.settingsFor(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Publish != nil {
+		publish := make([]string, 0, len(*c.Publish))
+		for _, a := range *c.Publish {
+			if !validActivity(a) {
+				return nil, ErrInvalidActivity
+			}
+			if !contains(publish, a) {
+				publish = append(publish, a)
+			}
+		}
+		settings.Publish = publish
+	}
+
+	wasPrivate := settings.Private
+	if c.Private != nil {
+		settings.Private = *c.Private
+	}
+
+	settings.UpdatedAt = time.Now().Unix()
+	err = s.s.CreateSettings(ctx, *settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if wasPrivate && !settings.Private {
+		requests, err := s.FindRequests(ctx, m)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range *requests {
+			_, err = s.RespondToRequest(ctx, m, r.FollowerID, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	settings.AccountID = ""
+	return settings, nil
+}
+
+//Helpers
+
+//settingsFor is FindSettings with the account ID, which settings are saved with
+func (s Social) settingsFor(ctx context.Context, m Member) (*social.Settings, error) {
+	settings, err := s.s.FindSettingsByID(ctx, m.ProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	if settings == nil {
+		settings = &social.Settings{
+			ID:        m.ProfileID,
+			AccountID: m.AccountID,
+			Private:   true,
+			Publish:   append([]string{}, Activities...),
+		}
+	}
+
+	return settings, nil
+}
+
+func validActivity(activity string) bool {
+	return contains(Activities, activity)
+}
+
+func contains(all []string, s string) bool {
+	for _, a := range all {
+		if a == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,457 @@
+package social
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/social"
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusAccepted = "accepted"
+)
+
+var (
+	ErrKidsProfile     = errors.New("kids profiles can't follow or be followed")
+	ErrSelf            = errors.New("profiles can't follow or block themselves")
+	ErrProfileNotFound = errors.New("profile not found")
+	ErrNotFollowing    = errors.New("not following")
+	ErrRequestNotFound = errors.New("follow request not found")
+	ErrBlockNotFound   = errors.New("block not found")
+)
+
+//Member is a profile taking part in the follow graph, built from the viewer or a looked up profile
+type Member struct {
+	AccountID string
+	ProfileID string
+	Name      string
+	Kids      bool
+}
+
+type Social struct {
+	s store
+}
+
+func NewSocialService(conn, region string) (*Social, error) {
+	var s store
+	s, err := social.NewStore(conn, region)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Social{
+		s: s,
+	}, nil
+}
+
+//MemberOf the account owner's profile has no AccountID, it shares the account's
+func MemberOf(prof *users.UserProfile) Member {
+	accountID := prof.AccountID
+	if accountID == "" {
+		accountID = prof.ID
+	}
+
+	return Member{
+		AccountID: accountID,
+		ProfileID: prof.ID,
+		Name:      prof.Name,
+		Kids:      prof.Kids,
+	}
+}
+
+//Follow is accepted straight away for public profiles and waits for a private one to accept it.
+//A profile that has blocked the follower, or been blocked by it, reads as not found.
+func (s Social) Follow(ctx context.Context, m Member, target Member) (*social.Connection, error) {
+	if m.ProfileID == target.ProfileID {
+		return nil, ErrSelf
+	}
+
+	if m.Kids || target.Kids {
+		return nil, ErrKidsProfile
+	}
+
+	blocked, err := s.blocked(ctx, m.ProfileID, target.ProfileID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, ErrProfileNotFound
+	}
+
+	existing, err := s.s.FindConnectionByID(ctx, connectionID(m.ProfileID, target.ProfileID))
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	settings, err := s.settingsFor(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	c := social.Connection{
+		ID:                connectionID(m.ProfileID, target.ProfileID),
+		FollowerID:        m.ProfileID,
+		FollowerAccountID: m.AccountID,
+		FollowerName:      m.Name,
+		FolloweeID:        target.ProfileID,
+		FolloweeAccountID: target.AccountID,
+		FolloweeName:      target.Name,
+		Status:            StatusPending,
+		CreatedAt:         now,
+	}
+	if !settings.Private {
+		c.Status = StatusAccepted
+		c.AcceptedAt = now
+	}
+
+	err = s.s.CreateConnection(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+//Unfollow also withdraws a pending request
+func (s Social) Unfollow(ctx context.Context, m Member, targetID string) error {
+	return s.removeConnection(ctx, connectionID(m.ProfileID, targetID))
+}
+
+//RemoveFollower stops someone following the member without blocking them
+func (s Social) RemoveFollower(ctx context.Context, m Member, followerID string) error {
+	return s.removeConnection(ctx, connectionID(followerID, m.ProfileID))
+}
+
+//RespondToRequest accepts or declines a follow request, declining removes it so it can be sent again
+func (s Social) RespondToRequest(ctx context.Context, m Member, followerID string, accept bool) (*social.Connection, error) {
+	c, err := s.s.FindConnectionByID(ctx, connectionID(followerID, m.ProfileID))
+	if err != nil {
+		return nil, err
+	}
+	if c == nil || c.Status != StatusPending {
+		return nil, ErrRequestNotFound
+	}
+
+	if !accept {
+		return nil, s.s.RemoveConnectionByID(ctx, c.ID)
+	}
+
+	c.Status = StatusAccepted
+	c.AcceptedAt = time.Now().Unix()
+	err = s.s.CreateConnection(ctx, *c)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+//FindFollowers is who has accepted follows of the member
+func (s Social) FindFollowers(ctx context.Context, m Member) (*[]social.Connection, error) {
+	all, err := s.s.FindConnectionsByFolloweeID(ctx, m.ProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	return withStatus(*all, StatusAccepted), nil
+}
+
+//FindFollowing is who the member follows, requests still waiting on a private profile included
+func (s Social) FindFollowing(ctx context.Context, m Member) (*[]social.Connection, error) {
+	all, err := s.s.FindConnectionsByFollowerID(ctx, m.ProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range *all {
+		(*all)[i] = publicConnection((*all)[i])
+	}
+
+	return all, nil
+}
+
+//FindRequests is the follow requests waiting on the member
+func (s Social) FindRequests(ctx context.Context, m Member) (*[]social.Connection, error) {
+	all, err := s.s.FindConnectionsByFolloweeID(ctx, m.ProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	return withStatus(*all, StatusPending), nil
+}
+
+//Follows is true when the follower's follow of the followee was accepted and neither has blocked
+//the other. Features that notify someone check that they follow the sender, anyone can follow a
+//public profile so the sender following them says nothing.
+func (s Social) Follows(ctx context.Context, followerID, followeeID string) (bool, error) {
+	blocked, err := s.blocked(ctx, followerID, followeeID)
+	if err != nil || blocked {
+		return false, err
+	}
+
+	c, err := s.s.FindConnectionByID(ctx, connectionID(followerID, followeeID))
+	if err != nil {
+		return false, err
+	}
+
+	return c != nil && c.Status == StatusAccepted, nil
+}
+
+//Block removes any follow between the two profiles in either direction and stops new ones.
+//The blocked profile isn't told, to it the member looks like they don't exist.
+func (s Social) Block(ctx context.Context, m Member, target Member) (*social.Block, error) {
+	if m.ProfileID == target.ProfileID {
+		return nil, ErrSelf
+	}
+
+	for _, ID := range []string{connectionID(m.ProfileID, target.ProfileID), connectionID(target.ProfileID, m.ProfileID)} {
+		err := s.s.RemoveConnectionByID(ctx, ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	b := social.Block{
+		ID:               connectionID(m.ProfileID, target.ProfileID),
+		BlockerID:        m.ProfileID,
+		BlockerAccountID: m.AccountID,
+		BlockedID:        target.ProfileID,
+		BlockedAccountID: target.AccountID,
+		CreatedAt:        time.Now().Unix(),
+	}
+
+	err := s.s.CreateBlock(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+
+	return &b, nil
+}
+
+//Unblock doesn't restore the follows the block removed
+func (s Social) Unblock(ctx context.Context, m Member, targetID string) error {
+	ID := connectionID(m.ProfileID, targetID)
+	b, err := s.s.FindBlockByID(ctx, ID)
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		return ErrBlockNotFound
+	}
+
+	return s.s.RemoveBlockByID(ctx, ID)
+}
+
+//FindBlocks is the profiles the member has blocked, not who has blocked them
+func (s Social) FindBlocks(ctx context.Context, m Member) (*[]social.Block, error) {
+	all, err := s.s.FindBlocksByProfileID(ctx, m.ProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make([]social.Block, 0)
+	for _, b := range *all {
+		if b.BlockerID == m.ProfileID {
+			b.BlockerAccountID, b.BlockedAccountID = "", ""
+			blocks = append(blocks, b)
+		}
+	}
+
+	return &blocks, nil
+}
+
+//Export is everything social the account's profiles have, for the account data export. Feeds
+//are other people's activity and are left out.
+type Export struct {
+	Connections *[]social.Connection `json:"connections"`
+	Blocks      *[]social.Block      `json:"blocks"`
+	Settings    *[]social.Settings   `json:"settings"`
+}
+
+func (s Social) ExportAccount(ctx context.Context, accountID string) (*Export, error) {
+	connections, err := s.s.FindConnectionsByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := s.s.FindBlocksByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	//blocks against the account's profiles are the blocker's data
+	own := make([]social.Block, 0)
+	for _, b := range *blocks {
+		if b.BlockerAccountID == accountID {
+			own = append(own, b)
+		}
+	}
+
+	settings, err := s.s.FindSettingsByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Export{
+		Connections: connections,
+		Blocks:      &own,
+		Settings:    settings,
+	}, nil
+}
+
+//RemoveProfileData deletes a removed household profile's follows, blocks, settings and feed
+func (s Social) RemoveProfileData(ctx context.Context, profileID string) error {
+	followers, err := s.s.FindConnectionsByFolloweeID(ctx, profileID)
+	if err != nil {
+		return err
+	}
+
+	following, err := s.s.FindConnectionsByFollowerID(ctx, profileID)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range append(*followers, *following...) {
+		err = s.s.RemoveConnectionByID(ctx, c.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	blocks, err := s.s.FindBlocksByProfileID(ctx, profileID)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range *blocks {
+		err = s.s.RemoveBlockByID(ctx, b.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	items, err := s.s.FindFeedItemsByProfileID(ctx, profileID)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range *items {
+		err = s.s.RemoveFeedItem(ctx, item.FeedID, item.SK)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.s.RemoveSettingsByID(ctx, profileID)
+}
+
+//RemoveUserData deletes everything social about the account's profiles when it is purged
+func (s Social) RemoveUserData(ctx context.Context, accountID string) error {
+	connections, err := s.s.FindConnectionsByAccountID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range *connections {
+		err = s.s.RemoveConnectionByID(ctx, c.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	blocks, err := s.s.FindBlocksByAccountID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range *blocks {
+		err = s.s.RemoveBlockByID(ctx, b.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	items, err := s.s.FindFeedItemsByAccountID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range *items {
+		err = s.s.RemoveFeedItem(ctx, item.FeedID, item.SK)
+		if err != nil {
+			return err
+		}
+	}
+
+	settings, err := s.s.FindSettingsByAccountID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	for _, st := range *settings {
+		err = s.s.RemoveSettingsByID(ctx, st.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//Helpers
+
+//one connection per follower and followee, blocks are keyed the same way
+func connectionID(fromID, toID string) string {
+	return fromID + "#" + toID
+}
+
+func (s Social) removeConnection(ctx context.Context, ID string) error {
+	c, err := s.s.FindConnectionByID(ctx, ID)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		return ErrNotFollowing
+	}
+
+	return s.s.RemoveConnectionByID(ctx, ID)
+}
+
+//blocked is true when either profile has blocked the other
+func (s Social) blocked(ctx context.Context, aID, bID string) (bool, error) {
+	for _, ID := range []string{connectionID(aID, bID), connectionID(bID, aID)} {
+		b, err := s.s.FindBlockByID(ctx, ID)
+		if err != nil {
+			return false, err
+		}
+		if b != nil {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func withStatus(all []social.Connection, status string) *[]social.Connection {
+	matched := make([]social.Connection, 0)
+	for _, c := range all {
+		if c.Status == status {
+			matched = append(matched, publicConnection(c))
+		}
+	}
+	return &matched
+}
+
+//connections shown to profiles only carry profile IDs and names, account IDs stay private
+func publicConnection(c social.Connection) social.Connection {
+	c.FollowerAccountID = ""
+	c.FolloweeAccountID = ""
+	return c
+}
@@ -0,0 +1,30 @@
+package social
+
+import (
+	"context"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/social"
+)
+
+type store interface {
+	CreateBlock(ctx context.Context, b social.Block) error
+	CreateConnection(ctx context.Context, c social.Connection) error
+	CreateFeedItem(ctx context.Context, item social.FeedItem) error
+	CreateSettings(ctx context.Context, settings social.Settings) error
+	FindBlockByID(ctx context.Context, ID string) (*social.Block, error)
+	FindBlocksByAccountID(ctx context.Context, accountID string) (*[]social.Block, error)
+	FindBlocksByProfileID(ctx context.Context, profileID string) (*[]social.Block, error)
+	FindConnectionByID(ctx context.Context, ID string) (*social.Connection, error)
+	FindConnectionsByAccountID(ctx context.Context, accountID string) (*[]social.Connection, error)
+	FindConnectionsByFolloweeID(ctx context.Context, followeeID string) (*[]social.Connection, error)
+	FindConnectionsByFollowerID(ctx context.Context, followerID string) (*[]social.Connection, error)
+	FindFeedItems(ctx context.Context, feedID, after string, limit int64) (*[]social.FeedItem, error)
+	FindFeedItemsByAccountID(ctx context.Context, accountID string) (*[]social.FeedItem, error)
+	FindFeedItemsByProfileID(ctx context.Context, profileID string) (*[]social.FeedItem, error)
+	FindSettingsByAccountID(ctx context.Context, accountID string) (*[]social.Settings, error)
+	FindSettingsByID(ctx context.Context, ID string) (*social.Settings, error)
+	RemoveBlockByID(ctx context.Context, ID string) error
+	RemoveConnectionByID(ctx context.Context, ID string) error
+	RemoveFeedItem(ctx context.Context, feedID, sk string) error
+	RemoveSettingsByID(ctx context.Context, ID string) error
+}
@@ -2,11 +2,32 @@ package users
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
 
 	"github.com/aschles4/finalProject/internal/pkg/dynamo/users"
 	"github.com/segmentio/ksuid"
+	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrUnauthorized       = errors.New("token does not match an active session")
+	ErrAccountDisabled    = errors.New("account is disabled")
+)
+
+type Login struct {
+	UserID    string `json:"userId,omitempty"`
+	Token     string `json:"token,omitempty"`
+	Challenge string `json:"challenge,omitempty"`
+	//DeleteAfter is set while the account is waiting to be deleted, see ScheduleAccountDeletion
+	DeleteAfter int64 `json:"deleteAfter,omitempty"`
+}
+
 func (u Users) CreateUserAccount(ctx context.Context, ID, email, pass string) error {
 
 	p, err := u.passEncrypt(ctx, pass)
@@ -16,9 +37,9 @@ func (u Users) CreateUserAccount(ctx context.Context, ID, email, pass string) er
 
 	a := users.UserAccount{
 		ID:       ID,
-		Email:    email,
+		Email:    NormalizeEmail(email),
 		Password: p,
-		Token:    "v",
+		Token:    u.generateToken(ctx),
 	}
 	return u.s.CreateUserAccount(ctx, a)
 }
@@ -32,42 +53,128 @@ func (u Users) CreateUserAccountWithToken(ctx context.Context, ID, email, pass,
 
 	a := users.UserAccount{
 		ID:       ID,
-		Email:    email,
+		Email:    NormalizeEmail(email),
 		Password: p,
 		Token:    token,
 	}
 	return u.s.CreateUserAccount(ctx, a)
 }
 
+//FindUserAccountByEmail ignores case and surrounding spaces. Accounts made before emails were
+//normalized are still found by the email exactly as it was sent.
 func (u Users) FindUserAccountByEmail(ctx context.Context, email string) (*users.UserAccount, error) {
-	return u.s.FindUserAccountByEmail(ctx, email)
+	act, err := u.s.FindUserAccountByEmail(ctx, NormalizeEmail(email))
+	if err != nil {
+		return nil, err
+	}
+
+	if act == nil && NormalizeEmail(email) != email {
+		return u.s.FindUserAccountByEmail(ctx, email)
+	}
+
+	return act, nil
+}
+
+//NormalizeEmail is the email as accounts are stored and looked up, so Bob@x.com and bob@x.com are one account
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+//FindUserAccounts is for staff, see Authorize
+func (u Users) FindUserAccounts(ctx context.Context, query, startID string, limit int) (*[]users.UserAccount, string, error) {
+	return u.s.FindUserAccounts(ctx, query, startID, limit)
+}
+
+func (u Users) FindUserAccountByID(ctx context.Context, ID string) (*users.UserAccount, error) {
+	act, err := u.s.FindUserAccountByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if act == nil {
+		return nil, ErrAccountMissing
+	}
+
+	return act, nil
 }
 
 func (u Users) FindUserAccountByToken(ctx context.Context, token string) (*users.UserAccount, error) {
-	return u.s.FindUserAccountByToken(ctx, token)
+	act, err := u.s.FindUserAccountByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if act == nil || act.Disabled {
+		return nil, ErrUnauthorized
+	}
+
+	return act, nil
 }
 
-func (u Users) LoginUser(ctx context.Context, email, pass string) (string, error) {
+//FindUserAccountByCalendarToken is nil for a disabled account or one waiting to be deleted, so their feeds stop
+func (u Users) FindUserAccountByCalendarToken(ctx context.Context, token string) (*users.UserAccount, error) {
+	act, err := u.s.FindUserAccountByCalendarToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if act == nil || act.Disabled || act.DeleteAfter != 0 {
+		return nil, nil
+	}
+
+	return act, nil
+}
+
+//LoginUser returns a session token, or a challenge to answer with CompleteTwoFactorLogin when 2FA is on.
+//Failures are counted per email and per source ip and answered with a *LockedError once over the policy.
+//A wrong password for a known email still returns a Login carrying the account id, so it can be audited.
+func (u Users) LoginUser(ctx context.Context, email, pass, sourceIP string) (*Login, error) {
+	now := time.Now()
+	err := u.checkLockout(ctx, email, sourceIP, now)
+	if err != nil {
+		return nil, err
+	}
+
 	//find account
-	println("before find account")
 	act, err := u.FindUserAccountByEmail(ctx, email)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	token := u.generateToken(ctx)
-	//update account
-	_, err = u.s.UpdateUserAccountToken(ctx, act.ID, token)
-	if err != nil {
-		return "", err
+	if act == nil || !u.passMatches(ctx, act.Password, pass) {
+		err = u.recordLoginFailure(ctx, email, sourceIP, now)
+		if err != nil {
+			return nil, err
+		}
+		if act == nil {
+			return nil, ErrInvalidCredentials
+		}
+		return &Login{UserID: act.ID}, ErrInvalidCredentials
 	}
-	println("after update")
 
-	return token, nil
+	//counters are only cleared once a session starts so wrong codes keep adding up
+	if !act.TwoFactor.Enabled {
+		err = u.clearLoginFailures(ctx, email)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return u.loginAccount(ctx, act)
 }
 
 func (u Users) LogoutUserByID(ctx context.Context, ID string) error {
-	_, err := u.s.UpdateUserAccountToken(ctx, ID, "howdoisecurethis")
+	return u.InvalidateSessions(ctx, ID)
+}
+
+//InvalidateSessions replaces the session token with a secret that is never handed out
+func (u Users) InvalidateSessions(ctx context.Context, ID string) error {
+	token, err := u.generateSecret(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = u.s.UpdateUserAccountToken(ctx, ID, token)
 	if err != nil {
 		return err
 	}
@@ -75,17 +182,130 @@ func (u Users) LogoutUserByID(ctx context.Context, ID string) error {
 	return nil
 }
 
+//ChangePassword also signs the account out everywhere
+func (u Users) ChangePassword(ctx context.Context, ID, pass string) error {
+	p, err := u.passEncrypt(ctx, pass)
+	if err != nil {
+		return err
+	}
+
+	_, err = u.s.UpdateUserAccountPassword(ctx, ID, p)
+	if err != nil {
+		return err
+	}
+
+	return u.InvalidateSessions(ctx, ID)
+}
+
+//ChangeOwnPassword asks for the current password before changing it, wrong ones count towards
+//the lockout the same as on the login page
+func (u Users) ChangeOwnPassword(ctx context.Context, act *users.UserAccount, current, pass, sourceIP string) error {
+	now := time.Now()
+	err := u.checkLockout(ctx, act.Email, sourceIP, now)
+	if err != nil {
+		return err
+	}
+
+	if !u.passMatches(ctx, act.Password, current) {
+		err = u.recordLoginFailure(ctx, act.Email, sourceIP, now)
+		if err != nil {
+			return err
+		}
+		return ErrInvalidCredentials
+	}
+
+	return u.ChangePassword(ctx, act.ID, pass)
+}
+
 func (u Users) RemoveUserAccountByID(ctx context.Context, ID string) error {
 	return u.s.RemoveUserAccountByID(ctx, ID)
 }
 
+//ResetCalendarToken issues a new secret for the calendar feed, which invalidates any previously shared URL
+func (u Users) ResetCalendarToken(ctx context.Context, ID string) (string, error) {
+	token, err := u.generateSecret(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = u.s.UpdateUserAccountCalendarToken(ctx, ID, token)
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+//StartSession signs in an account that was just created, there are no failed logins to check yet
+func (u Users) StartSession(ctx context.Context, ID string) (string, error) {
+	return u.startSession(ctx, ID)
+}
+
 //Helpers
 
+//loginAccount is the last step of every sign in method, accounts with 2FA on get a challenge instead of a session
+func (u Users) loginAccount(ctx context.Context, act *users.UserAccount) (*Login, error) {
+	if act.Disabled {
+		return nil, ErrAccountDisabled
+	}
+
+	if act.TwoFactor.Enabled {
+		challenge, err := u.createToken(ctx, act.ID, PurposeLoginChallenge, loginChallengeTTL)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Login{UserID: act.ID, Challenge: challenge, DeleteAfter: act.DeleteAfter}, nil
+	}
+
+	token, err := u.startSession(ctx, act.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Login{UserID: act.ID, Token: token, DeleteAfter: act.DeleteAfter}, nil
+}
+
+func (u Users) startSession(ctx context.Context, ID string) (string, error) {
+	token := u.generateToken(ctx)
+	//update account
+	_, err := u.s.UpdateUserAccountToken(ctx, ID, token)
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
 func (u Users) passEncrypt(ctx context.Context, pass string) (string, error) {
-	//TODO ENCRYPT PASSWORD!!
-	return pass, nil
+	p, err := bcrypt.GenerateFromPassword([]byte(pass), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(p), nil
+}
+
+func (u Users) passMatches(ctx context.Context, hash, pass string) bool {
+	//accounts created before hashing still hold the plain password
+	if !strings.HasPrefix(hash, "$2") {
+		return subtle.ConstantTimeCompare([]byte(hash), []byte(pass)) == 1
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(pass)) == nil
 }
 
 func (u Users) generateToken(ctx context.Context) string {
 	return ksuid.New().String()
 }
+
+//generateSecret is for tokens that end up in URLs, so they must not be guessable
+func (u Users) generateSecret(ctx context.Context) (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,231 @@
+package users
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/segmentio/ksuid"
+)
+
+const (
+	ScopeReadLibrary  = "library:read"
+	ScopeWriteLibrary = "library:write"
+	ScopeReadProfile  = "profile:read"
+)
+
+const (
+	MaxAPIKeys = 10
+
+	//keys look like fpk_<id>_<secret>, ksuids have no underscore so the id splits off cleanly
+	apiKeyPrefix = "fpk_"
+	//lastUsedAt is only written this often so a busy script isn't a write per request
+	apiKeyUsageInterval = time.Minute
+)
+
+//APIKeyScopes is every scope a personal key can be given
+var APIKeyScopes = []string{ScopeReadLibrary, ScopeWriteLibrary, ScopeReadProfile}
+
+var (
+	ErrInvalidScope   = errors.New("scope does not exist")
+	ErrScopeRequired  = errors.New("at least one scope is required")
+	ErrTooManyAPIKeys = errors.New("account has the maximum number of api keys")
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
+
+//IsAPIKey tells API keys apart from session tokens in an Authorization header
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, apiKeyPrefix)
+}
+
+//CreateAPIKey returns the key once, only its hash is kept. The key acts as profileID, or the owner
+//when empty, and a zero ttl never expires.
+func (u Users) CreateAPIKey(ctx context.Context, act *users.UserAccount, name, profileID string, scopes []string, ttl time.Duration) (string, *users.APIKey, error) {
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if profileID == "" {
+		profileID = act.ID
+	}
+
+	_, err = u.findProfile(ctx, act.ID, profileID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	existing, err := u.s.FindAPIKeysByUserID(ctx, act.ID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(*existing) >= MaxAPIKeys {
+		return "", nil, ErrTooManyAPIKeys
+	}
+
+	secret, err := u.generateSecret(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	k := users.APIKey{
+		ID:        ksuid.New().String(),
+		UserID:    act.ID,
+		ProfileID: profileID,
+		Name:      name,
+		Scopes:    scopes,
+		CreatedAt: now.Unix(),
+	}
+	if ttl > 0 {
+		k.ExpiresAt = now.Add(ttl).Unix()
+	}
+
+	key := apiKeyPrefix + k.ID + "_" + secret
+	k.SecretHash = hashToken(key)
+
+	err = u.s.CreateAPIKey(ctx, k)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, &k, nil
+}
+
+//FindAPIKeys lists the account's keys newest first, run them through PublicAPIKey before returning them
+func (u Users) FindAPIKeys(ctx context.Context, act *users.UserAccount) (*[]users.APIKey, error) {
+	keys, err := u.s.FindAPIKeysByUserID(ctx, act.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(*keys, func(i, j int) bool {
+		return (*keys)[i].CreatedAt > (*keys)[j].CreatedAt
+	})
+
+	return keys, nil
+}
+
+//RevokeAPIKey deletes the key, it stops working on the next request
+func (u Users) RevokeAPIKey(ctx context.Context, act *users.UserAccount, ID string) error {
+	k, err := u.s.FindAPIKeyByID(ctx, ID)
+	if err != nil {
+		return err
+	}
+
+	if k == nil || k.UserID != act.ID {
+		return ErrAPIKeyNotFound
+	}
+
+	return u.s.RemoveAPIKeyByID(ctx, ID)
+}
+
+//PublicAPIKey strips the secret hash before a key is returned to a client
+func PublicAPIKey(k users.APIKey) users.APIKey {
+	k.SecretHash = ""
+	return k
+}
+
+//FindViewerForScope is FindViewerByToken for lambdas that also serve API keys. Session tokens
+//pass as before, API keys must carry the scope.
+func (u Users) FindViewerForScope(ctx context.Context, token, scope string) (*Viewer, error) {
+	if !IsAPIKey(token) {
+		return u.FindViewerByToken(ctx, token)
+	}
+
+	v, err := u.findViewerByAPIKey(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !v.HasScope(scope) {
+		return nil, ErrForbidden
+	}
+
+	return v, nil
+}
+
+//Helpers
+
+func (u Users) findViewerByAPIKey(ctx context.Context, key string) (*Viewer, error) {
+	parts := strings.SplitN(strings.TrimPrefix(key, apiKeyPrefix), "_", 2)
+	if len(parts) != 2 {
+		return nil, ErrUnauthorized
+	}
+
+	k, err := u.s.FindAPIKeyByID(ctx, parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if k == nil || subtle.ConstantTimeCompare([]byte(k.SecretHash), []byte(hashToken(key))) != 1 {
+		return nil, ErrUnauthorized
+	}
+
+	now := time.Now()
+	if k.ExpiresAt != 0 && now.Unix() > k.ExpiresAt {
+		return nil, ErrUnauthorized
+	}
+
+	//keys outlive password changes, but not a disabled account or one waiting to be deleted
+	act, err := u.s.FindUserAccountByID(ctx, k.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if act == nil || act.Disabled || act.DeleteAfter != 0 {
+		return nil, ErrUnauthorized
+	}
+
+	prof, err := u.findProfile(ctx, act.ID, k.ProfileID)
+	if err == ErrProfileNotFound {
+		return nil, ErrUnauthorized
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if now.Sub(time.Unix(k.LastUsedAt, 0)) > apiKeyUsageInterval {
+		err = u.s.UpdateAPIKeyLastUsed(ctx, k.ID, now.Unix())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Viewer{Account: act, Profile: prof, APIKeyID: k.ID, Scopes: k.Scopes}, nil
+}
+
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, ErrScopeRequired
+	}
+
+	seen := make(map[string]bool)
+	normalized := make([]string, 0)
+	for _, s := range scopes {
+		if seen[s] {
+			continue
+		}
+
+		known := false
+		for _, k := range APIKeyScopes {
+			if s == k {
+				known = true
+			}
+		}
+
+		if !known {
+			return nil, ErrInvalidScope
+		}
+
+		seen[s] = true
+		normalized = append(normalized, s)
+	}
+
+	return normalized, nil
+}
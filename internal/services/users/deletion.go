@@ -0,0 +1,248 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+)
+
+var ErrDeletionNotScheduled = errors.New("account is not scheduled for deletion")
+
+//AccountExport is everything held about an account, secrets like the password hash, session
+//tokens, the TOTP secret, profile PINs and API key hashes are left out
+type AccountExport struct {
+	Account    ExportedAccount     `json:"account"`
+	Profiles   []users.UserProfile `json:"profiles"`
+	Follows    []users.Follow      `json:"follows"`
+	Identities []users.Identity    `json:"identities"`
+	APIKeys    []users.APIKey      `json:"apiKeys"`
+	Lists      []users.List        `json:"lists"`
+}
+
+type ExportedAccount struct {
+	ID          string `json:"id"`
+	Email       string `json:"email"`
+	Verified    bool   `json:"verified"`
+	Role        string `json:"role,omitempty"`
+	TwoFactor   bool   `json:"twoFactor"`
+	Disabled    bool   `json:"disabled,omitempty"`
+	DeleteAfter int64  `json:"deleteAfter,omitempty"`
+}
+
+//ScheduleAccountDeletion asks for the password again, and a second factor when 2FA is on, then signs
+//the account out everywhere. Nothing is removed until PurgeAccount runs after the grace period.
+func (u Users) ScheduleAccountDeletion(ctx context.Context, act *users.UserAccount, pass, code, sourceIP string, grace time.Duration) (time.Time, error) {
+	now := time.Now()
+	err := u.checkLockout(ctx, act.Email, sourceIP, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	//wrong passwords here count the same as on the login page
+	if !u.passMatches(ctx, act.Password, pass) {
+		err = u.recordLoginFailure(ctx, act.Email, sourceIP, now)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Time{}, ErrInvalidCredentials
+	}
+
+	if act.TwoFactor.Enabled {
+		err = u.checkSecondFactor(ctx, act, code)
+		if err == ErrInvalidCode {
+			recordErr := u.recordLoginFailure(ctx, act.Email, sourceIP, now)
+			if recordErr != nil {
+				return time.Time{}, recordErr
+			}
+		}
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	deleteAfter := now.Add(grace)
+	err = u.s.UpdateUserAccountDeleteAfter(ctx, act.ID, deleteAfter.Unix())
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	err = u.InvalidateSessions(ctx, act.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return deleteAfter, nil
+}
+
+func (u Users) CancelAccountDeletion(ctx context.Context, act *users.UserAccount) error {
+	if act.DeleteAfter == 0 {
+		return ErrDeletionNotScheduled
+	}
+
+	return u.s.UpdateUserAccountDeleteAfter(ctx, act.ID, 0)
+}
+
+func (u Users) FindAccountsDueForDeletion(ctx context.Context, now time.Time) (*[]users.UserAccount, error) {
+	return u.s.FindUserAccountsDueForDeletion(ctx, now.Unix())
+}
+
+//PurgeAccount removes the account and everything hanging off it. The account goes last so a run
+//that fails part way finds it again on the next schedule and picks up where it stopped.
+func (u Users) PurgeAccount(ctx context.Context, act *users.UserAccount) error {
+	sessions, err := u.s.FindProfileSessionsByAccountID(ctx, act.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, ps := range *sessions {
+		err = u.s.RemoveProfileSessionByID(ctx, ps.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	tokens, err := u.s.FindTokensByUserID(ctx, act.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, t := range *tokens {
+		err = u.s.RemoveTokenByID(ctx, t.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	follows, err := u.s.FindFollowsByUserID(ctx, act.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range *follows {
+		err = u.s.RemoveFollowByID(ctx, f.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	identities, err := u.s.FindIdentitiesByUserID(ctx, act.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range *identities {
+		err = u.s.RemoveIdentityByID(ctx, i.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	keys, err := u.s.FindAPIKeysByUserID(ctx, act.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range *keys {
+		err = u.s.RemoveAPIKeyByID(ctx, k.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	lists, err := u.s.FindListsByAccountID(ctx, act.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range *lists {
+		err = u.s.RemoveListByID(ctx, l.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	//libraries and watch history live on the profiles
+	profiles, err := u.FindViewerProfiles(ctx, act)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range *profiles {
+		err = u.s.RemoveLoginAttemptsByID(ctx, pinAttemptsID(p.ID))
+		if err != nil {
+			return err
+		}
+
+		err = u.s.RemoveUserProfileByID(ctx, p.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = u.clearLoginFailures(ctx, act.Email)
+	if err != nil {
+		return err
+	}
+
+	return u.s.RemoveUserAccountByID(ctx, act.ID)
+}
+
+//ExportAccount gathers what the users service holds, notifications and the audit log are added by the caller
+func (u Users) ExportAccount(ctx context.Context, act *users.UserAccount) (*AccountExport, error) {
+	profiles, err := u.FindViewerProfiles(ctx, act)
+	if err != nil {
+		return nil, err
+	}
+
+	follows, err := u.s.FindFollowsByUserID(ctx, act.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := u.s.FindIdentitiesByUserID(ctx, act.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := u.FindAPIKeys(ctx, act)
+	if err != nil {
+		return nil, err
+	}
+
+	lists, err := u.s.FindListsByAccountID(ctx, act.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := AccountExport{
+		Account: ExportedAccount{
+			ID:          act.ID,
+			Email:       act.Email,
+			Verified:    act.Verified,
+			Role:        act.Role,
+			TwoFactor:   act.TwoFactor.Enabled,
+			Disabled:    act.Disabled,
+			DeleteAfter: act.DeleteAfter,
+		},
+		Profiles:   make([]users.UserProfile, 0, len(*profiles)),
+		Follows:    *follows,
+		Identities: *identities,
+		APIKeys:    make([]users.APIKey, 0, len(*keys)),
+		Lists:      *lists,
+	}
+
+	for _, p := range *profiles {
+		p = PublicProfile(p)
+		//the webhook secret signs deliveries, the URL is the user's own data
+		p.Notifications.WebhookSecret = ""
+		export.Profiles = append(export.Profiles, p)
+	}
+
+	for _, k := range *keys {
+		export.APIKeys = append(export.APIKeys, PublicAPIKey(k))
+	}
+
+	return &export, nil
+}
@@ -0,0 +1,43 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+)
+
+func (u Users) FollowShow(ctx context.Context, userID, showID, lastAirDate string) error {
+	f := users.Follow{
+		ID:           followID(userID, showID),
+		UserID:       userID,
+		ShowID:       showID,
+		LastNotified: lastAirDate,
+		CreatedAt:    time.Now().Unix(),
+	}
+	return u.s.CreateFollow(ctx, f)
+}
+
+func (u Users) UnfollowShow(ctx context.Context, userID, showID string) error {
+	return u.s.RemoveFollowByID(ctx, followID(userID, showID))
+}
+
+func (u Users) FindFollowsByUserID(ctx context.Context, userID string) (*[]users.Follow, error) {
+	return u.s.FindFollowsByUserID(ctx, userID)
+}
+
+func (u Users) FindAllFollows(ctx context.Context) (*[]users.Follow, error) {
+	return u.s.FindAllFollows(ctx)
+}
+
+func (u Users) MarkFollowNotified(ctx context.Context, ID, airDate string) error {
+	return u.s.UpdateFollowLastNotified(ctx, ID, airDate)
+}
+
+//Helpers
+
+//one follow per user and show, so following twice just overwrites
+func followID(userID, showID string) string {
+	return fmt.Sprintf("%s:%s", userID, showID)
+}
@@ -0,0 +1,219 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/pkg/oidc"
+	"github.com/segmentio/ksuid"
+)
+
+var (
+	ErrIdentityNotLinked = errors.New("an account with this email exists, sign in and link the provider from settings")
+	ErrIdentityLinked    = errors.New("identity is already linked to another account")
+	ErrIdentityNotFound  = errors.New("identity not found")
+	ErrEmailRequired     = errors.New("provider did not share an email address")
+)
+
+type OIDCLogin struct {
+	Login    *Login
+	Identity users.Identity
+	//Created is set when the sign in made a new account
+	Created bool
+	//Linked is set when the flow was started by a signed in user to add the identity
+	Linked bool
+}
+
+//StartOIDCLogin returns the provider URL to send the user to. Passing linkUserID adds the
+//identity to that account instead of signing in.
+func (u Users) StartOIDCLogin(ctx context.Context, p *oidc.Provider, linkUserID string) (string, error) {
+	verifier, err := oidc.NewVerifier()
+	if err != nil {
+		return "", err
+	}
+
+	nonce, err := oidc.NewNonce()
+	if err != nil {
+		return "", err
+	}
+
+	//the state handed to the provider is the token secret, the verifier never leaves our table
+	state, err := u.createTokenWithData(ctx, linkUserID, PurposeOIDCLogin, oidcLoginTTL, map[string]string{
+		"provider": p.Name(),
+		"nonce":    nonce,
+		"verifier": verifier,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return p.AuthCodeURL(state, nonce, verifier), nil
+}
+
+//CompleteOIDCLogin handles the provider redirect. Known identities sign in, unknown ones create
+//an account, unless the email already belongs to an account; that has to be linked while signed in
+//so an unverified provider email can't take over an existing account.
+func (u Users) CompleteOIDCLogin(ctx context.Context, p *oidc.Provider, state, code string) (*OIDCLogin, error) {
+	t, err := u.consumeToken(ctx, state, PurposeOIDCLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Data["provider"] != p.Name() {
+		return nil, ErrInvalidToken
+	}
+
+	tokens, err := p.Exchange(ctx, code, t.Data["verifier"])
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := p.VerifyIDToken(ctx, tokens.IDToken, t.Data["nonce"], time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	ID := identityID(p.Config.Issuer, claims.Subject)
+	existing, err := u.s.FindIdentityByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.UserID != "" {
+		return u.linkIdentity(ctx, t.UserID, p, claims, existing)
+	}
+
+	if existing != nil {
+		act, err := u.s.FindUserAccountByID(ctx, existing.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		if act == nil {
+			return nil, ErrIdentityNotFound
+		}
+
+		login, err := u.loginAccount(ctx, act)
+		if err != nil {
+			return nil, err
+		}
+
+		return &OIDCLogin{Login: login, Identity: *existing}, nil
+	}
+
+	return u.signupWithIdentity(ctx, p, claims)
+}
+
+func (u Users) FindIdentitiesByUserID(ctx context.Context, userID string) (*[]users.Identity, error) {
+	return u.s.FindIdentitiesByUserID(ctx, userID)
+}
+
+//UnlinkIdentity leaves the password in place, accounts created through a provider can set one with a password reset
+func (u Users) UnlinkIdentity(ctx context.Context, userID, ID string) error {
+	i, err := u.s.FindIdentityByID(ctx, ID)
+	if err != nil {
+		return err
+	}
+
+	if i == nil || i.UserID != userID {
+		return ErrIdentityNotFound
+	}
+
+	return u.s.RemoveIdentityByID(ctx, ID)
+}
+
+//Helpers
+
+func (u Users) linkIdentity(ctx context.Context, userID string, p *oidc.Provider, claims *oidc.Claims, existing *users.Identity) (*OIDCLogin, error) {
+	if existing != nil {
+		if existing.UserID != userID {
+			return nil, ErrIdentityLinked
+		}
+
+		return &OIDCLogin{Identity: *existing, Linked: true}, nil
+	}
+
+	i := newIdentity(userID, p, claims)
+	err := u.s.CreateIdentity(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDCLogin{Identity: i, Linked: true}, nil
+}
+
+func (u Users) signupWithIdentity(ctx context.Context, p *oidc.Provider, claims *oidc.Claims) (*OIDCLogin, error) {
+	if claims.Email == "" {
+		return nil, ErrEmailRequired
+	}
+
+	act, err := u.FindUserAccountByEmail(ctx, claims.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if act != nil {
+		return nil, ErrIdentityNotLinked
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = claims.Email
+	}
+
+	ID := ksuid.New().String()
+	err = u.CreateUserProfile(ctx, ID, name, claims.Email, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	//nobody knows this password, the provider is how this account signs in
+	pass, err := u.generateSecret(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.CreateUserAccount(ctx, ID, claims.Email, pass)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.EmailVerified {
+		err = u.s.UpdateUserAccountVerified(ctx, ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	i := newIdentity(ID, p, claims)
+	err = u.s.CreateIdentity(ctx, i)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := u.startSession(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OIDCLogin{Login: &Login{UserID: ID, Token: token}, Identity: i, Created: true}, nil
+}
+
+func newIdentity(userID string, p *oidc.Provider, claims *oidc.Claims) users.Identity {
+	return users.Identity{
+		ID:        identityID(p.Config.Issuer, claims.Subject),
+		UserID:    userID,
+		Provider:  p.Name(),
+		Issuer:    p.Config.Issuer,
+		Subject:   claims.Subject,
+		Email:     claims.Email,
+		CreatedAt: time.Now().Unix(),
+	}
+}
+
+//identityID uses the issuer rather than our provider name so renaming a provider keeps its links
+func identityID(issuer, subject string) string {
+	return issuer + "|" + subject
+}
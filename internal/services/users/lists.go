@@ -0,0 +1,443 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/services/content"
+	"github.com/segmentio/ksuid"
+)
+
+const (
+	//VisibilityPrivate lists are only seen by their profile, unlisted ones by anyone with the slug
+	//and public ones are also in FindPublicLists
+	VisibilityPrivate  = "private"
+	VisibilityUnlisted = "unlisted"
+	VisibilityPublic   = "public"
+)
+
+const (
+	MaxLists              = 50
+	MaxListItems          = 500
+	MaxListNameLength     = 100
+	MaxListDescriptionLen = 1000
+	MaxListNoteLength     = 500
+	MaxPublicLists        = 100
+
+	//the random part keeps unlisted slugs from being guessed from the name
+	listSlugSecretLength = 12
+	listSlugNameLength   = 40
+)
+
+//ListVisibilities is every visibility a list can have
+var ListVisibilities = []string{VisibilityPrivate, VisibilityUnlisted, VisibilityPublic}
+
+var (
+	ErrListNotFound      = errors.New("list not found")
+	ErrTooManyLists      = errors.New("profile has the maximum number of lists")
+	ErrTooManyListItems  = errors.New("list has the maximum number of items")
+	ErrListNameRequired  = errors.New("list name is required")
+	ErrListNameTooLong   = errors.New("list name is too long")
+	ErrListDescTooLong   = errors.New("list description is too long")
+	ErrListNoteTooLong   = errors.New("list item note is too long")
+	ErrInvalidVisibility = errors.New("visibility must be private, unlisted or public")
+	ErrInvalidListItem   = errors.New("list items need an ID and a type of movie or tv")
+	ErrDuplicateListItem = errors.New("title is already in the list")
+	ErrListItemNotFound  = errors.New("title is not in the list")
+)
+
+//ListChanges are the edits UpdateList makes, nil fields are left alone. Items replaces the whole
+//list so it also sets the order, titles already in the list keep their AddedAt.
+type ListChanges struct {
+	Name        *string
+	Description *string
+	Visibility  *string
+	Items       *[]users.ListItem
+}
+
+//CreateList starts an empty list on the profile, private when visibility is empty
+func (u Users) CreateList(ctx context.Context, prof *users.UserProfile, name, description, visibility string) (*users.List, error) {
+	if visibility == "" {
+		visibility = VisibilityPrivate
+	}
+
+	err := checkListDetails(name, description, visibility)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := u.s.FindListsByProfileID(ctx, prof.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*existing) >= MaxLists {
+		return nil, ErrTooManyLists
+	}
+
+	secret, err := u.generateSecret(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	//the owner's profile has no accountId when it predates households
+	accountID := prof.AccountID
+	if accountID == "" {
+		accountID = prof.ID
+	}
+
+	now := time.Now().Unix()
+	l := users.List{
+		ID:          ksuid.New().String(),
+		AccountID:   accountID,
+		ProfileID:   prof.ID,
+		Name:        strings.TrimSpace(name),
+		Description: description,
+		Visibility:  visibility,
+		Slug:        listSlug(name, secret),
+		Items:       make([]users.ListItem, 0),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	err = u.s.CreateList(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+
+	return &l, nil
+}
+
+//FindLists returns the profile's lists, most recently changed first
+func (u Users) FindLists(ctx context.Context, prof *users.UserProfile) (*[]users.List, error) {
+	lists, err := u.s.FindListsByProfileID(ctx, prof.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(*lists, func(i, j int) bool {
+		return (*lists)[i].UpdatedAt > (*lists)[j].UpdatedAt
+	})
+
+	return lists, nil
+}
+
+//FindList only returns lists that belong to the profile, whatever their visibility
+func (u Users) FindList(ctx context.Context, prof *users.UserProfile, ID string) (*users.List, error) {
+	l, err := u.s.FindListByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if l == nil || l.ProfileID != prof.ID {
+		return nil, ErrListNotFound
+	}
+
+	return l, nil
+}
+
+//FindSharedList is the signed out view of a list, private lists are reported as not found
+func (u Users) FindSharedList(ctx context.Context, slug string) (*users.List, error) {
+	l, err := u.s.FindListBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	if l == nil || l.Visibility == VisibilityPrivate {
+		return nil, ErrListNotFound
+	}
+
+	return l, nil
+}
+
+//FindPublicLists returns up to limit public lists, most recently changed first
+func (u Users) FindPublicLists(ctx context.Context, limit int) (*[]users.List, error) {
+	lists, err := u.s.FindListsByVisibility(ctx, VisibilityPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(*lists, func(i, j int) bool {
+		return (*lists)[i].UpdatedAt > (*lists)[j].UpdatedAt
+	})
+
+	if len(*lists) > limit {
+		*lists = (*lists)[:limit]
+	}
+
+	return lists, nil
+}
+
+//UpdateList applies the changes in one write, the slug stays the same so shared links keep working
+func (u Users) UpdateList(ctx context.Context, prof *users.UserProfile, ID string, c ListChanges) (*users.List, error) {
+	l, err := u.FindList(ctx, prof, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, description, visibility := l.Name, l.Description, l.Visibility
+	if c.Name != nil {
+		name = *c.Name
+	}
+	if c.Description != nil {
+		description = *c.Description
+	}
+	if c.Visibility != nil {
+		visibility = *c.Visibility
+	}
+
+	err = checkListDetails(name, description, visibility)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Name = strings.TrimSpace(name)
+	l.Description = description
+	l.Visibility = visibility
+
+	now := time.Now().Unix()
+	if c.Items != nil {
+		added := make(map[string]int64)
+		for _, i := range l.Items {
+			added[titleKey(i.Type, i.ID)] = i.AddedAt
+		}
+
+		items := make([]users.ListItem, 0, len(*c.Items))
+		seen := make(map[string]bool)
+		for _, i := range *c.Items {
+			err = checkListItem(i)
+			if err != nil {
+				return nil, err
+			}
+
+			key := titleKey(i.Type, i.ID)
+			if seen[key] {
+				return nil, ErrDuplicateListItem
+			}
+			seen[key] = true
+
+			i.AddedAt = now
+			if at, ok := added[key]; ok {
+				i.AddedAt = at
+			}
+			items = append(items, i)
+		}
+
+		if len(items) > MaxListItems {
+			return nil, ErrTooManyListItems
+		}
+		l.Items = items
+	}
+
+	l.UpdatedAt = now
+	err = u.s.CreateList(ctx, *l)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+//AddToList puts the title at the end of the list
+func (u Users) AddToList(ctx context.Context, prof *users.UserProfile, ID string, item users.ListItem) (*users.List, error) {
+	err := checkListItem(item)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := u.FindList(ctx, prof, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range l.Items {
+		if titleKey(i.Type, i.ID) == titleKey(item.Type, item.ID) {
+			return nil, ErrDuplicateListItem
+		}
+	}
+
+	if len(l.Items) >= MaxListItems {
+		return nil, ErrTooManyListItems
+	}
+
+	now := time.Now().Unix()
+	item.AddedAt = now
+	l.Items = append(l.Items, item)
+	l.UpdatedAt = now
+
+	err = u.s.CreateList(ctx, *l)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+//AddAllToList puts the titles at the end of the list in order, titles already in it stay where they are
+func (u Users) AddAllToList(ctx context.Context, prof *users.UserProfile, ID string, items []users.ListItem) (*users.List, error) {
+	l, err := u.FindList(ctx, prof, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, i := range l.Items {
+		seen[titleKey(i.Type, i.ID)] = true
+	}
+
+	now := time.Now().Unix()
+	for _, item := range items {
+		err = checkListItem(item)
+		if err != nil {
+			return nil, err
+		}
+
+		key := titleKey(item.Type, item.ID)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		item.AddedAt = now
+		l.Items = append(l.Items, item)
+	}
+
+	if len(l.Items) > MaxListItems {
+		return nil, ErrTooManyListItems
+	}
+
+	l.UpdatedAt = now
+	err = u.s.CreateList(ctx, *l)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (u Users) RemoveFromList(ctx context.Context, prof *users.UserProfile, ID string, item users.ListItem) (*users.List, error) {
+	l, err := u.FindList(ctx, prof, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]users.ListItem, 0, len(l.Items))
+	for _, i := range l.Items {
+		if titleKey(i.Type, i.ID) != titleKey(item.Type, item.ID) {
+			items = append(items, i)
+		}
+	}
+
+	if len(items) == len(l.Items) {
+		return nil, ErrListItemNotFound
+	}
+
+	l.Items = items
+	l.UpdatedAt = time.Now().Unix()
+
+	err = u.s.CreateList(ctx, *l)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (u Users) RemoveList(ctx context.Context, prof *users.UserProfile, ID string) error {
+	_, err := u.FindList(ctx, prof, ID)
+	if err != nil {
+		return err
+	}
+
+	return u.s.RemoveListByID(ctx, ID)
+}
+
+//SharedList strips the account and profile IDs before a list is shown to someone other than its owner
+func SharedList(l users.List) users.List {
+	l.AccountID = ""
+	l.ProfileID = ""
+	return l
+}
+
+//Helpers
+
+func (u Users) removeListsByProfileID(ctx context.Context, profileID string) error {
+	lists, err := u.s.FindListsByProfileID(ctx, profileID)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range *lists {
+		err = u.s.RemoveListByID(ctx, l.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkListDetails(name, description, visibility string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrListNameRequired
+	}
+
+	if len(name) > MaxListNameLength {
+		return ErrListNameTooLong
+	}
+
+	if len(description) > MaxListDescriptionLen {
+		return ErrListDescTooLong
+	}
+
+	for _, v := range ListVisibilities {
+		if visibility == v {
+			return nil
+		}
+	}
+
+	return ErrInvalidVisibility
+}
+
+func checkListItem(i users.ListItem) error {
+	if i.ID == 0 || (i.Type != content.MediaMovie && i.Type != content.MediaTV) {
+		return ErrInvalidListItem
+	}
+
+	if len(i.Note) > MaxListNoteLength {
+		return ErrListNoteTooLong
+	}
+
+	return nil
+}
+
+//listSlug is the name lower cased with dashes then the start of the secret, eg date-night-Xk2f9aQ0pLm3
+func listSlug(name, secret string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if b.Len() >= listSlugNameLength {
+			break
+		}
+
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if b.Len() > 0 && !dash {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		return secret[:listSlugSecretLength]
+	}
+
+	return slug + "-" + secret[:listSlugSecretLength]
+}
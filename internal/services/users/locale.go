@@ -0,0 +1,47 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/services/content"
+)
+
+var ErrInvalidLocale = errors.New("language must be a two letter code like es and region a country code like MX")
+
+//LocaleFor is the profile's own language and region without defaults, so a request can still fill
+//them in. The parental controls country stands in for a region the profile hasn't set.
+func LocaleFor(prof *users.UserProfile) content.Locale {
+	l := content.Locale{
+		Language: prof.Language,
+		Region:   prof.Region,
+	}
+
+	if l.Region == "" && prof.Parental != nil {
+		l.Region = prof.Parental.Country
+	}
+
+	return l
+}
+
+//UpdateLocale sets the language and region the profile sees content in, empty clears either
+func (u Users) UpdateLocale(ctx context.Context, prof *users.UserProfile, language, region string) (*users.UserProfile, error) {
+	if language != "" && !content.ValidLanguage(language) {
+		return nil, ErrInvalidLocale
+	}
+
+	if region != "" && !content.ValidRegion(region) {
+		return nil, ErrInvalidLocale
+	}
+
+	prof.Language = strings.ToLower(language)
+	prof.Region = strings.ToUpper(region)
+	err := u.s.CreateUserProfile(ctx, *prof)
+	if err != nil {
+		return nil, err
+	}
+
+	return prof, nil
+}
@@ -0,0 +1,158 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+//LockoutPolicy is read from the LOCKOUT_ env of every lambda that checks a password, code or pin,
+//so they all count failures the same way
+type LockoutPolicy struct {
+	//failures before each attempt has to wait, doubling from BaseDelay up to MaxDelay
+	DelayAfter int           `default:"3" envconfig:"LOCKOUT_DELAY_AFTER"`
+	BaseDelay  time.Duration `default:"1s" envconfig:"LOCKOUT_BASE_DELAY"`
+	MaxDelay   time.Duration `default:"1m" envconfig:"LOCKOUT_MAX_DELAY"`
+	//failures before the account or source ip is locked out for LockoutDuration
+	AccountThreshold int           `default:"10" envconfig:"LOCKOUT_THRESHOLD"`
+	IPThreshold      int           `default:"50" envconfig:"LOCKOUT_IP_THRESHOLD"`
+	LockoutDuration  time.Duration `default:"15m" envconfig:"LOCKOUT_DURATION"`
+	//counters are forgotten after this long without a failure
+	Window time.Duration `default:"1h" envconfig:"LOCKOUT_WINDOW"`
+}
+
+var DefaultLockoutPolicy = LockoutPolicy{
+	DelayAfter:       3,
+	BaseDelay:        time.Second,
+	MaxDelay:         time.Minute,
+	AccountThreshold: 10,
+	IPThreshold:      50,
+	LockoutDuration:  15 * time.Minute,
+	Window:           time.Hour,
+}
+
+//LockedError is returned while an account or source ip has to wait before trying again
+type LockedError struct {
+	Until  time.Time
+	Locked bool
+}
+
+func (e *LockedError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed attempts, locked until %s", e.Until.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("too many failed attempts, retry after %s", e.Until.Format(time.RFC3339))
+}
+
+func (e *LockedError) RetryAfter(now time.Time) time.Duration {
+	d := e.Until.Sub(now)
+	if d < time.Second {
+		return time.Second
+	}
+	return d
+}
+
+//Helpers
+
+func accountAttemptsID(email string) string {
+	return "account:" + NormalizeEmail(email)
+}
+
+func ipAttemptsID(sourceIP string) string {
+	return "ip:" + sourceIP
+}
+
+//profile PINs are only four digits so they share the account lockout
+func pinAttemptsID(profileID string) string {
+	return "pin:" + profileID
+}
+
+func loginAttemptsIDs(email, sourceIP string) []string {
+	keys := []string{accountAttemptsID(email)}
+	if sourceIP != "" {
+		keys = append(keys, ipAttemptsID(sourceIP))
+	}
+	return keys
+}
+
+//checkLockout is keyed by email rather than account id so unknown emails behave the same as real ones
+func (u Users) checkLockout(ctx context.Context, email, sourceIP string, now time.Time) error {
+	return u.checkAttempts(ctx, loginAttemptsIDs(email, sourceIP), now)
+}
+
+func (u Users) recordLoginFailure(ctx context.Context, email, sourceIP string, now time.Time) error {
+	return u.recordFailures(ctx, loginAttemptsIDs(email, sourceIP), now)
+}
+
+func (u Users) checkAttempts(ctx context.Context, keys []string, now time.Time) error {
+	for _, key := range keys {
+		a, err := u.s.FindLoginAttemptsByID(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		if a != nil && a.LockedUntil > now.Unix() {
+			return &LockedError{
+				Until:  time.Unix(a.LockedUntil, 0),
+				Locked: a.Failures >= u.thresholdFor(key),
+			}
+		}
+	}
+
+	return nil
+}
+
+func (u Users) recordFailures(ctx context.Context, keys []string, now time.Time) error {
+	for _, key := range keys {
+		a, err := u.s.IncrementLoginFailures(ctx, key, now.Unix(), now.Add(u.lockout.Window).Unix())
+		if err != nil {
+			return err
+		}
+
+		wait := u.waitAfter(a.Failures, u.thresholdFor(key))
+		if wait == 0 {
+			continue
+		}
+
+		until := now.Add(wait)
+		err = u.s.UpdateLoginLockout(ctx, key, until.Unix(), until.Add(u.lockout.Window).Unix())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (u Users) clearLoginFailures(ctx context.Context, email string) error {
+	return u.s.RemoveLoginAttemptsByID(ctx, accountAttemptsID(email))
+}
+
+func (u Users) thresholdFor(key string) int {
+	if strings.HasPrefix(key, "ip:") {
+		return u.lockout.IPThreshold
+	}
+	return u.lockout.AccountThreshold
+}
+
+func (u Users) waitAfter(failures, threshold int) time.Duration {
+	p := u.lockout
+	if threshold > 0 && failures >= threshold {
+		return p.LockoutDuration
+	}
+
+	if failures < p.DelayAfter {
+		return 0
+	}
+
+	wait := p.BaseDelay
+	for i := p.DelayAfter; i < failures && wait < p.MaxDelay; i++ {
+		wait *= 2
+	}
+	if wait > p.MaxDelay {
+		wait = p.MaxDelay
+	}
+
+	return wait
+}
@@ -0,0 +1,135 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/services/content"
+)
+
+var (
+	ErrInvalidRating = errors.New("rating is not part of the country's rating system")
+	ErrNoOwnerPIN    = errors.New("the account owner's profile needs a pin to unlock titles")
+)
+
+//kidsControls apply to kids profiles until a parent sets their own
+var kidsControls = users.ParentalControls{
+	Country:      "US",
+	MaxMovie:     "PG",
+	MaxTV:        "TV-Y7",
+	BlockUnrated: true,
+}
+
+//RatingLimitFor is what the content service filters on for the profile, nil when it is unrestricted
+func RatingLimitFor(prof *users.UserProfile) *content.RatingLimit {
+	pc := prof.Parental
+	if pc == nil && prof.Kids {
+		pc = &kidsControls
+	}
+
+	if pc == nil {
+		return nil
+	}
+
+	return &content.RatingLimit{
+		Country:      pc.Country,
+		MaxMovie:     pc.MaxMovie,
+		MaxTV:        pc.MaxTV,
+		BlockUnrated: pc.BlockUnrated,
+		Allowed:      pc.Allowed,
+	}
+}
+
+//UpdateParentalControls replaces the profile's controls, nil removes them. Titles unlocked before are kept.
+func (u Users) UpdateParentalControls(ctx context.Context, act *users.UserAccount, ID string, pc *users.ParentalControls) (*users.UserProfile, error) {
+	prof, err := u.findProfile(ctx, act.ID, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pc != nil {
+		pc.Country = strings.ToUpper(pc.Country)
+		if _, ok := content.Certifications(pc.Country); !ok {
+			return nil, ErrInvalidRating
+		}
+
+		if pc.MaxMovie != "" && !content.ValidCertification(pc.Country, content.MediaMovie, pc.MaxMovie) {
+			return nil, ErrInvalidRating
+		}
+
+		if pc.MaxTV != "" && !content.ValidCertification(pc.Country, content.MediaTV, pc.MaxTV) {
+			return nil, ErrInvalidRating
+		}
+
+		if prof.Parental != nil {
+			pc.Allowed = prof.Parental.Allowed
+		} else {
+			pc.Allowed = nil
+		}
+	}
+
+	prof.Parental = pc
+	err = u.s.CreateUserProfile(ctx, *prof)
+	if err != nil {
+		return nil, err
+	}
+
+	return prof, nil
+}
+
+//UnlockTitle lets a parent allow one restricted title on a profile by entering the account owner's PIN
+func (u Users) UnlockTitle(ctx context.Context, v *Viewer, mediaType, ID, pin string) error {
+	owner, err := u.findProfile(ctx, v.Account.ID, v.Account.ID)
+	if err != nil {
+		return err
+	}
+
+	if owner.PIN == "" {
+		return ErrNoOwnerPIN
+	}
+
+	now := time.Now()
+	keys := []string{pinAttemptsID(owner.ID)}
+	err = u.checkAttempts(ctx, keys, now)
+	if err != nil {
+		return err
+	}
+
+	if !u.passMatches(ctx, owner.PIN, pin) {
+		err = u.recordFailures(ctx, keys, now)
+		if err != nil {
+			return err
+		}
+		return ErrInvalidPIN
+	}
+
+	err = u.s.RemoveLoginAttemptsByID(ctx, pinAttemptsID(owner.ID))
+	if err != nil {
+		return err
+	}
+
+	prof := v.Profile
+	if prof.Parental == nil && !prof.Kids {
+		//nothing is restricted on this profile
+		return nil
+	}
+
+	if prof.Parental == nil {
+		//a kids profile on the defaults gets them written out so the unlock has somewhere to live
+		pc := kidsControls
+		prof.Parental = &pc
+	}
+
+	key := content.TitleKey(mediaType, ID)
+	for _, a := range prof.Parental.Allowed {
+		if a == key {
+			return nil
+		}
+	}
+
+	prof.Parental.Allowed = append(prof.Parental.Allowed, key)
+	return u.s.CreateUserProfile(ctx, *prof)
+}
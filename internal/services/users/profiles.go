@@ -2,13 +2,25 @@ package users
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/pkg/webhooks"
+	"github.com/aschles4/finalProject/internal/services/content"
 )
 
+//MaxWatchHistory is the most watches a profile keeps, the oldest go first. The history lives on
+//the profile item and DynamoDB refuses items over 400 KB, which would fail every profile write.
+const MaxWatchHistory = 2000
+
 func (u Users) CreateUserProfile(ctx context.Context, ID, name, email string, acts []users.StreamAccounts) error {
+	//the owner's profile shares the account ID
 	p := users.UserProfile{
 		ID:             ID,
+		AccountID:      ID,
 		Name:           name,
 		Email:          email,
 		StreamAccounts: acts,
@@ -23,6 +35,174 @@ func (u Users) FindUserProfileByID(ctx context.Context, ID string) (*users.UserP
 	return u.s.FindUserProfileByID(ctx, ID)
 }
 
+//UpdateUserProfile overwrites the stored profile, callers should start from FindUserProfileByID
+func (u Users) UpdateUserProfile(ctx context.Context, profile users.UserProfile) error {
+	return u.s.CreateUserProfile(ctx, profile)
+}
+
 func (u Users) RemoveUserProfileByID(ctx context.Context, ID string) error {
 	return u.s.RemoveUserProfileByID(ctx, ID)
 }
+
+//UpdateNotificationPreferences keeps the existing webhook secret unless the webhook URL changes.
+//The URL has to be https and resolve to public addresses, see webhooks.CheckURL.
+func (u Users) UpdateNotificationPreferences(ctx context.Context, ID string, prefs users.NotificationPreferences) (*users.UserProfile, error) {
+	if prefs.WebhookURL != "" {
+		err := webhooks.CheckURL(ctx, prefs.WebhookURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	prof, err := u.s.FindUserProfileByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs.WebhookSecret = prof.Notifications.WebhookSecret
+	if prefs.WebhookURL == "" {
+		prefs.WebhookSecret = ""
+	} else if prefs.WebhookURL != prof.Notifications.WebhookURL || prefs.WebhookSecret == "" {
+		prefs.WebhookSecret, err = u.generateSecret(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return u.s.UpdateUserProfileNotifications(ctx, ID, prefs)
+}
+
+//AddToLibrary is a no-op for titles already in the profile's library, added is false then
+func (u Users) AddToLibrary(ctx context.Context, prof *users.UserProfile, c users.Content) (bool, error) {
+	for _, l := range prof.Library.ContentList {
+		if l.ID == c.ID && (l.Type == c.Type || l.Type == "") {
+			return false, nil
+		}
+	}
+
+	prof.Library.ContentList = append(prof.Library.ContentList, c)
+	err := u.s.CreateUserProfile(ctx, *prof)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+//MarkWatched adds to the front of the profile's history, newest first
+func (u Users) MarkWatched(ctx context.Context, prof *users.UserProfile, w users.Watched) error {
+	if w.WatchedAt == 0 {
+		w.WatchedAt = time.Now().Unix()
+	}
+
+	prof.WatchHistory = trimHistory(append([]users.Watched{w}, prof.WatchHistory...))
+	return u.s.CreateUserProfile(ctx, *prof)
+}
+
+//ImportToProfile adds an import's library titles and watches in one write. Titles already in
+//the library and watches already in the history are left out, and the history is trimmed to
+//MaxWatchHistory. It returns how many were added, not counting watches trimmed straight away.
+func (u Users) ImportToProfile(ctx context.Context, prof *users.UserProfile, library []users.Content, watched []users.Watched) (int, int, error) {
+	inLibrary := make(map[string]bool)
+	for _, c := range prof.Library.ContentList {
+		inLibrary[titleKey(c.Type, c.ID)] = true
+	}
+
+	addedLibrary := 0
+	for _, c := range library {
+		key := titleKey(c.Type, c.ID)
+		if inLibrary[key] {
+			continue
+		}
+		inLibrary[key] = true
+
+		prof.Library.ContentList = append(prof.Library.ContentList, c)
+		addedLibrary++
+	}
+
+	inHistory := make(map[string]bool)
+	for _, w := range prof.WatchHistory {
+		inHistory[watchedKey(w)] = true
+	}
+
+	added := make(map[string]bool)
+	for _, w := range watched {
+		if inHistory[watchedKey(w)] {
+			continue
+		}
+		inHistory[watchedKey(w)] = true
+		added[watchedKey(w)] = true
+
+		prof.WatchHistory = append(prof.WatchHistory, w)
+	}
+
+	if addedLibrary == 0 && len(added) == 0 {
+		return 0, 0, nil
+	}
+
+	//imported watches are older than most of the history, keep it newest first
+	sort.SliceStable(prof.WatchHistory, func(i, j int) bool {
+		return prof.WatchHistory[i].WatchedAt > prof.WatchHistory[j].WatchedAt
+	})
+	prof.WatchHistory = trimHistory(prof.WatchHistory)
+
+	addedWatched := 0
+	for _, w := range prof.WatchHistory {
+		if added[watchedKey(w)] {
+			addedWatched++
+		}
+	}
+
+	err := u.s.CreateUserProfile(ctx, *prof)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return addedLibrary, addedWatched, nil
+}
+
+//SeenContent is the set of titles in the profile's library or history keyed by content.TitleKey,
+//used to keep them out of recommendations. Movies and shows share TMDB IDs.
+func SeenContent(prof *users.UserProfile) map[string]bool {
+	seen := make(map[string]bool)
+	for _, c := range prof.Library.ContentList {
+		for _, key := range seenKeys(c.Type, c.ID) {
+			seen[key] = true
+		}
+	}
+	for _, w := range prof.WatchHistory {
+		for _, key := range seenKeys(w.Type, w.ID) {
+			seen[key] = true
+		}
+	}
+
+	return seen
+}
+
+//Helpers
+
+//watchedKey is one watch of a title, the same episode watched twice is two entries
+func watchedKey(w users.Watched) string {
+	return fmt.Sprintf("%s:%d:%d:%d", titleKey(w.Type, w.ID), w.Season, w.Episode, w.WatchedAt)
+}
+
+//titleKey is content.TitleKey for the int64 IDs profiles and lists keep
+func titleKey(typ string, ID int64) string {
+	return content.TitleKey(typ, strconv.FormatInt(ID, 10))
+}
+
+//seenKeys is the title's key, library entries from before titles had a type could be either
+func seenKeys(typ string, ID int64) []string {
+	if typ == "" {
+		return []string{titleKey(content.MediaMovie, ID), titleKey(content.MediaTV, ID)}
+	}
+	return []string{titleKey(typ, ID)}
+}
+
+//trimHistory drops the oldest watches past MaxWatchHistory, the history is newest first
+func trimHistory(history []users.Watched) []users.Watched {
+	if len(history) > MaxWatchHistory {
+		return history[:MaxWatchHistory]
+	}
+	return history
+}
@@ -0,0 +1,129 @@
+package users
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+)
+
+const (
+	RoleUser    = "user"
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+)
+
+const (
+	ScopeReadUsers   = "users:read"
+	ScopeLogoutUsers = "users:logout"
+	ScopeManageUsers = "users:manage"
+	ScopeManageRoles = "roles:manage"
+)
+
+//roleScopes is everything a role may do beyond using their own account.
+//Support can look people up and sign them out, only admins change accounts.
+var roleScopes = map[string][]string{
+	RoleUser:    {},
+	RoleSupport: {ScopeReadUsers, ScopeLogoutUsers},
+	RoleAdmin:   {ScopeReadUsers, ScopeLogoutUsers, ScopeManageUsers, ScopeManageRoles},
+}
+
+var (
+	ErrForbidden      = errors.New("account does not have the required scope")
+	ErrInvalidRole    = errors.New("role does not exist")
+	ErrAccountMissing = errors.New("account not found")
+	ErrSelfAction     = errors.New("admins can't disable or demote themselves")
+)
+
+//Role is the account's role, accounts from before roles existed are users. The first admin
+//has to be given the role directly in the Accounts table.
+func Role(act *users.UserAccount) string {
+	if act.Role == "" {
+		return RoleUser
+	}
+	return act.Role
+}
+
+func HasScope(act *users.UserAccount, scope string) bool {
+	for _, s := range roleScopes[Role(act)] {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+//Authorize is the check every admin lambda starts with: a valid session token whose role has the scope
+func (u Users) Authorize(ctx context.Context, token, scope string) (*users.UserAccount, error) {
+	act, err := u.FindUserAccountByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !HasScope(act, scope) {
+		return nil, ErrForbidden
+	}
+
+	return act, nil
+}
+
+//SetAccountDisabled signs a disabled account out everywhere, enabling it again doesn't sign anyone in
+func (u Users) SetAccountDisabled(ctx context.Context, admin *users.UserAccount, ID string, disabled bool) error {
+	if admin.ID == ID {
+		return ErrSelfAction
+	}
+
+	_, err := u.FindUserAccountByID(ctx, ID)
+	if err != nil {
+		return err
+	}
+
+	err = u.s.UpdateUserAccountDisabled(ctx, ID, disabled)
+	if err != nil {
+		return err
+	}
+
+	if disabled {
+		return u.InvalidateSessions(ctx, ID)
+	}
+
+	return nil
+}
+
+func (u Users) SetAccountRole(ctx context.Context, admin *users.UserAccount, ID, role string) (*users.UserAccount, error) {
+	if _, ok := roleScopes[role]; !ok {
+		return nil, ErrInvalidRole
+	}
+
+	if admin.ID == ID {
+		return nil, ErrSelfAction
+	}
+
+	_, err := u.FindUserAccountByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.s.UpdateUserAccountRole(ctx, ID, role)
+}
+
+//AdminAccount is an account as staff see it, without password, tokens or 2FA secrets
+type AdminAccount struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	Verified  bool   `json:"verified"`
+	Disabled  bool   `json:"disabled"`
+	TwoFactor bool   `json:"twoFactor"`
+}
+
+func NewAdminAccount(act users.UserAccount) AdminAccount {
+	return AdminAccount{
+		ID:        act.ID,
+		Email:     act.Email,
+		Role:      Role(&act),
+		Verified:  act.Verified,
+		Disabled:  act.Disabled,
+		TwoFactor: act.TwoFactor.Enabled,
+	}
+}
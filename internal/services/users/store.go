@@ -7,12 +7,58 @@ import (
 )
 
 type store interface {
+	CreateAPIKey(ctx context.Context, key users.APIKey) error
+	ConsumeTokenByID(ctx context.Context, ID string) (*users.Token, error)
+	CreateFollow(ctx context.Context, follow users.Follow) error
+	CreateIdentity(ctx context.Context, identity users.Identity) error
+	CreateList(ctx context.Context, list users.List) error
+	CreateProfileSession(ctx context.Context, session users.ProfileSession) error
+	CreateToken(ctx context.Context, token users.Token) error
 	CreateUserAccount(ctx context.Context, account users.UserAccount) error
 	CreateUserProfile(ctx context.Context, profile users.UserProfile) error
+	FindAPIKeyByID(ctx context.Context, ID string) (*users.APIKey, error)
+	FindAPIKeysByUserID(ctx context.Context, userID string) (*[]users.APIKey, error)
+	FindAllFollows(ctx context.Context) (*[]users.Follow, error)
+	FindFollowsByUserID(ctx context.Context, userID string) (*[]users.Follow, error)
+	FindIdentitiesByUserID(ctx context.Context, userID string) (*[]users.Identity, error)
+	FindIdentityByID(ctx context.Context, ID string) (*users.Identity, error)
+	FindListByID(ctx context.Context, ID string) (*users.List, error)
+	FindListBySlug(ctx context.Context, slug string) (*users.List, error)
+	FindListsByAccountID(ctx context.Context, accountID string) (*[]users.List, error)
+	FindListsByProfileID(ctx context.Context, profileID string) (*[]users.List, error)
+	FindListsByVisibility(ctx context.Context, visibility string) (*[]users.List, error)
+	FindLoginAttemptsByID(ctx context.Context, ID string) (*users.LoginAttempts, error)
+	FindProfileSessionByID(ctx context.Context, ID string) (*users.ProfileSession, error)
+	FindProfileSessionsByAccountID(ctx context.Context, accountID string) (*[]users.ProfileSession, error)
+	FindTokensByUserID(ctx context.Context, userID string) (*[]users.Token, error)
+	FindUserAccountByCalendarToken(ctx context.Context, token string) (*users.UserAccount, error)
 	FindUserAccountByEmail(ctx context.Context, email string) (*users.UserAccount, error)
+	FindUserAccountByID(ctx context.Context, ID string) (*users.UserAccount, error)
 	FindUserAccountByToken(ctx context.Context, token string) (*users.UserAccount, error)
+	FindUserAccounts(ctx context.Context, query, startID string, limit int) (*[]users.UserAccount, string, error)
+	FindUserAccountsDueForDeletion(ctx context.Context, now int64) (*[]users.UserAccount, error)
 	FindUserProfileByID(ctx context.Context, ID string) (*users.UserProfile, error)
+	FindUserProfilesByAccountID(ctx context.Context, accountID string) (*[]users.UserProfile, error)
+	IncrementLoginFailures(ctx context.Context, ID string, now, expiresAt int64) (*users.LoginAttempts, error)
+	RemoveAPIKeyByID(ctx context.Context, ID string) error
+	RemoveFollowByID(ctx context.Context, ID string) error
+	RemoveIdentityByID(ctx context.Context, ID string) error
+	RemoveListByID(ctx context.Context, ID string) error
+	RemoveLoginAttemptsByID(ctx context.Context, ID string) error
+	RemoveProfileSessionByID(ctx context.Context, ID string) error
+	RemoveTokenByID(ctx context.Context, ID string) error
 	RemoveUserAccountByID(ctx context.Context, ID string) error
 	RemoveUserProfileByID(ctx context.Context, ID string) error
+	UpdateAPIKeyLastUsed(ctx context.Context, ID string, lastUsedAt int64) error
+	UpdateFollowLastNotified(ctx context.Context, ID, airDate string) error
+	UpdateLoginLockout(ctx context.Context, ID string, lockedUntil, expiresAt int64) error
+	UpdateUserAccountCalendarToken(ctx context.Context, ID, token string) (*users.UserAccount, error)
+	UpdateUserAccountDeleteAfter(ctx context.Context, ID string, deleteAfter int64) error
+	UpdateUserAccountDisabled(ctx context.Context, ID string, disabled bool) error
+	UpdateUserAccountPassword(ctx context.Context, ID, password string) (*users.UserAccount, error)
+	UpdateUserAccountRole(ctx context.Context, ID, role string) (*users.UserAccount, error)
 	UpdateUserAccountToken(ctx context.Context, ID, token string) (*users.UserAccount, error)
+	UpdateUserAccountTwoFactor(ctx context.Context, ID string, tf users.TwoFactor) error
+	UpdateUserAccountVerified(ctx context.Context, ID string) error
+	UpdateUserProfileNotifications(ctx context.Context, ID string, prefs users.NotificationPreferences) (*users.UserProfile, error)
 }
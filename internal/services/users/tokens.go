@@ -0,0 +1,138 @@
+package users
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+)
+
+const (
+	PurposeVerifyEmail    = "verify_email"
+	PurposePasswordReset  = "password_reset"
+	PurposeLoginChallenge = "login_challenge"
+	PurposeOIDCLogin      = "oidc_login"
+
+	verifyEmailTTL    = 48 * time.Hour
+	passwordResetTTL  = time.Hour
+	loginChallengeTTL = 5 * time.Minute
+	oidcLoginTTL      = 10 * time.Minute
+)
+
+var ErrInvalidToken = errors.New("token is invalid or has expired")
+
+//CreateVerificationToken returns the secret to email, only its hash is stored
+func (u Users) CreateVerificationToken(ctx context.Context, userID string) (string, error) {
+	return u.createToken(ctx, userID, PurposeVerifyEmail, verifyEmailTTL)
+}
+
+func (u Users) VerifyEmail(ctx context.Context, token string) (string, error) {
+	t, err := u.consumeToken(ctx, token, PurposeVerifyEmail)
+	if err != nil {
+		return "", err
+	}
+
+	err = u.s.UpdateUserAccountVerified(ctx, t.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	return t.UserID, nil
+}
+
+//CreatePasswordResetToken returns an empty token without error for unknown emails so callers can't probe for accounts
+func (u Users) CreatePasswordResetToken(ctx context.Context, email string) (string, *users.UserAccount, error) {
+	act, err := u.FindUserAccountByEmail(ctx, email)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if act == nil {
+		return "", nil, nil
+	}
+
+	token, err := u.createToken(ctx, act.ID, PurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, act, nil
+}
+
+func (u Users) ResetPassword(ctx context.Context, token, pass string) (string, error) {
+	t, err := u.consumeToken(ctx, token, PurposePasswordReset)
+	if err != nil {
+		return "", err
+	}
+
+	err = u.ChangePassword(ctx, t.UserID, pass)
+	if err != nil {
+		return "", err
+	}
+
+	//proving access to the inbox is how a locked out user gets back in
+	act, err := u.s.FindUserAccountByID(ctx, t.UserID)
+	if err != nil {
+		return "", err
+	}
+
+	if act != nil {
+		err = u.clearLoginFailures(ctx, act.Email)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return t.UserID, nil
+}
+
+//Helpers
+
+func (u Users) createToken(ctx context.Context, userID, purpose string, ttl time.Duration) (string, error) {
+	return u.createTokenWithData(ctx, userID, purpose, ttl, nil)
+}
+
+//createTokenWithData keeps server side state for a flow that leaves and comes back, like an OIDC redirect
+func (u Users) createTokenWithData(ctx context.Context, userID, purpose string, ttl time.Duration, data map[string]string) (string, error) {
+	secret, err := u.generateSecret(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	t := users.Token{
+		ID:        hashToken(secret),
+		UserID:    userID,
+		Purpose:   purpose,
+		Data:      data,
+		ExpiresAt: time.Now().Add(ttl).Unix(),
+	}
+
+	err = u.s.CreateToken(ctx, t)
+	if err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+func (u Users) consumeToken(ctx context.Context, token, purpose string) (*users.Token, error) {
+	t, err := u.s.ConsumeTokenByID(ctx, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	//the dynamo ttl sweep is lazy so expired tokens may still be in the table
+	if t == nil || t.Purpose != purpose || time.Now().Unix() > t.ExpiresAt {
+		return nil, ErrInvalidToken
+	}
+
+	return t, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
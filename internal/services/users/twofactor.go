@@ -0,0 +1,220 @@
+package users
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/pkg/totp"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	ErrInvalidCode         = errors.New("two factor code is invalid")
+	ErrTwoFactorEnabled    = errors.New("two factor authentication is already enabled")
+	ErrTwoFactorNotEnabled = errors.New("two factor authentication is not enabled")
+)
+
+//EnrollTwoFactor stores a new secret that stays inactive until ConfirmTwoFactor sees a code from it
+func (u Users) EnrollTwoFactor(ctx context.Context, act *users.UserAccount, issuer string) (string, string, error) {
+	if act.TwoFactor.Enabled {
+		return "", "", ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+
+	err = u.s.UpdateUserAccountTwoFactor(ctx, act.ID, users.TwoFactor{
+		Secret: secret,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return secret, totp.URI(issuer, act.Email, secret), nil
+}
+
+//ConfirmTwoFactor turns 2FA on and returns the recovery codes, which are only ever shown this once
+func (u Users) ConfirmTwoFactor(ctx context.Context, act *users.UserAccount, code string) ([]string, error) {
+	if act.TwoFactor.Enabled {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	if act.TwoFactor.Secret == "" {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	step, ok := totp.Validate(act.TwoFactor.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.s.UpdateUserAccountTwoFactor(ctx, act.ID, users.TwoFactor{
+		Enabled:       true,
+		Secret:        act.TwoFactor.Secret,
+		RecoveryCodes: hashes,
+		LastStep:      step,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+//CompleteTwoFactorLogin trades the challenge from LoginUser and a TOTP or recovery code for a session token.
+//The challenge is spent either way, so a wrong code means starting the login again. Once the challenge
+//is known the Login carries the account id even alongside an error, so failures can be audited.
+func (u Users) CompleteTwoFactorLogin(ctx context.Context, challenge, code, sourceIP string) (*Login, error) {
+	t, err := u.consumeToken(ctx, challenge, PurposeLoginChallenge)
+	if err != nil {
+		return nil, err
+	}
+
+	act, err := u.s.FindUserAccountByID(ctx, t.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if act == nil {
+		return nil, ErrInvalidToken
+	}
+
+	login := &Login{UserID: act.ID}
+	if act.Disabled {
+		return login, ErrAccountDisabled
+	}
+
+	err = u.checkSecondFactor(ctx, act, code)
+	if err == ErrInvalidCode {
+		//wrong codes count towards the same lockout as wrong passwords
+		recordErr := u.recordLoginFailure(ctx, act.Email, sourceIP, time.Now())
+		if recordErr != nil {
+			return login, recordErr
+		}
+	}
+	if err != nil {
+		return login, err
+	}
+
+	err = u.clearLoginFailures(ctx, act.Email)
+	if err != nil {
+		return login, err
+	}
+
+	login.Token, err = u.startSession(ctx, act.ID)
+	if err != nil {
+		return login, err
+	}
+
+	return login, nil
+}
+
+func (u Users) DisableTwoFactor(ctx context.Context, act *users.UserAccount, code string) error {
+	err := u.checkSecondFactor(ctx, act, code)
+	if err != nil {
+		return err
+	}
+
+	return u.s.UpdateUserAccountTwoFactor(ctx, act.ID, users.TwoFactor{})
+}
+
+//RegenerateRecoveryCodes replaces every recovery code, used or not
+func (u Users) RegenerateRecoveryCodes(ctx context.Context, act *users.UserAccount, code string) ([]string, error) {
+	err := u.checkSecondFactor(ctx, act, code)
+	if err != nil {
+		return nil, err
+	}
+
+	//checkSecondFactor may have spent a recovery code or moved the last step, so reload
+	fresh, err := u.s.FindUserAccountByID(ctx, act.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	tf := fresh.TwoFactor
+	tf.RecoveryCodes = hashes
+	err = u.s.UpdateUserAccountTwoFactor(ctx, act.ID, tf)
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+//Helpers
+
+//checkSecondFactor accepts a current TOTP code that hasn't been used yet, or spends one recovery code
+func (u Users) checkSecondFactor(ctx context.Context, act *users.UserAccount, code string) error {
+	tf := act.TwoFactor
+	if !tf.Enabled {
+		return ErrTwoFactorNotEnabled
+	}
+
+	step, ok := totp.Validate(tf.Secret, code, time.Now())
+	if ok {
+		if step <= tf.LastStep {
+			return ErrInvalidCode
+		}
+
+		tf.LastStep = step
+		return u.s.UpdateUserAccountTwoFactor(ctx, act.ID, tf)
+	}
+
+	hash := hashToken(normalizeRecoveryCode(code))
+	for i, h := range tf.RecoveryCodes {
+		if h != hash {
+			continue
+		}
+
+		remaining := make([]string, 0)
+		remaining = append(remaining, tf.RecoveryCodes[:i]...)
+		remaining = append(remaining, tf.RecoveryCodes[i+1:]...)
+		tf.RecoveryCodes = remaining
+
+		return u.s.UpdateUserAccountTwoFactor(ctx, act.ID, tf)
+	}
+
+	return ErrInvalidCode
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	codes := make([]string, 0)
+	hashes := make([]string, 0)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 7)
+		_, err := rand.Read(b)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		c := strings.ToLower(enc.EncodeToString(b))[:10]
+		codes = append(codes, c[:5]+"-"+c[5:])
+		hashes = append(hashes, hashToken(c))
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(code), "-", "", -1))
+}
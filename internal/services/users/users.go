@@ -2,10 +2,12 @@ package users
 
 import (
 	"github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/kelseyhightower/envconfig"
 )
 
 type Users struct {
-	s store
+	s       store
+	lockout LockoutPolicy
 }
 
 func NewUsersService(conn, region string) (*Users, error) {
@@ -15,7 +17,15 @@ func NewUsersService(conn, region string) (*Users, error) {
 		return nil, err
 	}
 
+	//unset LOCKOUT_ variables keep their DefaultLockoutPolicy values
+	var lockout LockoutPolicy
+	err = envconfig.Process("", &lockout)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Users{
-		s: s,
+		s:       s,
+		lockout: lockout,
 	}, nil
 }
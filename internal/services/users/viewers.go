@@ -0,0 +1,371 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/segmentio/ksuid"
+)
+
+const (
+	MaxProfiles       = 6
+	profileSessionTTL = 30 * 24 * time.Hour
+)
+
+var (
+	ErrProfileNotFound = errors.New("profile not found")
+	ErrTooManyProfiles = errors.New("account has the maximum number of profiles")
+	ErrPrimaryProfile  = errors.New("the account owner's profile can't be removed")
+	ErrInvalidPIN      = errors.New("pin is invalid")
+	ErrPINFormat       = errors.New("pin must be 4 to 8 digits")
+	ErrNotProfileToken = errors.New("token was not issued by SelectProfile")
+)
+
+//Viewer is who a token speaks for. Account tokens act as the owner's profile, tokens from
+//SelectProfile act as the chosen profile.
+type Viewer struct {
+	Account *users.UserAccount
+	Profile *users.UserProfile
+	//Selected is set when the token came from SelectProfile
+	Selected bool
+	//APIKeyID is set when an API key was used, Scopes are then all it may do
+	APIKeyID string
+	Scopes   []string
+}
+
+//HasScope is always true for session tokens, they are limited by FindUserAccountByToken instead
+func (v Viewer) HasScope(scope string) bool {
+	if v.APIKeyID == "" {
+		return true
+	}
+
+	for _, s := range v.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+//FindViewerByToken accepts profile tokens as well as account tokens. Lambdas that change the
+//account itself (password, 2FA, sign out) keep using FindUserAccountByToken so a profile token,
+//which may belong to a kids profile, can't reach them.
+func (u Users) FindViewerByToken(ctx context.Context, token string) (*Viewer, error) {
+	ps, err := u.s.FindProfileSessionByID(ctx, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	if ps == nil {
+		act, err := u.FindUserAccountByToken(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+
+		prof, err := u.findProfile(ctx, act.ID, act.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Viewer{Account: act, Profile: prof}, nil
+	}
+
+	//the dynamo ttl sweep is lazy so expired sessions may still be in the table
+	if time.Now().Unix() > ps.ExpiresAt {
+		return nil, ErrUnauthorized
+	}
+
+	act, err := u.s.FindUserAccountByID(ctx, ps.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if act == nil || act.Disabled || hashToken(act.Token) != ps.AccountToken {
+		return nil, ErrUnauthorized
+	}
+
+	prof, err := u.findProfile(ctx, act.ID, ps.ProfileID)
+	if err == ErrProfileNotFound {
+		return nil, ErrUnauthorized
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Viewer{Account: act, Profile: prof, Selected: true}, nil
+}
+
+//FindViewerProfiles lists the household with the owner first, PINs are left in so callers must go through PublicProfile
+func (u Users) FindViewerProfiles(ctx context.Context, act *users.UserAccount) (*[]users.UserProfile, error) {
+	profiles, err := u.s.FindUserProfilesByAccountID(ctx, act.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	//owners that signed up before households existed have no accountId on their profile
+	found := false
+	for _, p := range *profiles {
+		if p.ID == act.ID {
+			found = true
+		}
+	}
+
+	if !found {
+		owner, err := u.s.FindUserProfileByID(ctx, act.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if owner.ID != "" {
+			*profiles = append(*profiles, *owner)
+		}
+	}
+
+	sort.SliceStable(*profiles, func(i, j int) bool {
+		return (*profiles)[i].ID == act.ID && (*profiles)[j].ID != act.ID
+	})
+
+	return profiles, nil
+}
+
+//AddViewerProfile shares the owner's streaming subscriptions, everything else starts empty
+func (u Users) AddViewerProfile(ctx context.Context, act *users.UserAccount, name string, kids bool, pin string) (*users.UserProfile, error) {
+	profiles, err := u.FindViewerProfiles(ctx, act)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*profiles) >= MaxProfiles {
+		return nil, ErrTooManyProfiles
+	}
+
+	var acts []users.StreamAccounts
+	for _, p := range *profiles {
+		if p.ID == act.ID {
+			acts = p.StreamAccounts
+		}
+	}
+
+	p := users.UserProfile{
+		ID:             ksuid.New().String(),
+		AccountID:      act.ID,
+		Name:           name,
+		Email:          act.Email,
+		Kids:           kids,
+		StreamAccounts: acts,
+		Library: users.Library{
+			ContentList: make([]users.Content, 0),
+		},
+	}
+
+	err = u.setPIN(ctx, &p, pin)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.s.CreateUserProfile(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+//UpdateViewerProfile changes the name, kids flag and PIN. A nil pin leaves it alone, an empty one removes it.
+func (u Users) UpdateViewerProfile(ctx context.Context, act *users.UserAccount, ID, name string, kids bool, pin *string) (*users.UserProfile, error) {
+	prof, err := u.findProfile(ctx, act.ID, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if name != "" {
+		prof.Name = name
+	}
+	prof.Kids = kids
+
+	if pin != nil {
+		err = u.setPIN(ctx, prof, *pin)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = u.s.CreateUserProfile(ctx, *prof)
+	if err != nil {
+		return nil, err
+	}
+
+	return prof, nil
+}
+
+//RemoveViewerProfile also ends the profile's sessions, FindViewerByToken rejects tokens for missing profiles.
+//The profile's lists go with it.
+func (u Users) RemoveViewerProfile(ctx context.Context, act *users.UserAccount, ID string) error {
+	if ID == act.ID {
+		return ErrPrimaryProfile
+	}
+
+	_, err := u.findProfile(ctx, act.ID, ID)
+	if err != nil {
+		return err
+	}
+
+	err = u.removeListsByProfileID(ctx, ID)
+	if err != nil {
+		return err
+	}
+
+	return u.s.RemoveUserProfileByID(ctx, ID)
+}
+
+//ShareStreamAccounts copies the owner's subscriptions to the rest of the household
+func (u Users) ShareStreamAccounts(ctx context.Context, act *users.UserAccount, acts []users.StreamAccounts) error {
+	profiles, err := u.FindViewerProfiles(ctx, act)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range *profiles {
+		if p.ID == act.ID {
+			continue
+		}
+
+		p.StreamAccounts = acts
+		err = u.s.CreateUserProfile(ctx, p)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//SelectProfile is the step after login, it returns a token scoped to one profile of the account
+func (u Users) SelectProfile(ctx context.Context, act *users.UserAccount, ID, pin string) (string, *users.UserProfile, error) {
+	prof, err := u.findProfile(ctx, act.ID, ID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if prof.PIN != "" {
+		now := time.Now()
+		keys := []string{pinAttemptsID(prof.ID)}
+		err = u.checkAttempts(ctx, keys, now)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if !u.passMatches(ctx, prof.PIN, pin) {
+			err = u.recordFailures(ctx, keys, now)
+			if err != nil {
+				return "", nil, err
+			}
+			return "", nil, ErrInvalidPIN
+		}
+
+		err = u.s.RemoveLoginAttemptsByID(ctx, pinAttemptsID(prof.ID))
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	secret, err := u.startProfileSession(ctx, act, prof)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return secret, prof, nil
+}
+
+//RefreshProfileToken swaps a profile token for one with a fresh expiry, the old token stops working
+func (u Users) RefreshProfileToken(ctx context.Context, token string) (string, *Viewer, error) {
+	v, err := u.FindViewerByToken(ctx, token)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !v.Selected {
+		return "", nil, ErrNotProfileToken
+	}
+
+	secret, err := u.startProfileSession(ctx, v.Account, v.Profile)
+	if err != nil {
+		return "", nil, err
+	}
+
+	err = u.s.RemoveProfileSessionByID(ctx, hashToken(token))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return secret, v, nil
+}
+
+//PublicProfile strips the PIN hash before a profile is returned to a client
+func PublicProfile(p users.UserProfile) users.UserProfile {
+	p.PIN = ""
+	return p
+}
+
+//Helpers
+
+//findProfile only returns profiles that belong to the account
+func (u Users) findProfile(ctx context.Context, accountID, ID string) (*users.UserProfile, error) {
+	prof, err := u.s.FindUserProfileByID(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if prof.ID == "" || (prof.ID != accountID && prof.AccountID != accountID) {
+		return nil, ErrProfileNotFound
+	}
+
+	return prof, nil
+}
+
+func (u Users) startProfileSession(ctx context.Context, act *users.UserAccount, prof *users.UserProfile) (string, error) {
+	secret, err := u.generateSecret(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	err = u.s.CreateProfileSession(ctx, users.ProfileSession{
+		ID:           hashToken(secret),
+		AccountID:    act.ID,
+		ProfileID:    prof.ID,
+		AccountToken: hashToken(act.Token),
+		ExpiresAt:    time.Now().Add(profileSessionTTL).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+func (u Users) setPIN(ctx context.Context, p *users.UserProfile, pin string) error {
+	if pin == "" {
+		p.PIN = ""
+		return nil
+	}
+
+	if len(pin) < 4 || len(pin) > 8 {
+		return ErrPINFormat
+	}
+	for _, r := range pin {
+		if r < '0' || r > '9' {
+			return ErrPINFormat
+		}
+	}
+
+	hash, err := u.passEncrypt(ctx, pin)
+	if err != nil {
+		return err
+	}
+
+	p.PIN = hash
+	return nil
+}
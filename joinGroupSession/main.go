@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+
+	dbGroups "github.com/aschles4/finalProject/internal/pkg/dynamo/groups"
+	"github.com/aschles4/finalProject/internal/services/groups"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type JoinGroupSessionRequest struct {
+	Code string `json:"code"`
+}
+
+type JoinGroupSessionResponse struct {
+	Session *dbGroups.Session `json:"session,omitempty"`
+	Status  int               `json:"status,omitempty"`
+	Message string            `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	G   *groups.Groups
+	l   zerolog.Logger
+}
+
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	v, err := h.U.FindViewerByToken(ctx, token)
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	var req JoinGroupSessionRequest
+	err = json.Unmarshal([]byte(event.Body), &req)
+	if err != nil {
+		return h.handleError(http.StatusBadRequest, err, "Failed to marshal event")
+	}
+
+	if req.Code == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Code is required")
+	}
+
+	s, err := h.G.JoinSession(ctx, req.Code, v.Account.ID, v.Profile.ID, v.Profile.Name)
+	if err == groups.ErrSessionNotFound {
+		return h.handleError(http.StatusNotFound, nil, "Session not found")
+	}
+	if err == groups.ErrSessionStarted {
+		return h.handleError(http.StatusConflict, nil, "Session has already started voting")
+	}
+	if err == groups.ErrSessionFull {
+		return h.handleError(http.StatusConflict, nil, "Session is full")
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to join session")
+	}
+
+	//return
+	js, err := json.Marshal(JoinGroupSessionResponse{
+		Session: s,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(JoinGroupSessionResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	g, err := groups.NewGroupsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to groups service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		G:   g,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
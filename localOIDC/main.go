@@ -0,0 +1,253 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/pkg/oidc"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+	"github.com/segmentio/ksuid"
+)
+
+//A stand-in OpenID Connect issuer for local development and testing. /authorize signs in whoever
+//is named by login_hint without asking and redirects straight back with a code. The token endpoint
+//enforces PKCE, the redirect uri and the client id like a real provider, and signs ID tokens with an
+//RSA key generated at startup. Point OIDC_PROVIDERS at it with {"name":"local","issuer":"http://localhost:9000","clientId":"local"}.
+
+type Env struct {
+	Addr     string `required:"true" default:"localhost:9000" envconfig:"OIDC_ADDR"`
+	Issuer   string `required:"true" default:"http://localhost:9000" envconfig:"OIDC_ISSUER"`
+	ClientID string `default:"local" envconfig:"OIDC_CLIENT_ID"`
+}
+
+type grant struct {
+	ClientID    string
+	RedirectURI string
+	Challenge   string
+	Nonce       string
+	Email       string
+	Name        string
+	ExpiresAt   time.Time
+}
+
+type Server struct {
+	Env Env
+	l   zerolog.Logger
+	key *rsa.PrivateKey
+	kid string
+
+	mu     sync.Mutex
+	grants map[string]grant
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, oidc.Discovery{
+		Issuer:                s.Env.Issuer,
+		AuthorizationEndpoint: s.Env.Issuer + "/authorize",
+		TokenEndpoint:         s.Env.Issuer + "/token",
+		JWKSURI:               s.Env.Issuer + "/jwks",
+		SigningAlgs:           []string{"RS256"},
+		ChallengeMethods:      []string{"S256"},
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, oidc.JWKS{
+		Keys: []oidc.JWK{oidc.NewRSAJWK(s.kid, &s.key.PublicKey)},
+	})
+}
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirect, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirect.Scheme == "" {
+		http.Error(w, "redirect_uri is required", http.StatusBadRequest)
+		return
+	}
+
+	if s.Env.ClientID != "" && q.Get("client_id") != s.Env.ClientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+
+	if q.Get("response_type") != "code" || q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		redirectError(w, r, redirect, q.Get("state"), "invalid_request")
+		return
+	}
+
+	email := q.Get("login_hint")
+	if email == "" {
+		email = "local.user@example.com"
+	}
+
+	code := ksuid.New().String()
+	s.mu.Lock()
+	s.grants[code] = grant{
+		ClientID:    q.Get("client_id"),
+		RedirectURI: q.Get("redirect_uri"),
+		Challenge:   q.Get("code_challenge"),
+		Nonce:       q.Get("nonce"),
+		Email:       email,
+		Name:        strings.Split(email, "@")[0],
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}
+	s.mu.Unlock()
+
+	s.l.Info().Str("email", email).Msg("signed in")
+
+	v := redirect.Query()
+	v.Set("code", code)
+	v.Set("state", q.Get("state"))
+	redirect.RawQuery = v.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, oidc.TokenResponse{Error: "unsupported_grant_type"})
+		return
+	}
+
+	//codes are single use
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	g, ok := s.grants[code]
+	delete(s.grants, code)
+	s.mu.Unlock()
+
+	clientID := r.PostForm.Get("client_id")
+	if user, _, basic := r.BasicAuth(); basic {
+		clientID, _ = url.QueryUnescape(user)
+	}
+
+	switch {
+	case !ok || time.Now().After(g.ExpiresAt):
+		writeJSON(w, http.StatusBadRequest, oidc.TokenResponse{Error: "invalid_grant", Description: "unknown or expired code"})
+		return
+	case g.RedirectURI != r.PostForm.Get("redirect_uri"):
+		writeJSON(w, http.StatusBadRequest, oidc.TokenResponse{Error: "invalid_grant", Description: "redirect_uri does not match"})
+		return
+	case g.ClientID != clientID:
+		writeJSON(w, http.StatusBadRequest, oidc.TokenResponse{Error: "invalid_client"})
+		return
+	case oidc.CodeChallenge(r.PostForm.Get("code_verifier")) != g.Challenge:
+		writeJSON(w, http.StatusBadRequest, oidc.TokenResponse{Error: "invalid_grant", Description: "code_verifier does not match"})
+		return
+	}
+
+	now := time.Now()
+	sum := sha256.Sum256([]byte(strings.ToLower(g.Email)))
+	idToken, err := s.sign(oidc.Claims{
+		Issuer:        s.Env.Issuer,
+		Subject:       hex.EncodeToString(sum[:8]),
+		Audience:      oidc.Audience{g.ClientID},
+		Expiry:        now.Add(time.Hour).Unix(),
+		IssuedAt:      now.Unix(),
+		Nonce:         g.Nonce,
+		Email:         g.Email,
+		EmailVerified: true,
+		Name:          g.Name,
+	})
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, oidc.TokenResponse{Error: "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, oidc.TokenResponse{
+		AccessToken: ksuid.New().String(),
+		TokenType:   "Bearer",
+		IDToken:     idToken,
+		ExpiresIn:   3600,
+	})
+}
+
+//sign produces a compact RS256 JWS
+func (s *Server) sign(claims oidc.Claims) (string, error) {
+	h, err := json.Marshal(map[string]string{"alg": "RS256", "kid": s.kid, "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	sum := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func redirectError(w http.ResponseWriter, r *http.Request, redirect *url.URL, state, code string) {
+	v := redirect.Query()
+	v.Set("error", code)
+	v.Set("state", state)
+	redirect.RawQuery = v.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to generate signing key")
+	}
+
+	s := &Server{
+		Env:    e,
+		l:      l,
+		key:    key,
+		kid:    ksuid.New().String(),
+		grants: make(map[string]grant),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/jwks", s.jwks)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+
+	l.Info().Str("addr", e.Addr).Str("issuer", e.Issuer).Msg("listening")
+	err = http.ListenAndServe(e.Addr, mux)
+	if err != nil {
+		l.Fatal().Msg(err.Error())
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+	"github.com/segmentio/ksuid"
+)
+
+//A stand-in SMTP server for local development. It accepts every message the notification
+//lambdas send, logs it and optionally writes it to OUTBOX as a .eml file. It never relays mail.
+
+type Env struct {
+	Addr   string `required:"true" default:"localhost:1025" envconfig:"SMTP_ADDR"`
+	Outbox string `default:"" envconfig:"OUTBOX"`
+}
+
+type Server struct {
+	Env Env
+	l   zerolog.Logger
+}
+
+func (s Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 localhost ESMTP stand-in")
+
+	var from string
+	var to []string
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			tp.PrintfLine("250-localhost")
+			tp.PrintfLine("250 8BITMIME")
+		case strings.HasPrefix(cmd, "HELO"):
+			tp.PrintfLine("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			from = strings.TrimSpace(line[len("MAIL FROM:"):])
+			tp.PrintfLine("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			to = append(to, strings.TrimSpace(line[len("RCPT TO:"):]))
+			tp.PrintfLine("250 OK")
+		case cmd == "DATA":
+			tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.deliver(from, to, data)
+			from, to = "", nil
+			tp.PrintfLine("250 OK")
+		case cmd == "RSET":
+			from, to = "", nil
+			tp.PrintfLine("250 OK")
+		case cmd == "NOOP":
+			tp.PrintfLine("250 OK")
+		case cmd == "QUIT":
+			tp.PrintfLine("221 Bye")
+			return
+		default:
+			tp.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func (s Server) deliver(from string, to []string, data []byte) {
+	s.l.Info().
+		Str("from", from).
+		Strs("to", to).
+		Int("bytes", len(data)).
+		Msg("received message")
+
+	if s.Env.Outbox == "" {
+		fmt.Fprintf(os.Stdout, "%s\n", data)
+		return
+	}
+
+	name := filepath.Join(s.Env.Outbox, fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405"), ksuid.New().String()))
+	err := ioutil.WriteFile(name, data, 0644)
+	if err != nil {
+		s.l.Error().Msg(err.Error())
+	}
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	ln, err := net.Listen("tcp", e.Addr)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to listen")
+	}
+	l.Info().Str("addr", e.Addr).Msg("smtp stand-in listening")
+
+	s := Server{
+		Env: e,
+		l:   l,
+	}
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			l.Error().Msg(err.Error())
+			continue
+		}
+		go s.handle(conn)
+	}
+}
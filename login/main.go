@@ -5,7 +5,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
+	dbAudit "github.com/aschles4/finalProject/internal/pkg/dynamo/audit"
+	"github.com/aschles4/finalProject/internal/services/audit"
 	"github.com/aschles4/finalProject/internal/services/users"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -19,9 +23,14 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token   string `json:"token,omitempty"`
-	Status  int    `json:"status,omitempty"`
-	Message string `json:"message,omitempty"`
+	Token             string `json:"token,omitempty"`
+	Challenge         string `json:"challenge,omitempty"`
+	TwoFactorRequired bool   `json:"twoFactorRequired,omitempty"`
+	DeleteAfter       int64  `json:"deleteAfter,omitempty"`
+	Locked            bool   `json:"locked,omitempty"`
+	RetryAfter        int    `json:"retryAfter,omitempty"`
+	Status            int    `json:"status,omitempty"`
+	Message           string `json:"message,omitempty"`
 }
 
 type Env struct {
@@ -32,18 +41,13 @@ type Env struct {
 type Handler struct {
 	Env Env
 	U   *users.Users
+	A   *audit.Audit
 	l   zerolog.Logger
 }
 
 func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	js, err := json.Marshal(event.Body)
-	if err != nil {
-		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal request")
-	}
-	println(string(js))
-
 	var req LoginRequest
-	err = json.Unmarshal([]byte(event.Body), &req)
+	err := json.Unmarshal([]byte(event.Body), &req)
 	if err != nil {
 		return h.handleError(http.StatusBadRequest, err, "Failed to marshal event")
 	}
@@ -57,14 +61,35 @@ func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxy
 	}
 
 	//Login User Here
-	token, err := h.U.LoginUser(ctx, req.Email, req.Password)
+	login, err := h.U.LoginUser(ctx, req.Email, req.Password, event.RequestContext.Identity.SourceIP)
+	//unknown emails have no account to file the failure under
+	if err != nil && login != nil {
+		h.record(ctx, event, login.UserID, audit.LoginFailed, map[string]string{"reason": err.Error()})
+	}
+	if locked, ok := err.(*users.LockedError); ok {
+		return h.handleLocked(locked)
+	}
+	if err == users.ErrAccountDisabled {
+		return h.handleError(http.StatusForbidden, nil, "Account is disabled")
+	}
+	if err == users.ErrInvalidCredentials {
+		return h.handleError(http.StatusUnauthorized, nil, "Invalid email or password")
+	}
 	if err != nil {
-		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+		return h.handleError(http.StatusInternalServerError, err, "Failed to login user")
 	}
 
-	//return users id & login token
-	js, err = json.Marshal(LoginResponse{
-		Token: token,
+	//with 2FA on the login only counts once loginTwoFactor gets a good code
+	if login.Token != "" {
+		h.record(ctx, event, login.UserID, audit.LoginSucceeded, nil)
+	}
+
+	//return users id & login token, or the challenge to send to loginTwoFactor with a code
+	js, err := json.Marshal(LoginResponse{
+		Token:             login.Token,
+		Challenge:         login.Challenge,
+		TwoFactorRequired: login.Challenge != "",
+		DeleteAfter:       login.DeleteAfter,
 	})
 	if err != nil {
 		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
@@ -98,6 +123,46 @@ func (h Handler) handleError(status int, err error, message string) (events.APIG
 	}, nil
 }
 
+//handleLocked answers with 429 and a Retry-After header, a locked account can also be unlocked with a password reset
+func (h Handler) handleLocked(locked *users.LockedError) (events.APIGatewayProxyResponse, error) {
+	h.l.Info().Msg(locked.Error())
+
+	retry := int(locked.RetryAfter(time.Now()).Seconds())
+	message := "Too many failed attempts, try again later"
+	if locked.Locked {
+		message = "Too many failed attempts, try again later or reset your password to unlock your account"
+	}
+
+	js, err := json.Marshal(LoginResponse{
+		Locked:     locked.Locked,
+		RetryAfter: retry,
+		Message:    message,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusTooManyRequests,
+		Headers:    map[string]string{"Retry-After": strconv.Itoa(retry)},
+		Body:       string(js),
+	}, nil
+}
+
+//record keeps a failed audit write from failing the request, it only gets logged
+func (h Handler) record(ctx context.Context, event events.APIGatewayProxyRequest, userID, action string, data map[string]string) {
+	err := h.A.Record(ctx, dbAudit.Event{
+		UserID:    userID,
+		Action:    action,
+		SourceIP:  event.RequestContext.Identity.SourceIP,
+		UserAgent: event.RequestContext.Identity.UserAgent,
+		Data:      data,
+	})
+	if err != nil {
+		h.l.Error().Str("userId", userID).Str("action", action).Msg(err.Error())
+	}
+}
+
 func main() {
 	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
 
@@ -114,10 +179,17 @@ func main() {
 		l.Fatal().Msg("failed to connect to users service")
 	}
 
+	a, err := audit.NewAuditService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to audit service")
+	}
+
 	h := Handler{
 		l:   l,
 		Env: e,
 		U:   u,
+		A:   a,
 	}
 
 	lambda.Start(h.HandleRequest)
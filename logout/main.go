@@ -7,6 +7,8 @@ import (
 	"os"
 	"strings"
 
+	dbAudit "github.com/aschles4/finalProject/internal/pkg/dynamo/audit"
+	"github.com/aschles4/finalProject/internal/services/audit"
 	"github.com/aschles4/finalProject/internal/services/users"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -27,6 +29,7 @@ type Env struct {
 type Handler struct {
 	Env Env
 	U   *users.Users
+	A   *audit.Audit
 	l   zerolog.Logger
 }
 
@@ -50,6 +53,7 @@ func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxy
 	if err != nil {
 		return h.handleError(http.StatusInternalServerError, err, "Failed to Logout User")
 	}
+	h.record(ctx, event, act.ID, audit.Logout, nil)
 
 	//return
 	return events.APIGatewayProxyResponse{
@@ -79,6 +83,20 @@ func (h Handler) handleError(status int, err error, message string) (events.APIG
 	}, nil
 }
 
+//record keeps a failed audit write from failing the request, it only gets logged
+func (h Handler) record(ctx context.Context, event events.APIGatewayProxyRequest, userID, action string, data map[string]string) {
+	err := h.A.Record(ctx, dbAudit.Event{
+		UserID:    userID,
+		Action:    action,
+		SourceIP:  event.RequestContext.Identity.SourceIP,
+		UserAgent: event.RequestContext.Identity.UserAgent,
+		Data:      data,
+	})
+	if err != nil {
+		h.l.Error().Str("userId", userID).Str("action", action).Msg(err.Error())
+	}
+}
+
 func main() {
 	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
 
@@ -95,10 +113,17 @@ func main() {
 		l.Fatal().Msg("failed to connect to users service")
 	}
 
+	a, err := audit.NewAuditService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to audit service")
+	}
+
 	h := Handler{
 		l:   l,
 		Env: e,
 		U:   u,
+		A:   a,
 	}
 
 	lambda.Start(h.HandleRequest)
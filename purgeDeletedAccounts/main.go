@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	dbAudit "github.com/aschles4/finalProject/internal/pkg/dynamo/audit"
+	"github.com/aschles4/finalProject/internal/services/audit"
+	"github.com/aschles4/finalProject/internal/services/groups"
+	"github.com/aschles4/finalProject/internal/services/notifications"
+	"github.com/aschles4/finalProject/internal/services/parties"
+	"github.com/aschles4/finalProject/internal/services/reviews"
+	"github.com/aschles4/finalProject/internal/services/social"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	N   *notifications.Notifications
+	R   *reviews.Reviews
+	A   *audit.Audit
+	S   *social.Social
+	G   *groups.Groups
+	P   *parties.Parties
+	l   zerolog.Logger
+}
+
+//Runs once a day on a CloudWatch schedule and removes accounts whose deletion grace period is over.
+//The audit log is kept, it is append only and is what shows the deletion happened.
+func (h Handler) HandleRequest(ctx context.Context, event events.CloudWatchEvent) error {
+	accounts, err := h.U.FindAccountsDueForDeletion(ctx, time.Now())
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+		return err
+	}
+
+	purged, failed := 0, 0
+	for _, act := range *accounts {
+		act := act
+		//one broken account shouldn't hold up the rest, it is retried on the next run
+		err = h.N.RemoveUserData(ctx, act.ID)
+		if err != nil {
+			h.l.Error().Str("userId", act.ID).Msg(err.Error())
+			failed++
+			continue
+		}
+
+		err = h.R.RemoveUserData(ctx, act.ID)
+		if err != nil {
+			h.l.Error().Str("userId", act.ID).Msg(err.Error())
+			failed++
+			continue
+		}
+
+		err = h.S.RemoveUserData(ctx, act.ID)
+		if err != nil {
+			h.l.Error().Str("userId", act.ID).Msg(err.Error())
+			failed++
+			continue
+		}
+
+		err = h.G.RemoveUserData(ctx, act.ID)
+		if err != nil {
+			h.l.Error().Str("userId", act.ID).Msg(err.Error())
+			failed++
+			continue
+		}
+
+		err = h.P.RemoveUserData(ctx, act.ID)
+		if err != nil {
+			h.l.Error().Str("userId", act.ID).Msg(err.Error())
+			failed++
+			continue
+		}
+
+		err = h.U.PurgeAccount(ctx, &act)
+		if err != nil {
+			h.l.Error().Str("userId", act.ID).Msg(err.Error())
+			failed++
+			continue
+		}
+
+		err = h.A.Record(ctx, dbAudit.Event{
+			UserID: act.ID,
+			Action: audit.AccountDeleted,
+		})
+		if err != nil {
+			h.l.Error().Str("userId", act.ID).Msg(err.Error())
+		}
+		purged++
+	}
+
+	h.l.Info().
+		Int("purged", purged).
+		Int("failed", failed).
+		Msg("purged deleted accounts")
+
+	return nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	n, err := notifications.NewNotificationsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to notifications service")
+	}
+
+	r, err := reviews.NewReviewsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to reviews service")
+	}
+
+	a, err := audit.NewAuditService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to audit service")
+	}
+
+	s, err := social.NewSocialService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to social service")
+	}
+
+	g, err := groups.NewGroupsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to groups service")
+	}
+
+	p, err := parties.NewPartiesService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to parties service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		N:   n,
+		R:   r,
+		A:   a,
+		S:   s,
+		G:   g,
+		P:   p,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	dbNotifications "github.com/aschles4/finalProject/internal/pkg/dynamo/notifications"
+	"github.com/aschles4/finalProject/internal/services/notifications"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type RequestPasswordResetRequest struct {
+	Email string `json:"email"`
+}
+
+type RequestPasswordResetResponse struct {
+	Status  int    `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection   string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region       string `required:"true" default:"us-east-1" envconfig:"REGION"`
+	AppURL       string `required:"true" default:"http://localhost:3000" envconfig:"APP_URL"`
+	SMTPHost     string `required:"true" default:"localhost" envconfig:"SMTP_HOST"`
+	SMTPPort     int    `required:"true" default:"1025" envconfig:"SMTP_PORT"`
+	SMTPUsername string `default:"" envconfig:"SMTP_USERNAME"`
+	SMTPPassword string `default:"" envconfig:"SMTP_PASSWORD"`
+	SMTPFrom     string `required:"true" default:"notifications@localhost" envconfig:"SMTP_FROM"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	N   *notifications.Notifications
+	l   zerolog.Logger
+}
+
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var req RequestPasswordResetRequest
+	err := json.Unmarshal([]byte(event.Body), &req)
+	if err != nil {
+		return h.handleError(http.StatusBadRequest, err, "Failed to marshal event")
+	}
+
+	if req.Email == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Email is required")
+	}
+
+	token, act, err := h.U.CreatePasswordResetToken(ctx, req.Email)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to create reset token")
+	}
+
+	//answer the same way whether or not the account exists
+	if act == nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusAccepted,
+		}, nil
+	}
+
+	err = h.N.SendPasswordReset(ctx, dbNotifications.Recipient{
+		UserID: act.ID,
+		Email:  act.Email,
+	}, fmt.Sprintf("%s/reset-password?token=%s", h.Env.AppURL, url.QueryEscape(token)))
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to send reset email")
+	}
+
+	//return
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusAccepted,
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(RequestPasswordResetResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	n, err := notifications.NewNotificationsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to notifications service")
+	}
+
+	email, err := notifications.NewEmailChannel(e.SMTPHost, e.SMTPPort, e.SMTPUsername, e.SMTPPassword, e.SMTPFrom)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to create email channel")
+	}
+	n.RegisterChannel(email)
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		N:   n,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	dbReviews "github.com/aschles4/finalProject/internal/pkg/dynamo/reviews"
+	"github.com/aschles4/finalProject/internal/services/reviews"
+	"github.com/aschles4/finalProject/internal/services/social"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type SaveReviewRequest struct {
+	Rating int    `json:"rating"`
+	Text   string `json:"text,omitempty"`
+}
+
+type SaveReviewResponse struct {
+	Review  *dbReviews.Review `json:"review,omitempty"`
+	Status  int               `json:"status,omitempty"`
+	Message string            `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	R   *reviews.Reviews
+	S   *social.Social
+	l   zerolog.Logger
+}
+
+//Rates or reviews a movie, show or episode, sending it again edits the viewer's review
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	v, err := h.U.FindViewerByToken(ctx, token)
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	//season and episode are only in the path when reviewing an episode
+	t, err := reviews.NewTarget(event.PathParameters["type"], event.PathParameters["id"], event.PathParameters["season_num"], event.PathParameters["episode_num"])
+	if err != nil {
+		return h.handleError(http.StatusBadRequest, err, "Type must be movie or tv with a numeric ID")
+	}
+
+	var req SaveReviewRequest
+	err = json.Unmarshal([]byte(event.Body), &req)
+	if err != nil {
+		return h.handleError(http.StatusBadRequest, err, "Failed to marshal event")
+	}
+
+	r, err := h.R.SaveReview(ctx, v.Account.ID, v.Profile.ID, v.Profile.Name, *t, req.Rating, req.Text)
+	if err == reviews.ErrInvalidRating {
+		return h.handleError(http.StatusBadRequest, nil, "Rating must be between 1 and 10")
+	}
+	if err == reviews.ErrReviewTooLong {
+		return h.handleError(http.StatusBadRequest, nil, "Review is too long")
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to save review")
+	}
+
+	//the target was checked to be numeric by NewTarget
+	ID, _ := strconv.ParseInt(t.TMDBID, 10, 64)
+
+	//the review is saved either way, so a failed fan out is only logged
+	err = h.S.Publish(ctx, social.MemberOf(v.Profile), social.Activity{
+		Verb:      social.ActivityRating,
+		MediaType: t.MediaType,
+		TMDBID:    ID,
+		Season:    t.Season,
+		Episode:   t.Episode,
+		Rating:    r.Rating,
+	})
+	if err != nil {
+		h.l.Error().Str("profileId", v.Profile.ID).Msg(err.Error())
+	}
+
+	//return
+	js, err := json.Marshal(SaveReviewResponse{
+		Review: r,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(SaveReviewResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	r, err := reviews.NewReviewsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to reviews service")
+	}
+
+	s, err := social.NewSocialService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to social service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		R:   r,
+		S:   s,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type SelectProfileRequest struct {
+	ProfileID string `json:"profileId"`
+	PIN       string `json:"pin,omitempty"`
+}
+
+type SelectProfileResponse struct {
+	Token      string   `json:"token,omitempty"`
+	Profile    *Profile `json:"profile,omitempty"`
+	RetryAfter int      `json:"retryAfter,omitempty"`
+	Status     int      `json:"status,omitempty"`
+	Message    string   `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	l   zerolog.Logger
+}
+
+//Profile leaves out the PIN hash, HasPIN tells the client to ask for it
+type Profile struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Kids   bool   `json:"kids,omitempty"`
+	HasPIN bool   `json:"hasPin,omitempty"`
+}
+
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	act, err := h.U.FindUserAccountByToken(ctx, token)
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	var req SelectProfileRequest
+	err = json.Unmarshal([]byte(event.Body), &req)
+	if err != nil {
+		return h.handleError(http.StatusBadRequest, err, "Failed to marshal event")
+	}
+
+	if req.ProfileID == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Profile ID is required")
+	}
+
+	//the returned token is scoped to the profile, use it for everything but account settings
+	profToken, prof, err := h.U.SelectProfile(ctx, act, req.ProfileID, req.PIN)
+	if locked, ok := err.(*users.LockedError); ok {
+		retry := int(locked.RetryAfter(time.Now()).Seconds())
+		js, err := json.Marshal(SelectProfileResponse{
+			RetryAfter: retry,
+			Message:    "Too many wrong PINs, try again later",
+		})
+		if err != nil {
+			return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+		}
+
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusTooManyRequests,
+			Headers:    map[string]string{"Retry-After": strconv.Itoa(retry)},
+			Body:       string(js),
+		}, nil
+	}
+	if err == users.ErrProfileNotFound {
+		return h.handleError(http.StatusNotFound, nil, "Profile not found")
+	}
+	if err == users.ErrInvalidPIN {
+		return h.handleError(http.StatusUnauthorized, nil, "PIN is invalid")
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to select profile")
+	}
+
+	//return
+	js, err := json.Marshal(SelectProfileResponse{
+		Token: profToken,
+		Profile: &Profile{
+			ID:     prof.ID,
+			Name:   prof.Name,
+			Kids:   prof.Kids,
+			HasPIN: prof.PIN != "",
+		},
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(SelectProfileResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/aschles4/finalProject/internal/services/notifications"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type Env struct {
+	Connection   string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region       string `required:"true" default:"us-east-1" envconfig:"REGION"`
+	SMTPHost     string `required:"true" default:"localhost" envconfig:"SMTP_HOST"`
+	SMTPPort     int    `required:"true" default:"1025" envconfig:"SMTP_PORT"`
+	SMTPUsername string `default:"" envconfig:"SMTP_USERNAME"`
+	SMTPPassword string `default:"" envconfig:"SMTP_PASSWORD"`
+	SMTPFrom     string `required:"true" default:"notifications@localhost" envconfig:"SMTP_FROM"`
+}
+
+type Handler struct {
+	Env Env
+	N   *notifications.Notifications
+	U   *users.Users
+	l   zerolog.Logger
+}
+
+//Runs once a day on a CloudWatch schedule and sends one email per user with everything queued for their digest
+func (h Handler) HandleRequest(ctx context.Context, event events.CloudWatchEvent) error {
+	report, err := h.N.SendDigests(ctx, h.U, time.Now())
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+		return err
+	}
+
+	h.l.Info().
+		Int("sent", report.Sent).
+		Int("failed", report.Failed).
+		Int("dead", report.Dead).
+		Msg("sent digests")
+
+	return nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	n, err := notifications.NewNotificationsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to notifications service")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	email, err := notifications.NewEmailChannel(e.SMTPHost, e.SMTPPort, e.SMTPUsername, e.SMTPPassword, e.SMTPFrom)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to create email channel")
+	}
+
+	n.RegisterChannel(email)
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		N:   n,
+		U:   u,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
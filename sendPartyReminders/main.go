@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/aschles4/finalProject/internal/services/notifications"
+	"github.com/aschles4/finalProject/internal/services/parties"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	N   *notifications.Notifications
+	P   *parties.Parties
+	l   zerolog.Logger
+}
+
+// Runs on a CloudWatch schedule, reminding each invitee about a party as many minutes ahead as they asked for
+func (h Handler) HandleRequest(ctx context.Context, event events.CloudWatchEvent) error {
+	reminders, err := h.P.FindDueReminders(ctx, time.Now())
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+		return err
+	}
+
+	for _, r := range *reminders {
+		prof, err := h.U.FindUserProfileByID(ctx, r.Invite.ProfileID)
+		if err != nil {
+			h.l.Error().Str("inviteId", r.Invite.ID).Msg(err.Error())
+			continue
+		}
+
+		//a removed profile has nobody to remind, marking it stops it coming up every run
+		if prof != nil && prof.ID != "" {
+			title := fmt.Sprintf("%s starts soon", parties.Title(r.Party))
+			message := fmt.Sprintf("%s's watch party starts in %d minutes.", r.Party.HostName, minutesUntil(r.Party.StartsAt))
+			err = h.N.PublishPartyEvent(ctx, notifications.SubscriberFor(prof), notifications.PartyReminder, r.Party.ID, title, message, r.Party.StartsAt)
+			if err != nil {
+				h.l.Error().Str("inviteId", r.Invite.ID).Msg(err.Error())
+				continue
+			}
+		}
+
+		err = h.P.MarkReminded(ctx, r.Invite)
+		if err != nil {
+			h.l.Error().Str("inviteId", r.Invite.ID).Msg(err.Error())
+		}
+	}
+
+	return nil
+}
+
+//minutesUntil rounds up so a reminder that runs a little late doesn't say 0 minutes
+func minutesUntil(startsAt int64) int64 {
+	left := startsAt - time.Now().Unix()
+	if left <= 0 {
+		return 0
+	}
+
+	return (left + 59) / 60
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	n, err := notifications.NewNotificationsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to notifications service")
+	}
+
+	p, err := parties.NewPartiesService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to parties service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		N:   n,
+		P:   p,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
@@ -3,10 +3,17 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/mail"
+	"net/url"
 	"os"
 
+	dbAudit "github.com/aschles4/finalProject/internal/pkg/dynamo/audit"
+	dbNotifications "github.com/aschles4/finalProject/internal/pkg/dynamo/notifications"
 	dbUsers "github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/services/audit"
+	"github.com/aschles4/finalProject/internal/services/notifications"
 	"github.com/aschles4/finalProject/internal/services/users"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -30,33 +37,42 @@ type SignupResponse struct {
 }
 
 type Env struct {
-	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
-	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+	Connection   string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region       string `required:"true" default:"us-east-1" envconfig:"REGION"`
+	AppURL       string `required:"true" default:"http://localhost:3000" envconfig:"APP_URL"`
+	SMTPHost     string `required:"true" default:"localhost" envconfig:"SMTP_HOST"`
+	SMTPPort     int    `required:"true" default:"1025" envconfig:"SMTP_PORT"`
+	SMTPUsername string `default:"" envconfig:"SMTP_USERNAME"`
+	SMTPPassword string `default:"" envconfig:"SMTP_PASSWORD"`
+	SMTPFrom     string `required:"true" default:"notifications@localhost" envconfig:"SMTP_FROM"`
 }
 
 type Handler struct {
 	Env Env
 	U   *users.Users
+	A   *audit.Audit
+	N   *notifications.Notifications
 	l   zerolog.Logger
 }
 
 func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	js, err := json.Marshal(event.Body)
-	if err != nil {
-		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal request")
-	}
-	println(string(js))
-
 	var req SignupRequest
-	err = json.Unmarshal([]byte(event.Body), &req)
+	err := json.Unmarshal([]byte(event.Body), &req)
 	if err != nil {
 		return h.handleError(http.StatusBadRequest, err, "Failed to marshal event")
 	}
 
+	//stored the way lookups and lockout counters see it, so one address can't make two accounts
+	req.Email = users.NormalizeEmail(req.Email)
 	if req.Email == "" {
 		return h.handleError(http.StatusBadRequest, nil, "Email is required")
 	}
 
+	addr, err := mail.ParseAddress(req.Email)
+	if err != nil || addr.Address != req.Email {
+		return h.handleError(http.StatusBadRequest, err, "Email is not a valid address")
+	}
+
 	if req.Password == "" {
 		return h.handleError(http.StatusBadRequest, nil, "Password is required")
 	}
@@ -66,6 +82,9 @@ func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxy
 	}
 
 	act, err := h.U.FindUserAccountByEmail(ctx, req.Email)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to check for an existing account")
+	}
 	if act != nil {
 		return h.handleError(http.StatusBadRequest, nil, "Account already created with email")
 	}
@@ -82,14 +101,31 @@ func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxy
 	if err != nil {
 		return h.handleError(http.StatusInternalServerError, err, "Failed to Create User Account")
 	}
+	h.record(ctx, event, ID, audit.Signup, nil)
+
+	//email a verification link, the account works unverified so a failed send only gets logged
+	verifyToken, err := h.U.CreateVerificationToken(ctx, ID)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to Create Verification Token")
+	}
+
+	err = h.N.SendEmailVerification(ctx, dbNotifications.Recipient{
+		UserID: ID,
+		Name:   req.Name,
+		Email:  req.Email,
+	}, fmt.Sprintf("%s/verify-email?token=%s", h.Env.AppURL, url.QueryEscape(verifyToken)))
+	if err != nil {
+		h.l.Error().Str("userId", ID).Msg(err.Error())
+	}
 
-	//Login User Here
-	token, err := h.U.LoginUser(ctx, req.Email, req.Password)
+	//the account was just made with this password, earlier failed logins from the ip don't apply
+	token, err := h.U.StartSession(ctx, ID)
 	if err != nil {
 		return h.handleError(http.StatusInternalServerError, err, "Failed to Login User On SignUp")
 	}
+	h.record(ctx, event, ID, audit.LoginSucceeded, nil)
 
-	js, err = json.Marshal(SignupResponse{
+	js, err := json.Marshal(SignupResponse{
 		UserID: ID,
 		Token:  token,
 	})
@@ -126,6 +162,20 @@ func (h Handler) handleError(status int, err error, message string) (events.APIG
 	}, nil
 }
 
+//record keeps a failed audit write from failing the request, it only gets logged
+func (h Handler) record(ctx context.Context, event events.APIGatewayProxyRequest, userID, action string, data map[string]string) {
+	err := h.A.Record(ctx, dbAudit.Event{
+		UserID:    userID,
+		Action:    action,
+		SourceIP:  event.RequestContext.Identity.SourceIP,
+		UserAgent: event.RequestContext.Identity.UserAgent,
+		Data:      data,
+	})
+	if err != nil {
+		h.l.Error().Str("userId", userID).Str("action", action).Msg(err.Error())
+	}
+}
+
 func main() {
 	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
 
@@ -142,10 +192,31 @@ func main() {
 		l.Fatal().Msg("failed to connect to users service")
 	}
 
+	a, err := audit.NewAuditService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to audit service")
+	}
+
+	n, err := notifications.NewNotificationsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to notifications service")
+	}
+
+	email, err := notifications.NewEmailChannel(e.SMTPHost, e.SMTPPort, e.SMTPUsername, e.SMTPPassword, e.SMTPFrom)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to create email channel")
+	}
+	n.RegisterChannel(email)
+
 	h := Handler{
 		l:   l,
 		Env: e,
 		U:   u,
+		A:   a,
+		N:   n,
 	}
 
 	lambda.Start(h.HandleRequest)
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	dbGroups "github.com/aschles4/finalProject/internal/pkg/dynamo/groups"
+	dbUsers "github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/services/content"
+	"github.com/aschles4/finalProject/internal/services/groups"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type StartGroupSessionResponse struct {
+	Session *dbGroups.Session `json:"session,omitempty"`
+	Status  int               `json:"status,omitempty"`
+	Message string            `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection  string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region      string `required:"true" default:"us-east-1" envconfig:"REGION"`
+	IMBDKey     string `required:"true" default:"" envconfig:"IMBD_KEY"`
+	GuideBoxKey string `required:"true" default:"" envconfig:"GB_KEY"`
+	ImageCDN    string `default:"" envconfig:"IMAGE_CDN"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	C   *content.Content
+	G   *groups.Groups
+	l   zerolog.Logger
+}
+
+//Closes the session to new members and puts titles from the members' libraries to the vote
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	v, err := h.U.FindViewerByToken(ctx, token)
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	//titles, release dates, ratings and availability in the viewer's language and region
+	localized := h.C.WithLocale(content.ResolveLocale(users.LocaleFor(v.Profile), event.Headers["Accept-Language"], event.QueryStringParameters["language"], event.QueryStringParameters["region"]))
+	h.C = &localized
+
+	ID := event.PathParameters["id"]
+	if ID == "" {
+		return h.handleError(http.StatusBadRequest, nil, "ID is required")
+	}
+
+	members, err := h.G.FindMembers(ctx, v.Profile.ID, ID)
+	if err == groups.ErrSessionNotFound {
+		return h.handleError(http.StatusNotFound, nil, "Session not found")
+	}
+	if err == groups.ErrNotHost {
+		return h.handleError(http.StatusForbidden, nil, "Only the host can do that")
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to find session")
+	}
+
+	//candidates come from the members' current libraries, not what they had when they joined
+	profiles := make([]*dbUsers.UserProfile, 0, len(members))
+	for _, m := range members {
+		prof, err := h.U.FindUserProfileByID(ctx, m.ProfileID)
+		if err != nil {
+			return h.handleError(http.StatusInternalServerError, err, "Failed to find member profiles")
+		}
+
+		//a member whose profile was removed since joining has nothing to add
+		if prof != nil && prof.ID != "" {
+			profiles = append(profiles, prof)
+		}
+	}
+
+	candidates := groups.RankCandidates(profiles)
+
+	//a failed lookup keeps the titles it missed, it shouldn't stop the group voting
+	providers, err := h.C.FindStreamingProviders(ctx, groups.CandidateKeys(candidates), "")
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+	candidates = groups.FilterByProviders(candidates, providers, groups.SubscribedProviders(profiles))
+
+	candidates, err = h.filterByRating(ctx, candidates, profiles)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to apply parental controls")
+	}
+
+	s, err := h.G.StartVoting(ctx, v.Profile.ID, ID, candidates)
+	if err == groups.ErrNoCandidates {
+		return h.handleError(http.StatusConflict, nil, "Nothing the group has in common to vote on, add to your libraries and try again")
+	}
+	if err == groups.ErrSessionStarted {
+		return h.handleError(http.StatusConflict, nil, "Session has already started voting")
+	}
+	if err == groups.ErrSessionNotFound {
+		return h.handleError(http.StatusNotFound, nil, "Session not found")
+	}
+	if err == groups.ErrNotHost {
+		return h.handleError(http.StatusForbidden, nil, "Only the host can do that")
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to start voting")
+	}
+
+	//return
+	js, err := json.Marshal(StartGroupSessionResponse{
+		Session: s,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+//filterByRating drops anything over any member's parental controls, the whole group is watching
+func (h Handler) filterByRating(ctx context.Context, candidates []dbGroups.Candidate, profiles []*dbUsers.UserProfile) ([]dbGroups.Candidate, error) {
+	list := make([]content.Thumbnail, 0, len(candidates))
+	for _, c := range candidates {
+		list = append(list, content.Thumbnail{ID: strconv.FormatInt(c.ID, 10), Type: c.Type})
+	}
+	s := []content.Suggestion{{List: list}}
+
+	for _, p := range profiles {
+		var err error
+		s, err = h.C.FilterByRating(ctx, s, users.RatingLimitFor(p))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	allowed := make(map[string]bool)
+	for _, row := range s {
+		for _, t := range row.List {
+			allowed[content.TitleKey(t.Type, t.ID)] = true
+		}
+	}
+
+	filtered := make([]dbGroups.Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		if allowed[content.TitleKey(c.Type, strconv.FormatInt(c.ID, 10))] {
+			filtered = append(filtered, c)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(StartGroupSessionResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	c, err := content.NewContentService(e.IMBDKey, e.GuideBoxKey)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to content service")
+	}
+
+	//images go through the CDN when there is one
+	c.ImageBase = e.ImageCDN
+
+	g, err := groups.NewGroupsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to groups service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		C:   c,
+		G:   g,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/aschles4/finalProject/internal/pkg/oidc"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type StartOIDCLoginResponse struct {
+	URL     string `json:"url,omitempty"`
+	Status  int    `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection  string       `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region      string       `required:"true" default:"us-east-1" envconfig:"REGION"`
+	Providers   oidc.Configs `default:"" envconfig:"OIDC_PROVIDERS"`
+	CallbackURL string       `required:"true" default:"http://localhost:3000/oidc" envconfig:"OIDC_CALLBACK_URL"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	l   zerolog.Logger
+}
+
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	name := event.PathParameters["provider"]
+	cfg, ok := h.Env.Providers.Find(name)
+	if !ok {
+		return h.handleError(http.StatusNotFound, nil, "Unknown login provider")
+	}
+
+	//the redirect must match what is registered with the provider exactly
+	p, err := oidc.NewProvider(ctx, cfg, fmt.Sprintf("%s/%s/callback", h.Env.CallbackURL, name))
+	if err != nil {
+		return h.handleError(http.StatusBadGateway, err, "Failed to reach login provider")
+	}
+
+	//signed in users are linking another identity to their account
+	var linkUserID string
+	if val, ok := event.Headers["Authorization"]; ok && strings.Contains(val, " ") {
+		act, err := h.U.FindUserAccountByToken(ctx, strings.Split(val, " ")[1])
+		if err != nil {
+			return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+		}
+		linkUserID = act.ID
+	}
+
+	u, err := h.U.StartOIDCLogin(ctx, p, linkUserID)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to start login")
+	}
+
+	//browsers navigating here get sent straight on, api clients read the url
+	if event.QueryStringParameters["redirect"] == "true" {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusFound,
+			Headers:    map[string]string{"Location": u},
+		}, nil
+	}
+
+	//return
+	js, err := json.Marshal(StartOIDCLoginResponse{
+		URL: u,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(StartOIDCLoginResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
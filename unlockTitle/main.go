@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aschles4/finalProject/internal/services/content"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type UnlockTitleRequest struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	PIN  string `json:"pin"`
+}
+
+type UnlockTitleResponse struct {
+	RetryAfter int    `json:"retryAfter,omitempty"`
+	Status     int    `json:"status,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	l   zerolog.Logger
+}
+
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	v, err := h.U.FindViewerByToken(ctx, token)
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	var req UnlockTitleRequest
+	err = json.Unmarshal([]byte(event.Body), &req)
+	if err != nil {
+		return h.handleError(http.StatusBadRequest, err, "Failed to marshal event")
+	}
+
+	if req.Type != content.MediaMovie && req.Type != content.MediaTV {
+		return h.handleError(http.StatusBadRequest, nil, "Type must be movie or tv")
+	}
+
+	if req.ID == "" || req.PIN == "" {
+		return h.handleError(http.StatusBadRequest, nil, "ID and PIN are required")
+	}
+
+	//the pin is the account owner's, not the restricted profile's
+	err = h.U.UnlockTitle(ctx, v, req.Type, req.ID, req.PIN)
+	if locked, ok := err.(*users.LockedError); ok {
+		retry := int(locked.RetryAfter(time.Now()).Seconds())
+		js, err := json.Marshal(UnlockTitleResponse{
+			RetryAfter: retry,
+			Message:    "Too many wrong PINs, try again later",
+		})
+		if err != nil {
+			return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+		}
+
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusTooManyRequests,
+			Headers:    map[string]string{"Retry-After": strconv.Itoa(retry)},
+			Body:       string(js),
+		}, nil
+	}
+	if err == users.ErrInvalidPIN {
+		return h.handleError(http.StatusUnauthorized, nil, "PIN is invalid")
+	}
+	if err == users.ErrNoOwnerPIN {
+		return h.handleError(http.StatusBadRequest, nil, "Set a PIN on the account owner's profile to unlock titles")
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to unlock title")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusNoContent,
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(UnlockTitleResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
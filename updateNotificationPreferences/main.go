@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+
+	dbUsers "github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/pkg/webhooks"
+	"github.com/aschles4/finalProject/internal/services/notifications"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type UpdateNotificationPreferencesRequest struct {
+	Channels   []string `json:"channels"`
+	WebhookURL string   `json:"webhookUrl"`
+	Digest     bool     `json:"digest"`
+}
+
+type UpdateNotificationPreferencesResponse struct {
+	Preferences *dbUsers.NotificationPreferences `json:"preferences,omitempty"`
+	Status      int                              `json:"status,omitempty"`
+	Message     string                           `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	l   zerolog.Logger
+}
+
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	var req UpdateNotificationPreferencesRequest
+	err := json.Unmarshal([]byte(event.Body), &req)
+	if err != nil {
+		return h.handleError(http.StatusBadRequest, err, "Failed to marshal event")
+	}
+
+	for _, c := range req.Channels {
+		if c != notifications.ChannelEmail && c != notifications.ChannelWebhook {
+			return h.handleError(http.StatusBadRequest, nil, "Channels must be email or webhook")
+		}
+
+		if c == notifications.ChannelWebhook && req.WebhookURL == "" {
+			return h.handleError(http.StatusBadRequest, nil, "Webhook URL is required for the webhook channel")
+		}
+	}
+
+	act, err := h.U.FindUserAccountByToken(ctx, token)
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	prof, err := h.U.UpdateNotificationPreferences(ctx, act.ID, dbUsers.NotificationPreferences{
+		Channels:   req.Channels,
+		WebhookURL: req.WebhookURL,
+		Digest:     req.Digest,
+	})
+	if err == webhooks.ErrNotHTTPS {
+		return h.handleError(http.StatusBadRequest, nil, "Webhook URL must use https")
+	}
+	if err == webhooks.ErrUnknownHost {
+		return h.handleError(http.StatusBadRequest, nil, "Webhook URL host could not be found")
+	}
+	if err == webhooks.ErrPrivateAddress {
+		return h.handleError(http.StatusBadRequest, nil, "Webhook URL must point at a public address")
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to update notification preferences")
+	}
+
+	//return, including the webhook secret so the receiver can verify signatures
+	js, err := json.Marshal(UpdateNotificationPreferencesResponse{
+		Preferences: &prof.Notifications,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(UpdateNotificationPreferencesResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
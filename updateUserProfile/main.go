@@ -5,9 +5,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
+	dbAudit "github.com/aschles4/finalProject/internal/pkg/dynamo/audit"
 	dbUsers "github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/services/audit"
 	"github.com/aschles4/finalProject/internal/services/users"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -16,14 +20,16 @@ import (
 )
 
 type UpdateUserProfileRequest struct {
-	Password       string                   `json:"password"`
-	Name           string                   `json:"name"`
-	StreamAccounts []dbUsers.StreamAccounts `json:"streamAccounts"`
+	Password        string                   `json:"password"`
+	CurrentPassword string                   `json:"currentPassword"`
+	Name            string                   `json:"name"`
+	StreamAccounts  []dbUsers.StreamAccounts `json:"streamAccounts"`
 }
 
 type UpdateUserProfileResponse struct {
-	Status  int    `json:"status,omitempty"`
-	Message string `json:"message,omitempty"`
+	RetryAfter int    `json:"retryAfter,omitempty"`
+	Status     int    `json:"status,omitempty"`
+	Message    string `json:"message,omitempty"`
 }
 
 type Env struct {
@@ -34,6 +40,7 @@ type Env struct {
 type Handler struct {
 	Env Env
 	U   *users.Users
+	A   *audit.Audit
 	l   zerolog.Logger
 }
 
@@ -47,14 +54,8 @@ func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxy
 		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
 	}
 
-	js, err := json.Marshal(event.Body)
-	if err != nil {
-		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal request")
-	}
-	println(string(js))
-
 	var req UpdateUserProfileRequest
-	err = json.Unmarshal([]byte(event.Body), &req)
+	err := json.Unmarshal([]byte(event.Body), &req)
 	if err != nil {
 		return h.handleError(http.StatusBadRequest, err, "Failed to marshal event")
 	}
@@ -64,44 +65,61 @@ func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxy
 		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
 	}
 
-	prof, err := h.U.FindUserProfileByID(ctx, act.ID)
-	if err != nil {
-		return h.handleError(http.StatusInternalServerError, err, "Failed to access user profile")
+	//a valid session isn't enough to change the password, the current one is asked for again
+	if req.Password != "" && req.CurrentPassword == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Current Password is required")
 	}
 
-	//Delete User Profile
-	err = h.U.RemoveUserProfileByID(ctx, act.ID)
-	if err != nil {
-		return h.handleError(http.StatusInternalServerError, err, "Failed to Remove Old User Profile")
-	}
-	//Delete users account
-	err = h.U.RemoveUserAccountByID(ctx, act.ID)
+	prof, err := h.U.FindUserProfileByID(ctx, act.ID)
 	if err != nil {
-		return h.handleError(http.StatusInternalServerError, err, "Failed to Remove User Account")
-	}
-
-	//Update values if they are updated
-	pass := req.Password
-	if req.Password == "" {
-		pass = act.Password
-	}
-
-	name := req.Name
-	if req.Name == "" {
-		pass = prof.Name
+		return h.handleError(http.StatusInternalServerError, err, "Failed to access user profile")
 	}
 
-	//create users profile
-	err = h.U.CreateUserProfile(ctx, act.ID, name, act.Email, req.StreamAccounts)
+	//changing the password signs the user out, so the client has to login again
+	if req.Password != "" {
+		err = h.U.ChangeOwnPassword(ctx, act, req.CurrentPassword, req.Password, event.RequestContext.Identity.SourceIP)
+		if locked, ok := err.(*users.LockedError); ok {
+			retry := int(locked.RetryAfter(time.Now()).Seconds())
+			js, err := json.Marshal(UpdateUserProfileResponse{
+				RetryAfter: retry,
+				Message:    "Too many failed attempts, try again later",
+			})
+			if err != nil {
+				return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+			}
+
+			return events.APIGatewayProxyResponse{
+				StatusCode: http.StatusTooManyRequests,
+				Headers:    map[string]string{"Retry-After": strconv.Itoa(retry)},
+				Body:       string(js),
+			}, nil
+		}
+		if err == users.ErrInvalidCredentials {
+			return h.handleError(http.StatusUnauthorized, nil, "Current Password is incorrect")
+		}
+		if err != nil {
+			return h.handleError(http.StatusInternalServerError, err, "Failed to Update Password")
+		}
+		h.record(ctx, event, act.ID, audit.PasswordChanged, map[string]string{"method": "profile"})
+	}
+
+	//Update values if they are updated, keeping the rest of the profile
+	if req.Name != "" {
+		prof.Name = req.Name
+	}
+	prof.StreamAccounts = req.StreamAccounts
+
+	err = h.U.UpdateUserProfile(ctx, *prof)
 	if err != nil {
-		return h.handleError(http.StatusInternalServerError, err, "Failed to Create User Account")
+		return h.handleError(http.StatusInternalServerError, err, "Failed to Update User Profile")
 	}
 
-	//create users account
-	err = h.U.CreateUserAccountWithToken(ctx, act.ID, act.Email, pass, token)
+	//the household watches through the same subscriptions
+	err = h.U.ShareStreamAccounts(ctx, act, req.StreamAccounts)
 	if err != nil {
-		return h.handleError(http.StatusInternalServerError, err, "Failed to Create User Account")
+		return h.handleError(http.StatusInternalServerError, err, "Failed to Update Household Profiles")
 	}
+	h.record(ctx, event, act.ID, audit.ProfileChanged, nil)
 
 	//return
 	return events.APIGatewayProxyResponse{
@@ -131,6 +149,20 @@ func (h Handler) handleError(status int, err error, message string) (events.APIG
 	}, nil
 }
 
+//record keeps a failed audit write from failing the request, it only gets logged
+func (h Handler) record(ctx context.Context, event events.APIGatewayProxyRequest, userID, action string, data map[string]string) {
+	err := h.A.Record(ctx, dbAudit.Event{
+		UserID:    userID,
+		Action:    action,
+		SourceIP:  event.RequestContext.Identity.SourceIP,
+		UserAgent: event.RequestContext.Identity.UserAgent,
+		Data:      data,
+	})
+	if err != nil {
+		h.l.Error().Str("userId", userID).Str("action", action).Msg(err.Error())
+	}
+}
+
 func main() {
 	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
 
@@ -147,10 +179,17 @@ func main() {
 		l.Fatal().Msg("failed to connect to users service")
 	}
 
+	a, err := audit.NewAuditService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to audit service")
+	}
+
 	h := Handler{
 		l:   l,
 		Env: e,
 		U:   u,
+		A:   a,
 	}
 
 	lambda.Start(h.HandleRequest)
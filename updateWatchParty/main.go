@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	dbParties "github.com/aschles4/finalProject/internal/pkg/dynamo/parties"
+	"github.com/aschles4/finalProject/internal/services/notifications"
+	"github.com/aschles4/finalProject/internal/services/parties"
+	"github.com/aschles4/finalProject/internal/services/social"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type UpdateWatchPartyRequest struct {
+	Name     *string    `json:"name"`
+	Note     *string    `json:"note"`
+	StartsAt *time.Time `json:"startsAt"`
+	Duration *int       `json:"duration"`
+	Invite   []string   `json:"invite"`
+	Uninvite []string   `json:"uninvite"`
+}
+
+type UpdateWatchPartyResponse struct {
+	*parties.State
+	Status  int    `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region     string `required:"true" default:"us-east-1" envconfig:"REGION"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	S   *social.Social
+	N   *notifications.Notifications
+	P   *parties.Parties
+	l   zerolog.Logger
+}
+
+//Lets the host change the party and who is invited, a new time is sent to everyone and moves their reminders
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	v, err := h.U.FindViewerByToken(ctx, token)
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+	m := social.MemberOf(v.Profile)
+
+	ID := event.PathParameters["id"]
+	if ID == "" {
+		return h.handleError(http.StatusBadRequest, nil, "ID is required")
+	}
+
+	var req UpdateWatchPartyRequest
+	err = json.Unmarshal([]byte(event.Body), &req)
+	if err != nil {
+		return h.handleError(http.StatusBadRequest, err, "Failed to marshal event")
+	}
+
+	guests, err := h.findGuests(ctx, m, req.Invite)
+	if err == errUnknownGuest {
+		return h.handleError(http.StatusBadRequest, nil, err.Error())
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to find invitees")
+	}
+
+	before, err := h.P.FindParty(ctx, m.ProfileID, ID)
+	if err == parties.ErrPartyNotFound {
+		return h.handleError(http.StatusNotFound, nil, "Party not found")
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to find party")
+	}
+
+	c := parties.Changes{
+		Name:     req.Name,
+		Note:     req.Note,
+		StartsAt: req.StartsAt,
+		Duration: req.Duration,
+	}
+
+	state, added, err := h.P.UpdateParty(ctx, m.ProfileID, ID, c, guests, req.Uninvite)
+	if err == parties.ErrPartyNotFound {
+		return h.handleError(http.StatusNotFound, nil, "Party not found")
+	}
+	if err == parties.ErrNotHost {
+		return h.handleError(http.StatusForbidden, nil, "Only the host can change a party")
+	}
+	if err == parties.ErrPartyCancelled {
+		return h.handleError(http.StatusConflict, nil, "Party has been cancelled")
+	}
+	if err == parties.ErrInvalidTarget || err == parties.ErrStartInPast || err == parties.ErrInvalidDuration || err == parties.ErrPartyNameTooLong || err == parties.ErrPartyNoteTooLong || err == parties.ErrTooManyInvitees {
+		return h.handleError(http.StatusBadRequest, nil, err.Error())
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to update party")
+	}
+
+	party := *state.Party
+	title := parties.Title(party)
+	h.notify(ctx, notifications.PartyInvite, party, added, fmt.Sprintf("%s invited you to watch %s", party.HostName, title), "Let them know if you can make it.")
+
+	//everyone already invited hears about a new time, other changes are quiet
+	if party.Sequence != before.Party.Sequence {
+		invited := make(map[string]bool)
+		for _, i := range added {
+			invited[i.ProfileID] = true
+		}
+
+		kept := make([]dbParties.Invite, 0, len(state.Invites))
+		for _, i := range state.Invites {
+			if !invited[i.ProfileID] {
+				kept = append(kept, i)
+			}
+		}
+
+		h.notify(ctx, notifications.PartyChanged, party, kept, fmt.Sprintf("The %s watch party has moved", title), "Check the new time and update your RSVP if you need to.")
+	}
+
+	//return
+	js, err := json.Marshal(UpdateWatchPartyResponse{
+		State: state,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+var errUnknownGuest = errors.New("guests must be profiles on your account or people who follow you")
+
+//findGuests looks up the profiles to invite, skipping the host and repeats. Anyone outside the host's
+//account has to follow the host.
+func (h Handler) findGuests(ctx context.Context, m social.Member, profileIDs []string) ([]parties.Guest, error) {
+	seen := map[string]bool{m.ProfileID: true}
+	guests := make([]parties.Guest, 0, len(profileIDs))
+	for _, ID := range profileIDs {
+		if seen[ID] {
+			continue
+		}
+		seen[ID] = true
+
+		prof, err := h.U.FindUserProfileByID(ctx, ID)
+		if err != nil {
+			return nil, err
+		}
+		if prof == nil || prof.ID == "" {
+			return nil, errUnknownGuest
+		}
+
+		g := social.MemberOf(prof)
+		if g.AccountID != m.AccountID {
+			//invites are emailed, so only people who follow the host can be sent one
+			ok, err := h.S.Follows(ctx, g.ProfileID, m.ProfileID)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, errUnknownGuest
+			}
+		}
+
+		guests = append(guests, parties.Guest{
+			AccountID: g.AccountID,
+			ProfileID: g.ProfileID,
+			Name:      g.Name,
+		})
+	}
+
+	return guests, nil
+}
+
+//notify lets the invitees know, a failed notification doesn't undo the change
+func (h Handler) notify(ctx context.Context, typ string, party dbParties.Party, invites []dbParties.Invite, title, message string) {
+	for _, i := range invites {
+		if i.ProfileID == party.HostID {
+			continue
+		}
+
+		prof, err := h.U.FindUserProfileByID(ctx, i.ProfileID)
+		if err != nil || prof == nil || prof.ID == "" {
+			h.l.Error().Str("inviteId", i.ID).Msg("failed to find invitee profile")
+			continue
+		}
+
+		err = h.N.PublishPartyEvent(ctx, notifications.SubscriberFor(prof), typ, party.ID, title, message, party.StartsAt)
+		if err != nil {
+			h.l.Error().Str("inviteId", i.ID).Msg(err.Error())
+		}
+	}
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(UpdateWatchPartyResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	s, err := social.NewSocialService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to social service")
+	}
+
+	n, err := notifications.NewNotificationsService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to notifications service")
+	}
+
+	p, err := parties.NewPartiesService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to parties service")
+	}
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		S:   s,
+		N:   n,
+		P:   p,
+	}
+
+	lambda.Start(h.HandleRequest)
+}
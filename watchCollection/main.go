@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	dbUsers "github.com/aschles4/finalProject/internal/pkg/dynamo/users"
+	"github.com/aschles4/finalProject/internal/services/content"
+	"github.com/aschles4/finalProject/internal/services/users"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+)
+
+type WatchCollectionRequest struct {
+	ListID string `json:"listId"`
+}
+
+type WatchCollectionResponse struct {
+	List       *dbUsers.List       `json:"list,omitempty"`
+	Collection *content.Collection `json:"collection,omitempty"`
+	Status     int                 `json:"status,omitempty"`
+	Message    string              `json:"message,omitempty"`
+}
+
+type Env struct {
+	Connection  string `required:"true" default:"https://dynamodb.us-east-1.amazonaws.com" envconfig:"CONNECTION"`
+	Region      string `required:"true" default:"us-east-1" envconfig:"REGION"`
+	IMBDKey     string `required:"true" default:"" envconfig:"IMBD_KEY"`
+	GuideBoxKey string `required:"true" default:"" envconfig:"GB_KEY"`
+	ImageCDN    string `default:"" envconfig:"IMAGE_CDN"`
+}
+
+type Handler struct {
+	Env Env
+	U   *users.Users
+	C   *content.Content
+	l   zerolog.Logger
+}
+
+//Adds every part of a collection to one of the profile's lists, or a new one, and reports how far through it they are
+func (h Handler) HandleRequest(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	var token string
+	if val, ok := event.Headers["Authorization"]; ok {
+		token = strings.Split(val, " ")[1]
+	}
+
+	if token == "" {
+		return h.handleError(http.StatusBadRequest, nil, "Authorization Header is required")
+	}
+
+	v, err := h.U.FindViewerForScope(ctx, token, users.ScopeWriteLibrary)
+	if err == users.ErrForbidden {
+		return h.handleError(http.StatusForbidden, nil, "API key does not have the library:write scope")
+	}
+	if err != nil {
+		return h.handleError(http.StatusUnauthorized, err, "Failed to authorize request")
+	}
+
+	//titles, release dates, ratings and availability in the viewer's language and region
+	localized := h.C.WithLocale(content.ResolveLocale(users.LocaleFor(v.Profile), event.Headers["Accept-Language"], event.QueryStringParameters["language"], event.QueryStringParameters["region"]))
+	h.C = &localized
+
+	ID := event.PathParameters["id"]
+	if ID == "" {
+		return h.handleError(http.StatusBadRequest, nil, "ID is required")
+	}
+
+	col, err := h.C.FindCollectionByID(ctx, ID)
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to access collection")
+	}
+
+	//drop parts over the profile's parental controls
+	filtered, err := h.C.FilterPartsByRating(ctx, *col, users.RatingLimitFor(v.Profile))
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to apply parental controls")
+	}
+
+	watched := make(map[string]bool)
+	for _, w := range v.Profile.WatchHistory {
+		if w.Type == content.MediaMovie || w.Type == "" {
+			watched[strconv.FormatInt(w.ID, 10)] = true
+		}
+	}
+	filtered = filtered.WithProgress(watched)
+
+	var req WatchCollectionRequest
+	if event.Body != "" {
+		err = json.Unmarshal([]byte(event.Body), &req)
+		if err != nil {
+			return h.handleError(http.StatusBadRequest, err, "Failed to marshal event")
+		}
+	}
+
+	//the list named after the collection unless the client picks one, so watching it again
+	//fills in the same list instead of starting another
+	listID := req.ListID
+	created := false
+	if listID == "" {
+		lists, err := h.U.FindLists(ctx, v.Profile)
+		if err != nil {
+			return h.handleError(http.StatusInternalServerError, err, "Failed to access lists")
+		}
+
+		for _, l := range *lists {
+			if l.Name == filtered.Name || l.Name == fallbackListName(filtered) {
+				listID = l.ID
+				break
+			}
+		}
+	}
+	if listID == "" {
+		l, err := h.U.CreateList(ctx, v.Profile, filtered.Name, filtered.Overview, users.VisibilityPrivate)
+		if err == users.ErrTooManyLists {
+			return h.handleError(http.StatusConflict, nil, fmt.Sprintf("Profiles can have %d lists at most", users.MaxLists))
+		}
+		if err == users.ErrListNameRequired || err == users.ErrListNameTooLong || err == users.ErrListDescTooLong {
+			//TMDB names and overviews can run past our limits, fall back to a plain list
+			l, err = h.U.CreateList(ctx, v.Profile, fallbackListName(filtered), "", users.VisibilityPrivate)
+		}
+		if err != nil {
+			return h.handleError(http.StatusInternalServerError, err, "Failed to create list")
+		}
+		listID = l.ID
+		created = true
+	}
+
+	items := make([]dbUsers.ListItem, 0, len(filtered.Parts))
+	for _, p := range filtered.Parts {
+		partID, err := strconv.ParseInt(p.ID, 10, 64)
+		if err != nil {
+			continue
+		}
+		items = append(items, dbUsers.ListItem{ID: partID, Type: content.MediaMovie})
+	}
+
+	//parts already in the list keep their place
+	l, err := h.U.AddAllToList(ctx, v.Profile, listID, items)
+	if err != nil && created {
+		//the list was only made for these parts, don't leave it behind empty
+		rmErr := h.U.RemoveList(ctx, v.Profile, listID)
+		if rmErr != nil {
+			h.l.Error().Str("listId", listID).Msg(rmErr.Error())
+		}
+	}
+	if err == users.ErrListNotFound {
+		return h.handleError(http.StatusNotFound, nil, "List not found")
+	}
+	if err == users.ErrTooManyListItems {
+		return h.handleError(http.StatusConflict, nil, fmt.Sprintf("Lists can hold %d titles at most", users.MaxListItems))
+	}
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to add to list")
+	}
+
+	//return
+	js, err := json.Marshal(WatchCollectionResponse{
+		List:       l,
+		Collection: &filtered,
+	})
+	if err != nil {
+		return h.handleError(http.StatusInternalServerError, err, "Failed to marshal response")
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: http.StatusOK,
+		Body:       string(js),
+	}, nil
+}
+
+//fallbackListName is the list name used when the collection's own name or overview won't fit
+func fallbackListName(col content.Collection) string {
+	return fmt.Sprintf("Collection %s", col.ID)
+}
+
+func (h Handler) handleError(status int, err error, message string) (events.APIGatewayProxyResponse, error) {
+	h.l.Error().Msg(message)
+	if err != nil {
+		h.l.Error().Msg(err.Error())
+	}
+
+	js, err := json.Marshal(WatchCollectionResponse{
+		Message: message,
+	})
+	if err != nil {
+		return events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       "{\"message\":\"InternalServerError\"}",
+		}, nil
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: status,
+		Body:       string(js),
+	}, nil
+}
+
+func main() {
+	l := zerolog.New(os.Stderr).With().Timestamp().Logger()
+
+	var e Env
+	err := envconfig.Process("", &e)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to parse envs")
+	}
+
+	u, err := users.NewUsersService(e.Connection, e.Region)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to users service")
+	}
+
+	c, err := content.NewContentService(e.IMBDKey, e.GuideBoxKey)
+	if err != nil {
+		l.Info().Msg(err.Error())
+		l.Fatal().Msg("failed to connect to content service")
+	}
+
+	//images go through the CDN when there is one
+	c.ImageBase = e.ImageCDN
+
+	h := Handler{
+		l:   l,
+		Env: e,
+		U:   u,
+		C:   c,
+	}
+
+	lambda.Start(h.HandleRequest)
+}